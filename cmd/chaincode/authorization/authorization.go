@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package authorization
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+)
+
+// ErrUnauthorized indicates that the client is not authorized to access a namespace
+var ErrUnauthorized = errors.New("client is not authorized")
+
+// Authorize ensures that the client that invoked the chaincode satisfies the given authorization
+// rules of the given namespace.
+func Authorize(stub shim.ChaincodeStubInterface, namespace string, rules config.AnchorAuthorization) error {
+	if len(rules.MSPIDs) == 0 && len(rules.OUs) == 0 && len(rules.Attributes) == 0 {
+		return nil
+	}
+
+	clientID, err := cid.New(stub)
+	if err != nil {
+		return errors.WithMessage(err, "failed to resolve client identity")
+	}
+
+	return checkClient(clientID, namespace, rules)
+}
+
+func checkClient(clientID *cid.ClientID, namespace string, rules config.AnchorAuthorization) error {
+	if len(rules.MSPIDs) > 0 {
+		mspID, err := clientID.GetMSPID()
+		if err != nil {
+			return errors.WithMessage(err, "failed to resolve client MSP ID")
+		}
+
+		if !contains(rules.MSPIDs, mspID) {
+			return errors.WithMessagef(ErrUnauthorized, "MSP [%s] is not authorized for namespace [%s]", mspID, namespace)
+		}
+	}
+
+	for _, ou := range rules.OUs {
+		found, err := clientID.HasOUValue(ou)
+		if err != nil {
+			return errors.WithMessage(err, "failed to resolve client OUs")
+		}
+
+		if !found {
+			return errors.WithMessagef(ErrUnauthorized, "client certificate does not contain OU [%s] required for namespace [%s]", ou, namespace)
+		}
+	}
+
+	for _, attr := range rules.Attributes {
+		if err := checkAttribute(clientID, namespace, attr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkAttribute(clientID *cid.ClientID, namespace string, attr config.Attribute) error {
+	value, found, err := clientID.GetAttributeValue(attr.Name)
+	if err != nil {
+		return errors.WithMessage(err, "failed to resolve client attributes")
+	}
+
+	if !found {
+		return errors.WithMessagef(ErrUnauthorized, "client certificate does not contain attribute [%s] required for namespace [%s]", attr.Name, namespace)
+	}
+
+	if attr.Value != "" && value != attr.Value {
+		return errors.WithMessagef(ErrUnauthorized, "client certificate attribute [%s] does not have the value required for namespace [%s]", attr.Name, namespace)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package authorization
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+)
+
+const (
+	ccName = "testcc"
+	ns     = "did:sidetree"
+
+	org1MSPID = "Org1MSP"
+	org2MSPID = "Org2MSP"
+)
+
+func TestAuthorize(t *testing.T) {
+	creator, err := mocks.NewCreator(org1MSPID, []string{"sidetree", "client"}, `{"attrs":{"sidetree.writer":"true","hf.Type":"client"}}`)
+	require.NoError(t, err)
+
+	stub := mocks.NewMockStub(ccName, nil)
+	stub.Creator = creator
+
+	t.Run("No rules -> success", func(t *testing.T) {
+		require.NoError(t, Authorize(stub, ns, config.AnchorAuthorization{}))
+	})
+
+	t.Run("All rules satisfied -> success", func(t *testing.T) {
+		err := Authorize(stub, ns, config.AnchorAuthorization{
+			MSPIDs: []string{org2MSPID, org1MSPID},
+			OUs:    []string{"sidetree"},
+			Attributes: []config.Attribute{
+				{Name: "sidetree.writer", Value: "true"},
+				{Name: "hf.Type"},
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Unauthorized MSP -> error", func(t *testing.T) {
+		err := Authorize(stub, ns, config.AnchorAuthorization{MSPIDs: []string{org2MSPID}})
+		require.Error(t, err)
+		require.Equal(t, ErrUnauthorized, errors.Cause(err))
+		require.Contains(t, err.Error(), "MSP [Org1MSP] is not authorized for namespace [did:sidetree]")
+	})
+
+	t.Run("Missing OU -> error", func(t *testing.T) {
+		err := Authorize(stub, ns, config.AnchorAuthorization{OUs: []string{"sidetree", "admin"}})
+		require.Error(t, err)
+		require.Equal(t, ErrUnauthorized, errors.Cause(err))
+		require.Contains(t, err.Error(), "client certificate does not contain OU [admin]")
+	})
+
+	t.Run("Missing attribute -> error", func(t *testing.T) {
+		err := Authorize(stub, ns, config.AnchorAuthorization{Attributes: []config.Attribute{{Name: "sidetree.admin"}}})
+		require.Error(t, err)
+		require.Equal(t, ErrUnauthorized, errors.Cause(err))
+		require.Contains(t, err.Error(), "client certificate does not contain attribute [sidetree.admin]")
+	})
+
+	t.Run("Invalid attribute value -> error", func(t *testing.T) {
+		err := Authorize(stub, ns, config.AnchorAuthorization{Attributes: []config.Attribute{{Name: "sidetree.writer", Value: "false"}}})
+		require.Error(t, err)
+		require.Equal(t, ErrUnauthorized, errors.Cause(err))
+		require.Contains(t, err.Error(), "client certificate attribute [sidetree.writer] does not have the value required")
+	})
+
+	t.Run("Invalid creator -> error", func(t *testing.T) {
+		stub := mocks.NewMockStub(ccName, nil)
+		stub.Creator = []byte("invalid creator")
+
+		err := Authorize(stub, ns, config.AnchorAuthorization{MSPIDs: []string{org1MSPID}})
+		require.Error(t, err)
+		require.NotEqual(t, ErrUnauthorized, errors.Cause(err))
+		require.Contains(t, err.Error(), "failed to resolve client identity")
+	})
+}
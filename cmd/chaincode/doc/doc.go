@@ -7,11 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package doc
 
 import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/flogging"
 	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
 	"github.com/trustbloc/sidetree-fabric/pkg/observer"
 )
 
@@ -22,18 +27,44 @@ const (
 
 	couchDB       = "couchdb"
 	docsCollIndex = `{"index": {"fields": ["uniqueSuffix"]}, "ddoc": "indexUniqueSuffixDoc", "name": "indexUniqueSuffix", "type": "json"}`
+
+	// Available function names
+	queryOperationsByUniqueSuffix    = "queryOperationsByUniqueSuffix"
+	queryOperationsByTransactionTime = "queryOperationsByTransactionTime"
+	countOperations                  = "countOperations"
 )
 
-// DocumentCC is used to setup database, collection and indexes for documents
+// funcMap is a map of functions by function name
+type funcMap map[string]func(shim.ChaincodeStubInterface, [][]byte) pb.Response
+
+// SidetreeConfigProvider returns the Sidetree configuration service for a given channel
+type SidetreeConfigProvider interface {
+	ForChannel(channelID string) config.SidetreeService
+}
+
+// DocumentCC is used to setup database, collection and indexes for documents. It also provides read-only
+// queries of the operations stored in the document collections.
 type DocumentCC struct {
-	name string
+	SidetreeConfigProvider
+
+	name       string
+	functions  funcMap
+	mutex      sync.RWMutex
+	channelIDs []string
 }
 
 // New returns chaincode
-func New(name string) *DocumentCC {
+func New(name string, configProvider SidetreeConfigProvider) *DocumentCC {
 	cc := &DocumentCC{
-		name: name,
+		SidetreeConfigProvider: configProvider,
+		name:                   name,
+		functions:              make(funcMap),
 	}
+
+	cc.functions[queryOperationsByUniqueSuffix] = cc.queryOperationsByUniqueSuffix
+	cc.functions[queryOperationsByTransactionTime] = cc.queryOperationsByTransactionTime
+	cc.functions[countOperations] = cc.countOperations
+
 	return cc
 }
 
@@ -46,16 +77,36 @@ func (cc *DocumentCC) Version() string { return ccVersion }
 // Chaincode returns the DocumentCC chaincode
 func (cc *DocumentCC) Chaincode() shim.Chaincode { return cc }
 
-// GetDBArtifacts returns Couch DB indexes for the collections in this chaincode
-// NOTE: All collections in this chaincode have the same indexes except for meta_data
+// ChannelJoined is invoked when the peer joins a channel. The document indexes configured for the channel
+// are included in the DB artifacts returned by GetDBArtifacts.
+func (cc *DocumentCC) ChannelJoined(channelID string) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	for _, id := range cc.channelIDs {
+		if id == channelID {
+			return
+		}
+	}
+
+	cc.channelIDs = append(cc.channelIDs, channelID)
+}
+
+// GetDBArtifacts returns Couch DB indexes for the collections in this chaincode. Every collection (except meta_data)
+// has the default index on uniqueSuffix along with any additional indexes that are configured for the collection.
+// GetDBArtifacts is invoked when the chaincode is deployed to a channel (including upgrades and collection updates),
+// so changes to the index configuration are only applied the next time the chaincode definition is updated.
+// NOTE: The channel is not provided, so see loadIndexes for how the configured indexes are selected.
 func (cc *DocumentCC) GetDBArtifacts(collNames []string) map[string]*ccapi.DBArtifacts {
+	configuredIndexes := cc.loadIndexes()
+
 	collIndexes := make(map[string][]string)
 	for _, collName := range collNames {
 		if collName == observer.MetaDataColName {
 			continue
 		}
 
-		collIndexes[collName] = []string{docsCollIndex}
+		collIndexes[collName] = append([]string{docsCollIndex}, configuredIndexes[collName]...)
 	}
 
 	logger.Infof("Returning DB indexes for collections %s: %s", collNames, collIndexes)
@@ -67,12 +118,149 @@ func (cc *DocumentCC) GetDBArtifacts(collNames []string) map[string]*ccapi.DBArt
 	}
 }
 
+// loadIndexes returns the configured indexes by collection. The indexes are loaded separately for each joined
+// channel and, since it isn't known for which channel the artifacts are requested, the indexes of a collection are
+// only returned if all of the channels that configure indexes for the collection configure the same indexes. Otherwise
+// only the default index is created for the collection so that the indexes of one channel aren't created in
+// the state database of another channel.
+func (cc *DocumentCC) loadIndexes() map[string][]string {
+	cc.mutex.RLock()
+	channelIDs := cc.channelIDs
+	cc.mutex.RUnlock()
+
+	collIndexes := make(map[string][]string)
+	collChannels := make(map[string]string)
+	conflicts := make(map[string]struct{})
+
+	for _, channelID := range channelIDs {
+		indexes, err := cc.loadChannelIndexes(channelID)
+		if err != nil {
+			logger.Warningf("[%s] Unable to load document index config - only the default indexes will be created: %s", channelID, err)
+			continue
+		}
+
+		for collName, collIdx := range indexes {
+			existingChannelID, ok := collChannels[collName]
+			if !ok {
+				collIndexes[collName] = collIdx
+				collChannels[collName] = channelID
+
+				continue
+			}
+
+			if !equal(collIndexes[collName], collIdx) {
+				logger.Errorf("[%s] The indexes configured for collection [%s] differ from the indexes configured in channel [%s]. Only the default indexes will be created for the collection.",
+					channelID, collName, existingChannelID)
+
+				conflicts[collName] = struct{}{}
+			}
+		}
+	}
+
+	for collName := range conflicts {
+		delete(collIndexes, collName)
+	}
+
+	return collIndexes
+}
+
+// loadChannelIndexes returns the indexes by collection that are configured for the given channel
+func (cc *DocumentCC) loadChannelIndexes(channelID string) (map[string][]string, error) {
+	indexCfg, err := cc.ForChannel(channelID).LoadDocumentIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	collIndexes := make(map[string][]string)
+	for _, collCfg := range indexCfg.Collections {
+		for _, index := range collCfg.Indexes {
+			if !contains(collIndexes[collCfg.Collection], index) {
+				collIndexes[collCfg.Collection] = append(collIndexes[collCfg.Collection], index)
+			}
+		}
+	}
+
+	return collIndexes, nil
+}
+
 // Init - nothing to do for now
 func (cc *DocumentCC) Init(stub shim.ChaincodeStubInterface) pb.Response {
 	return shim.Success(nil)
 }
 
-// Invoke manages document lifecycle operations (write, read)
+// Invoke provides read-only queries of the operations stored in the document collections
 func (cc *DocumentCC) Invoke(stub shim.ChaincodeStubInterface) (resp pb.Response) {
-	return shim.Error("invoke is not supported")
+	txID := stub.GetTxID()
+
+	defer handlePanic(&resp)
+
+	args := stub.GetArgs()
+	if len(args) < 1 {
+		errMsg := "function name is required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	// only display first arg (function), remaining args may contain client data, do not log them
+	logger.Debugf("[txID %s] DocumentCC Arg[0]=%s", txID, args[0])
+
+	functionName := string(args[0])
+	function, valid := cc.functions[functionName]
+	if !valid {
+		errMsg := fmt.Sprintf("Invalid invoke function [%s]. Expecting one of: %s", functionName, cc.functions.String())
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return function(stub, args[1:])
+}
+
+func (m funcMap) String() string {
+	str := ""
+	i := 0
+	for key := range m {
+		if i > 0 {
+			str += ", "
+		}
+		i++
+		str += fmt.Sprintf("\"%s\"", key)
+	}
+	return str
+}
+
+// handlePanic handles a panic (if any) by populating error response
+func handlePanic(resp *pb.Response) {
+	if r := recover(); r != nil {
+		logger.Errorf("Recovering from panic: %s", string(debug.Stack()))
+
+		errResp := shim.Error("panic: check server logs")
+		resp.Reset()
+		resp.Status = errResp.Status
+		resp.Message = errResp.Message
+	}
+}
+
+// equal returns true if the given slices contain the same values (in any order)
+func equal(values1, values2 []string) bool {
+	if len(values1) != len(values2) {
+		return false
+	}
+
+	for _, v := range values1 {
+		if !contains(values2, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
@@ -12,19 +12,29 @@ import (
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/stretchr/testify/require"
+
 	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	configmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer"
 )
 
 const (
 	ccName = "document_cc"
 	coll1  = "coll1"
 	coll2  = "coll2"
+
+	channel1 = "channel1"
+	channel2 = "channel2"
+
+	txnTimeIndex = `{"index": {"fields": ["transactionTime"]}, "ddoc": "indexTransactionTimeDoc", "name": "indexTransactionTime", "type": "json"}`
+	typeIndex    = `{"index": {"fields": ["type"]}, "ddoc": "indexTypeDoc", "name": "indexType", "type": "json"}`
 )
 
 func TestNew(t *testing.T) {
 	req := require.New(t)
 
-	cc := New(ccName)
+	cc := New(ccName, &mocks.SidetreeConfigProvider{})
 	req.NotNil(cc)
 
 	req.Equal(ccName, cc.Name())
@@ -40,20 +50,117 @@ func TestNew(t *testing.T) {
 	req.Len(artifact.CollectionIndexes, 2)
 }
 
+func TestGetDBArtifacts(t *testing.T) {
+	t.Run("Configured indexes", func(t *testing.T) {
+		configService1 := &configmocks.SidetreeConfigService{}
+		configService1.LoadDocumentIndexesReturns(config.DocumentIndexes{
+			Collections: []config.CollectionIndexes{
+				{Collection: coll1, Indexes: []string{txnTimeIndex}},
+				{Collection: observer.MetaDataColName, Indexes: []string{typeIndex}},
+			},
+		}, nil)
+
+		configService2 := &configmocks.SidetreeConfigService{}
+		configService2.LoadDocumentIndexesReturns(config.DocumentIndexes{
+			Collections: []config.CollectionIndexes{
+				{Collection: coll1, Indexes: []string{txnTimeIndex}},
+				{Collection: coll2, Indexes: []string{typeIndex}},
+			},
+		}, nil)
+
+		configProvider := &mocks.SidetreeConfigProvider{}
+		configProvider.ForChannelStub = func(channelID string) config.SidetreeService {
+			if channelID == channel1 {
+				return configService1
+			}
+			return configService2
+		}
+
+		cc := New(ccName, configProvider)
+		cc.ChannelJoined(channel1)
+		cc.ChannelJoined(channel2)
+		cc.ChannelJoined(channel1)
+
+		dbArtifacts := cc.GetDBArtifacts([]string{coll1, coll2, observer.MetaDataColName})
+		artifact, ok := dbArtifacts[couchDB]
+		require.True(t, ok)
+		require.Len(t, artifact.CollectionIndexes, 2)
+		require.Equal(t, []string{docsCollIndex, txnTimeIndex}, artifact.CollectionIndexes[coll1])
+		require.Equal(t, []string{docsCollIndex, typeIndex}, artifact.CollectionIndexes[coll2])
+		require.Equal(t, 2, configProvider.ForChannelCallCount())
+	})
+
+	t.Run("Different indexes in different channels -> default indexes", func(t *testing.T) {
+		configService1 := &configmocks.SidetreeConfigService{}
+		configService1.LoadDocumentIndexesReturns(config.DocumentIndexes{
+			Collections: []config.CollectionIndexes{
+				{Collection: coll1, Indexes: []string{txnTimeIndex, typeIndex}},
+				{Collection: coll2, Indexes: []string{typeIndex}},
+			},
+		}, nil)
+
+		configService2 := &configmocks.SidetreeConfigService{}
+		configService2.LoadDocumentIndexesReturns(config.DocumentIndexes{
+			Collections: []config.CollectionIndexes{
+				{Collection: coll1, Indexes: []string{txnTimeIndex}},
+				{Collection: coll2, Indexes: []string{typeIndex}},
+			},
+		}, nil)
+
+		configProvider := &mocks.SidetreeConfigProvider{}
+		configProvider.ForChannelStub = func(channelID string) config.SidetreeService {
+			if channelID == channel1 {
+				return configService1
+			}
+			return configService2
+		}
+
+		cc := New(ccName, configProvider)
+		cc.ChannelJoined(channel1)
+		cc.ChannelJoined(channel2)
+
+		dbArtifacts := cc.GetDBArtifacts([]string{coll1, coll2})
+		artifact, ok := dbArtifacts[couchDB]
+		require.True(t, ok)
+		require.Equal(t, []string{docsCollIndex}, artifact.CollectionIndexes[coll1])
+		require.Equal(t, []string{docsCollIndex, typeIndex}, artifact.CollectionIndexes[coll2])
+	})
+
+	t.Run("Config error -> default indexes", func(t *testing.T) {
+		configService := &configmocks.SidetreeConfigService{}
+		configService.LoadDocumentIndexesReturns(config.DocumentIndexes{}, fmt.Errorf("injected config error"))
+
+		configProvider := &mocks.SidetreeConfigProvider{}
+		configProvider.ForChannelReturns(configService)
+
+		cc := New(ccName, configProvider)
+		cc.ChannelJoined(channel1)
+
+		dbArtifacts := cc.GetDBArtifacts([]string{coll1})
+		artifact, ok := dbArtifacts[couchDB]
+		require.True(t, ok)
+		require.Equal(t, []string{docsCollIndex}, artifact.CollectionIndexes[coll1])
+	})
+}
+
 func TestInvoke(t *testing.T) {
 
 	stub := prepareStub()
 
 	_, err := invoke(stub, [][]byte{})
 	require.NotNil(t, err)
-	require.Contains(t, err.Error(), "invoke is not supported")
+	require.Contains(t, err.Error(), "function name is required")
+
+	_, err = invoke(stub, [][]byte{[]byte("xxx")})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Invalid invoke function [xxx]")
 
 	// run last
 	checkInit(t, stub, [][]byte{})
 }
 
 func prepareStub() *mocks.MockStub {
-	return mocks.NewMockStub(ccName, New(ccName))
+	return mocks.NewMockStub(ccName, New(ccName, &mocks.SidetreeConfigProvider{}))
 }
 
 func checkInit(t *testing.T, stub *mocks.MockStub, args [][]byte) {
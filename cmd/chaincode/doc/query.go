@@ -0,0 +1,231 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/authorization"
+	"github.com/trustbloc/sidetree-fabric/pkg/common/docquery"
+)
+
+var (
+	// maxQueryResults is the maximum number of operations returned by a single query
+	maxQueryResults = 1000
+
+	// maxCountResults is the maximum number of operations that are counted by countOperations
+	maxCountResults = 100000
+)
+
+// OperationQueryResults contains the operations returned by a query
+type OperationQueryResults struct {
+	Operations []json.RawMessage `json:"operations"`
+	// Truncated is true if more operations matched the query than are included in the results. In the case
+	// of a transaction time query, the remaining operations may be retrieved by narrowing the time range.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// OperationCount contains the number of operations stored for a namespace
+type OperationCount struct {
+	Namespace string `json:"namespace"`
+	Count     int    `json:"count"`
+	// Truncated is true if the namespace contains more operations than the maximum number that are counted,
+	// in which case Count is the maximum
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// queryOperationsByUniqueSuffix returns the operations for the given document.
+// Args: namespace, uniqueSuffix
+func (cc *DocumentCC) queryOperationsByUniqueSuffix(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) != 2 || len(args[0]) == 0 || len(args[1]) == 0 {
+		errMsg := "namespace and unique suffix are required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return cc.queryOperations(stub, string(args[0]), docquery.ByUniqueSuffix(string(args[1])))
+}
+
+// queryOperationsByTransactionTime returns the operations whose transaction time is within the given range.
+// If the end time is not provided then all operations from the start time are returned.
+// Args: namespace, startTime (inclusive), [endTime (exclusive)]
+func (cc *DocumentCC) queryOperationsByTransactionTime(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 2 || len(args) > 3 || len(args[0]) == 0 || len(args[1]) == 0 {
+		errMsg := "namespace and start time are required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	startTime, err := strconv.ParseUint(string(args[1]), 10, 64)
+	if err != nil {
+		errMsg := fmt.Sprintf("invalid start time [%s]", args[1])
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	endTime := uint64(math.MaxUint64)
+	if len(args) == 3 && len(args[2]) > 0 {
+		endTime, err = strconv.ParseUint(string(args[2]), 10, 64)
+		if err != nil {
+			errMsg := fmt.Sprintf("invalid end time [%s]", args[2])
+			logger.Debugf("[txID %s] %s", txID, errMsg)
+			return shim.Error(errMsg)
+		}
+	}
+
+	if endTime <= startTime {
+		errMsg := "end time must be greater than start time"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return cc.queryOperations(stub, string(args[0]), docquery.ByTransactionTimeRange(startTime, endTime))
+}
+
+// countOperations returns the number of operations stored for the given namespace, up to a maximum of maxCountResults.
+// Args: namespace
+func (cc *DocumentCC) countOperations(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) != 1 || len(args[0]) == 0 {
+		errMsg := "namespace is required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	namespace := string(args[0])
+
+	count := &OperationCount{Namespace: namespace}
+	err := cc.query(stub, namespace, docquery.AllOperations(), func([]byte) bool {
+		if count.Count == maxCountResults {
+			logger.Debugf("[txID %s] Stopping count since the maximum number of operations [%d] was reached", txID, maxCountResults)
+			count.Truncated = true
+			return false
+		}
+
+		count.Count++
+		return true
+	})
+	if err != nil {
+		return errorResponse(txID, err)
+	}
+
+	countBytes, err := json.Marshal(count)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to marshal operation count: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(countBytes)
+}
+
+func (cc *DocumentCC) queryOperations(stub shim.ChaincodeStubInterface, namespace, query string) pb.Response {
+	txID := stub.GetTxID()
+
+	results := &OperationQueryResults{Operations: []json.RawMessage{}}
+
+	err := cc.query(stub, namespace, query, func(value []byte) bool {
+		if len(results.Operations) == maxQueryResults {
+			logger.Debugf("[txID %s] Truncating results since the maximum number of results [%d] was reached", txID, maxQueryResults)
+			results.Truncated = true
+			return false
+		}
+
+		results.Operations = append(results.Operations, value)
+		return true
+	})
+	if err != nil {
+		return errorResponse(txID, err)
+	}
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to marshal query results: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(resultsBytes)
+}
+
+// query executes the given query against the document collection of the namespace and invokes the
+// given function for each result. Iteration stops if the function returns false.
+func (cc *DocumentCC) query(stub shim.ChaincodeStubInterface, namespace, query string, handle func(value []byte) bool) error {
+	collection, err := cc.getCollection(stub, namespace)
+	if err != nil {
+		return err
+	}
+
+	it, err := stub.GetPrivateDataQueryResult(collection, query)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to query operations for namespace [%s]", namespace)
+	}
+
+	defer func() {
+		if err := it.Close(); err != nil {
+			logger.Warningf("[txID %s] Failed to close iterator: %s", stub.GetTxID(), err)
+		}
+	}()
+
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return errors.WithMessagef(err, "failed to retrieve operation for namespace [%s]", namespace)
+		}
+
+		if !handle(kv.Value) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// getCollection returns the collection in which the operations for the given namespace are stored after ensuring
+// that the client is authorized to query the namespace
+func (cc *DocumentCC) getCollection(stub shim.ChaincodeStubInterface, namespace string) (string, error) {
+	cfg, err := cc.ForChannel(stub.GetChannelID()).LoadSidetree(namespace)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to load Sidetree config for namespace [%s]", namespace)
+	}
+
+	if cfg.ChaincodeName != cc.name {
+		return "", errors.Errorf("operations for namespace [%s] are not stored by chaincode [%s]", namespace, cc.name)
+	}
+
+	if err := authorization.Authorize(stub, namespace, cfg.QueryAuthorization); err != nil {
+		return "", err
+	}
+
+	return cfg.Collection, nil
+}
+
+// errorResponse returns a 403 (forbidden) response if the client is not authorized, otherwise an error response
+func errorResponse(txID string, err error) pb.Response {
+	if errors.Cause(err) == authorization.ErrUnauthorized {
+		logger.Warnf("[txID %s] %s", txID, err)
+
+		return pb.Response{
+			Status:  403,
+			Message: err.Error(),
+		}
+	}
+
+	errMsg := err.Error()
+	logger.Errorf("[txID %s] %s", txID, errMsg)
+
+	return shim.Error(errMsg)
+}
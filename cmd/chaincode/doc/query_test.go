@@ -0,0 +1,260 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	configmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
+)
+
+const (
+	ns1     = "did:sidetree"
+	ns2     = "did:other"
+	docColl = "docs"
+)
+
+func TestQueryOperationsByUniqueSuffix(t *testing.T) {
+	stub := prepareQueryStub(t)
+
+	t.Run("Success", func(t *testing.T) {
+		results := queryOperations(t, stub, queryOperationsByUniqueSuffix, ns1, "suffix1")
+		require.Len(t, results.Operations, 3)
+		require.False(t, results.Truncated)
+
+		op := &operation.AnchoredOperation{}
+		require.NoError(t, json.Unmarshal(results.Operations[0], op))
+		require.Equal(t, "suffix1", op.UniqueSuffix)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		restore := maxQueryResults
+		maxQueryResults = 2
+		defer func() { maxQueryResults = restore }()
+
+		results := queryOperations(t, stub, queryOperationsByUniqueSuffix, ns1, "suffix1")
+		require.Len(t, results.Operations, 2)
+		require.True(t, results.Truncated)
+	})
+
+	t.Run("No results", func(t *testing.T) {
+		stub := mocks.NewMockStub(ccName, New(ccName, newConfigProvider(nil)))
+
+		results := queryOperations(t, stub, queryOperationsByUniqueSuffix, ns1, "suffix1")
+		require.Empty(t, results.Operations)
+	})
+
+	t.Run("Missing args", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByUniqueSuffix), []byte(ns1)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "namespace and unique suffix are required")
+	})
+
+	t.Run("Namespace not stored by chaincode", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByUniqueSuffix), []byte(ns2), []byte("suffix1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "operations for namespace [did:other] are not stored by chaincode")
+	})
+
+	t.Run("Config error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected config error")
+
+		stub := mocks.NewMockStub(ccName, New(ccName, newConfigProvider(errExpected)))
+
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByUniqueSuffix), []byte(ns1), []byte("suffix1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected query error")
+		stub.GetPrivateQueryErr = errExpected
+		defer func() { stub.GetPrivateQueryErr = nil }()
+
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByUniqueSuffix), []byte(ns1), []byte("suffix1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestQueryOperationsByTransactionTime(t *testing.T) {
+	stub := prepareQueryStub(t)
+
+	t.Run("Success", func(t *testing.T) {
+		results := queryOperations(t, stub, queryOperationsByTransactionTime, ns1, "10", "20")
+		require.Len(t, results.Operations, 3)
+
+		results = queryOperations(t, stub, queryOperationsByTransactionTime, ns1, "10")
+		require.Len(t, results.Operations, 3)
+	})
+
+	t.Run("Missing args", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByTransactionTime), []byte(ns1)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "namespace and start time are required")
+	})
+
+	t.Run("Invalid start time", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByTransactionTime), []byte(ns1), []byte("xxx")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid start time [xxx]")
+	})
+
+	t.Run("Invalid end time", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryOperationsByTransactionTime), []byte(ns1), []byte("10"), []byte("xxx")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid end time [xxx]")
+
+		_, err = invoke(stub, [][]byte{[]byte(queryOperationsByTransactionTime), []byte(ns1), []byte("10"), []byte("10")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "end time must be greater than start time")
+	})
+}
+
+func TestCountOperations(t *testing.T) {
+	stub := prepareQueryStub(t)
+
+	t.Run("Success", func(t *testing.T) {
+		payload, err := invoke(stub, [][]byte{[]byte(countOperations), []byte(ns1)})
+		require.NoError(t, err)
+
+		count := &OperationCount{}
+		require.NoError(t, json.Unmarshal(payload, count))
+		require.Equal(t, ns1, count.Namespace)
+		require.Equal(t, 3, count.Count)
+	})
+
+	t.Run("Maximum count", func(t *testing.T) {
+		restore := maxCountResults
+		maxCountResults = 2
+		defer func() { maxCountResults = restore }()
+
+		payload, err := invoke(stub, [][]byte{[]byte(countOperations), []byte(ns1)})
+		require.NoError(t, err)
+
+		count := &OperationCount{}
+		require.NoError(t, json.Unmarshal(payload, count))
+		require.Equal(t, 2, count.Count)
+		require.True(t, count.Truncated)
+	})
+
+	t.Run("Missing namespace", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(countOperations)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "namespace is required")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected query error")
+		stub.GetPrivateQueryErr = errExpected
+		defer func() { stub.GetPrivateQueryErr = nil }()
+
+		_, err := invoke(stub, [][]byte{[]byte(countOperations), []byte(ns1)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestQueryAuthorization(t *testing.T) {
+	creator, err := mocks.NewCreator("Org1MSP", []string{"sidetree"}, `{"attrs":{"hf.Type":"client"}}`)
+	require.NoError(t, err)
+
+	newStub := func(rules config.AnchorAuthorization) *mocks.MockStub {
+		configService := &configmocks.SidetreeConfigService{}
+		configService.LoadSidetreeReturns(config.Sidetree{ChaincodeName: ccName, Collection: docColl, QueryAuthorization: rules}, nil)
+
+		configProvider := &mocks.SidetreeConfigProvider{}
+		configProvider.ForChannelReturns(configService)
+
+		stub := mocks.NewMockStub(ccName, New(ccName, configProvider))
+		stub.Creator = creator
+
+		return stub
+	}
+
+	t.Run("Authorized", func(t *testing.T) {
+		stub := newStub(config.AnchorAuthorization{MSPIDs: []string{"Org1MSP"}, OUs: []string{"sidetree"}})
+
+		results := queryOperations(t, stub, queryOperationsByUniqueSuffix, ns1, "suffix1")
+		require.Empty(t, results.Operations)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		stub := newStub(config.AnchorAuthorization{MSPIDs: []string{"Org2MSP"}})
+
+		resp := stub.MockInvoke("tx1", [][]byte{[]byte(queryOperationsByUniqueSuffix), []byte(ns1), []byte("suffix1")})
+		require.Equal(t, int32(403), resp.Status)
+		require.Contains(t, resp.Message, "MSP [Org1MSP] is not authorized for namespace [did:sidetree]")
+
+		resp = stub.MockInvoke("tx1", [][]byte{[]byte(countOperations), []byte(ns1)})
+		require.Equal(t, int32(403), resp.Status)
+	})
+}
+
+// prepareQueryStub returns a stub with three operations stored in the document collection.
+// NOTE: The mock stub doesn't evaluate the query so all operations in the collection are returned.
+func prepareQueryStub(t *testing.T) *mocks.MockStub {
+	stub := mocks.NewMockStub(ccName, New(ccName, newConfigProvider(nil)))
+
+	stub.MockTransactionStart("tx1")
+	defer stub.MockTransactionEnd("tx1")
+
+	for i := 0; i < 3; i++ {
+		opBytes, err := json.Marshal(&operation.AnchoredOperation{
+			UniqueSuffix:    "suffix1",
+			Type:            operation.TypeUpdate,
+			TransactionTime: uint64(10 + i),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, stub.PutPrivateData(docColl, fmt.Sprintf("key%d", i), opBytes))
+	}
+
+	return stub
+}
+
+func newConfigProvider(err error) *mocks.SidetreeConfigProvider {
+	configService := &configmocks.SidetreeConfigService{}
+	configService.LoadSidetreeStub = func(namespace string) (config.Sidetree, error) {
+		if err != nil {
+			return config.Sidetree{}, err
+		}
+
+		if namespace == ns1 {
+			return config.Sidetree{ChaincodeName: ccName, Collection: docColl}, nil
+		}
+
+		return config.Sidetree{ChaincodeName: "txncc", Collection: "coll"}, nil
+	}
+
+	configProvider := &mocks.SidetreeConfigProvider{}
+	configProvider.ForChannelReturns(configService)
+
+	return configProvider
+}
+
+func queryOperations(t *testing.T, stub *mocks.MockStub, fctn string, args ...string) *OperationQueryResults {
+	invokeArgs := [][]byte{[]byte(fctn)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	payload, err := invoke(stub, invokeArgs)
+	require.NoError(t, err)
+
+	results := &OperationQueryResults{}
+	require.NoError(t, json.Unmarshal(payload, results))
+
+	return results
+}
@@ -13,8 +13,10 @@ package mocks
 
 import (
 	"container/list"
+	"math"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-chaincode-go/shimtest" //nolint
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
@@ -59,6 +61,10 @@ type MockStub struct {
 	GetPrivateErr      error
 	PutPrivateErr      error
 	GetPrivateQueryErr error
+	GetStateByRangeErr error
+
+	// Event contains the chaincode event that was set in the last invocation (if any)
+	Event *pb.ChaincodeEvent
 }
 
 // GetTransient returns transient map
@@ -239,12 +245,24 @@ func (stub *MockStub) MockInit(uuid string, args [][]byte) pb.Response {
 //MockInvoke invokes chaincode
 func (stub *MockStub) MockInvoke(uuid string, args [][]byte) pb.Response {
 	stub.args = args
+	stub.Event = nil
 	stub.MockTransactionStart(uuid)
 	res := stub.cc.Invoke(stub)
 	stub.MockTransactionEnd(uuid)
 	return res
 }
 
+// SetEvent sets the chaincode event for the current invocation
+func (stub *MockStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+
+	stub.Event = &pb.ChaincodeEvent{EventName: name, Payload: payload}
+
+	return nil
+}
+
 // GetPrivateDataQueryResult mocks rich query
 func (stub *MockStub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
 
@@ -261,6 +279,7 @@ func NewMockStateQueryIterator(stub *MockStub, collection, query string) *MockSt
 	iter.Closed = false
 	iter.Stub = stub
 	iter.Query = query
+	iter.Collection = collection
 	iter.Current = stub.getKeys(collection).Front()
 
 	return iter
@@ -272,10 +291,11 @@ func NewMockStateQueryIterator(stub *MockStub, collection, query string) *MockSt
 
 // MockStateQueryIterator is a mock implementation of the state query iterator
 type MockStateQueryIterator struct {
-	Closed  bool
-	Stub    *MockStub
-	Query   string
-	Current *list.Element
+	Closed     bool
+	Stub       *MockStub
+	Query      string
+	Collection string
+	Current    *list.Element
 }
 
 // HasNext returns true if the range query iterator contains additional keys
@@ -310,7 +330,7 @@ func (iter *MockStateQueryIterator) Next() (*queryresult.KV, error) {
 	}
 
 	key := iter.Current.Value.(string)
-	value, err := iter.Stub.GetState(key)
+	value, err := iter.Stub.getState(iter.Collection, key)
 	iter.Current = iter.Current.Next()
 
 	return &queryresult.KV{Key: key, Value: value}, err
@@ -328,3 +348,88 @@ func (iter *MockStateQueryIterator) Close() error {
 	iter.Closed = true
 	return nil
 }
+
+// GetStateByRange returns the keys in the given range
+func (stub *MockStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	it, _, err := stub.GetStateByRangeWithPagination(startKey, endKey, math.MaxInt32, "")
+
+	return it, err
+}
+
+// GetStateByRangeWithPagination returns a page of the keys in the given range. The returned bookmark
+// is the first key of the next page (or empty if there are no more keys).
+func (stub *MockStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	if stub.GetStateByRangeErr != nil {
+		return nil, nil, stub.GetStateByRangeErr
+	}
+
+	if bookmark != "" {
+		startKey = bookmark
+	}
+
+	var kvs []*queryresult.KV
+	nextBookmark := ""
+
+	for elem := stub.getKeys("").Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		if key < startKey || key >= endKey {
+			continue
+		}
+
+		if int32(len(kvs)) == pageSize {
+			nextBookmark = key
+			break
+		}
+
+		value, err := stub.GetState(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		kvs = append(kvs, &queryresult.KV{Key: key, Value: value})
+	}
+
+	md := &pb.QueryResponseMetadata{
+		FetchedRecordsCount: int32(len(kvs)),
+		Bookmark:            nextBookmark,
+	}
+
+	return &MockKVIterator{kvs: kvs}, md, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns a page of the keys that match the given partial composite key
+func (stub *MockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stub.GetStateByRangeWithPagination(partialCompositeKey, partialCompositeKey+string(utf8.MaxRune), pageSize, bookmark)
+}
+
+// MockKVIterator is a mock state iterator over a fixed set of key-values
+type MockKVIterator struct {
+	kvs []*queryresult.KV
+}
+
+// HasNext returns true if the iterator contains additional key-values
+func (it *MockKVIterator) HasNext() bool {
+	return len(it.kvs) > 0
+}
+
+// Next returns the next key-value
+func (it *MockKVIterator) Next() (*queryresult.KV, error) {
+	if len(it.kvs) == 0 {
+		return nil, errors.New("MockKVIterator.Next() called when it does not HaveNext()")
+	}
+
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+
+	return kv, nil
+}
+
+// Close closes the iterator
+func (it *MockKVIterator) Close() error {
+	return nil
+}
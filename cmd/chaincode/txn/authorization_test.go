@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmdmocks "github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	configmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+const (
+	org1MSPID = "Org1MSP"
+	org2MSPID = "Org2MSP"
+)
+
+// TestWriteAnchor_Authorization tests that writeAnchor enforces the authorization rules of the namespace. The rules
+// themselves are tested in the authorization package.
+func TestWriteAnchor_Authorization(t *testing.T) {
+	creator := newCreator(t, org1MSPID, []string{"sidetree", "client"}, `{"attrs":{"sidetree.writer":"true","hf.Type":"client"}}`)
+
+	t.Run("No rules -> success", func(t *testing.T) {
+		stub := prepareAuthStub(config.AnchorAuthorization{})
+		stub.Creator = creator
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), getTxnInfoBytes(t, 100)})
+		require.NoError(t, err)
+	})
+
+	t.Run("All rules satisfied -> success", func(t *testing.T) {
+		stub := prepareAuthStub(config.AnchorAuthorization{
+			MSPIDs: []string{org2MSPID, org1MSPID},
+			OUs:    []string{"sidetree"},
+			Attributes: []config.Attribute{
+				{Name: "sidetree.writer", Value: "true"},
+				{Name: "hf.Type"},
+			},
+		})
+		stub.Creator = creator
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), getTxnInfoBytes(t, 100)})
+		require.NoError(t, err)
+
+		result, err := stub.GetState(common.AnchorPrefix + testAnchor(t, "anchor1"))
+		require.NoError(t, err)
+		require.NotEmpty(t, result)
+	})
+
+	t.Run("Unauthorized MSP -> error", func(t *testing.T) {
+		stub := prepareAuthStub(config.AnchorAuthorization{MSPIDs: []string{org2MSPID}})
+		stub.Creator = creator
+
+		resp := stub.MockInvoke("1", [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), getTxnInfoBytes(t, 100)})
+		require.Equal(t, int32(403), resp.Status)
+		require.Contains(t, resp.Message, "MSP [Org1MSP] is not authorized for namespace [ns]")
+
+		result, err := stub.GetState(common.AnchorPrefix + testAnchor(t, "anchor1"))
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("Invalid creator -> error", func(t *testing.T) {
+		stub := prepareAuthStub(config.AnchorAuthorization{MSPIDs: []string{org1MSPID}})
+		stub.Creator = []byte("invalid creator")
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve client identity")
+	})
+
+	t.Run("Config error -> error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected config error")
+
+		configService := &configmocks.SidetreeConfigService{}
+		configService.LoadSidetreeReturns(config.Sidetree{}, errExpected)
+
+		configProvider := &cmdmocks.SidetreeConfigProvider{}
+		configProvider.ForChannelReturns(configService)
+
+		stub := prepareAuthStubWithConfigProvider(configProvider)
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestIndexAnchors_Authorization(t *testing.T) {
+	stub := prepareAuthStub(config.AnchorAuthorization{MSPIDs: []string{org1MSPID}})
+	stub.Creator = newCreator(t, org1MSPID, []string{"sidetree"}, `{"attrs":{}}`)
+
+	writeAnchors(t, stub, "ns1", "a1", "a2")
+
+	t.Run("Authorized -> success", func(t *testing.T) {
+		results := indexAnchorsWithArgs(t, stub)
+		require.Equal(t, 2, results.Indexed)
+	})
+
+	t.Run("Unauthorized MSP -> error", func(t *testing.T) {
+		stub.Creator = newCreator(t, org2MSPID, []string{"sidetree"}, `{"attrs":{}}`)
+
+		resp := stub.MockInvoke("1", [][]byte{[]byte(indexAnchors)})
+		require.Equal(t, int32(403), resp.Status)
+		require.Contains(t, resp.Message, "MSP [Org2MSP] is not authorized for namespace [ns1]")
+	})
+
+	t.Run("Config error -> error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected config error")
+
+		configService := &configmocks.SidetreeConfigService{}
+		configService.LoadSidetreeReturns(config.Sidetree{}, errExpected)
+
+		configProvider := &cmdmocks.SidetreeConfigProvider{}
+		configProvider.ForChannelReturns(configService)
+
+		stub := prepareAuthStubWithConfigProvider(configProvider)
+		stub.MockTransactionStart("tx1")
+		require.NoError(t, stub.PutState(common.AnchorPrefix+"anchor1", getNamespaceTxnInfoBytes(t, "ns1", 100)))
+		stub.MockTransactionEnd("tx1")
+
+		_, err := invoke(stub, [][]byte{[]byte(indexAnchors)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func prepareAuthStub(authCfg config.AnchorAuthorization) *cmdmocks.MockStub {
+	return prepareAuthStubWithConfigProvider(newConfigProvider(authCfg))
+}
+
+func prepareAuthStubWithConfigProvider(configProvider SidetreeConfigProvider) *cmdmocks.MockStub {
+	return prepareValidationStub(newProtocolVersion(), configProvider, mocks.NewDCASClient())
+}
+
+func newCreator(t *testing.T, mspID string, ous []string, attrs string) []byte {
+	creator, err := cmdmocks.NewCreator(mspID, ous, attrs)
+	require.NoError(t, err)
+
+	return creator
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/cas"
+)
+
+// maxContentsResponseSize is the maximum size of the (JSON-encoded) results returned by readContents. It is kept
+// well below Fabric's default maximum message size (100MB) to leave room for the rest of the proposal response.
+var maxContentsResponseSize = 64 * 1024 * 1024
+
+// resultOverhead is the maximum size of the JSON field names and delimiters of an encoded ContentResult
+const resultOverhead = 64
+
+// ContentResults contains the per-item results of a writeContents or readContents invocation
+type ContentResults struct {
+	Results []*ContentResult `json:"results"`
+	// Truncated is true if not all of the requested items are included in the results since the response would
+	// have exceeded the maximum message size. The remaining items should be requested in a subsequent invocation.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ContentResult contains the result for a single item. Error is set if the item could not be written or read.
+type ContentResult struct {
+	Address string `json:"address,omitempty"`
+	Content []byte `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// writeContents writes one or more items of content using the cas client.
+// Args: collection, content1, content2, ...
+func (cc *SidetreeTxnCC) writeContents(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 2 || len(args[0]) == 0 {
+		errMsg := "collection and at least one content item are required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	client, resp, ok := cc.newCASClient(stub, string(args[0]))
+	if !ok {
+		return resp
+	}
+
+	results := &ContentResults{}
+
+	for _, content := range args[1:] {
+		result := &ContentResult{}
+
+		if len(content) == 0 {
+			result.Error = "content is required"
+		} else {
+			address, err := client.Write(content)
+			if err != nil {
+				logger.Errorf("[txID %s] failed to write content: %s", txID, err.Error())
+				result.Error = fmt.Sprintf("failed to write content: %s", err.Error())
+			} else {
+				result.Address = address
+			}
+		}
+
+		results.Results = append(results.Results, result)
+	}
+
+	return marshalContentResults(txID, results)
+}
+
+// readContents reads one or more items of content using the cas client. If the total size of the content
+// would exceed the maximum response size then the results are truncated.
+// Args: collection, address1, address2, ...
+func (cc *SidetreeTxnCC) readContents(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 2 || len(args[0]) == 0 {
+		errMsg := "collection and at least one content address are required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	client, resp, ok := cc.newCASClient(stub, string(args[0]))
+	if !ok {
+		return resp
+	}
+
+	results := &ContentResults{}
+	size := 0
+
+	for _, address := range args[1:] {
+		result := &ContentResult{Address: string(address)}
+
+		if len(address) == 0 {
+			result.Error = "content address is required"
+		} else {
+			content, err := client.Read(string(address))
+			switch {
+			case err != nil:
+				logger.Errorf("[txID %s] failed to read content [%s]: %s", txID, address, err.Error())
+				result.Error = fmt.Sprintf("failed to read content: %s", err.Error())
+			case content == nil:
+				result.Error = "content not found"
+			case encodedSize(string(address), content, "") > maxContentsResponseSize:
+				// The content can never be returned, so an error is returned for the item instead of truncating the
+				// results (which would cause the client to request the same item again)
+				logger.Debugf("[txID %s] Content at address [%s] exceeds the maximum response size of %d bytes", txID, address, maxContentsResponseSize)
+				result.Error = fmt.Sprintf("content size [%d] exceeds the maximum response size", len(content))
+			default:
+				result.Content = content
+			}
+		}
+
+		resultSize := encodedSize(result.Address, result.Content, result.Error)
+
+		// At least one result is always returned so that the client makes progress
+		if len(results.Results) > 0 && size+resultSize > maxContentsResponseSize {
+			logger.Debugf("[txID %s] Truncating results at address [%s] since the response would exceed %d bytes", txID, address, maxContentsResponseSize)
+			results.Truncated = true
+
+			break
+		}
+
+		size += resultSize
+		results.Results = append(results.Results, result)
+	}
+
+	return marshalContentResults(txID, results)
+}
+
+// encodedSize returns the (maximum) size of a JSON-encoded ContentResult, in which the content is base64-encoded
+func encodedSize(address string, content []byte, errMsg string) int {
+	return resultOverhead + len(address) + base64.StdEncoding.EncodedLen(len(content)) + len(errMsg)
+}
+
+func (cc *SidetreeTxnCC) newCASClient(stub shim.ChaincodeStubInterface, coll string) (*cas.Client, pb.Response, bool) {
+	dcasClient, err := cc.CreateDCASClientStubWrapper(coll, stub)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to create DCAS client: %s", err.Error())
+		logger.Errorf("[txID %s] %s", stub.GetTxID(), errMsg)
+		return nil, shim.Error(errMsg), false
+	}
+
+	return cas.New(dcasClient), pb.Response{}, true
+}
+
+func marshalContentResults(txID string, results *ContentResults) pb.Response {
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to marshal content results: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(resultsBytes)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmdmocks "github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestWriteContents(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		results := invokeContents(t, stub, writeContents, coll1, "content1", "", "content2")
+		require.Len(t, results.Results, 3)
+		require.False(t, results.Truncated)
+
+		require.Equal(t, testAddress(t, "content1"), results.Results[0].Address)
+		require.Empty(t, results.Results[0].Error)
+		require.Empty(t, results.Results[1].Address)
+		require.Equal(t, "content is required", results.Results[1].Error)
+		require.Equal(t, testAddress(t, "content2"), results.Results[2].Address)
+
+		payload, err := invoke(stub, [][]byte{[]byte(readContent), []byte(coll1), []byte(results.Results[2].Address)})
+		require.NoError(t, err)
+		require.Equal(t, []byte("content2"), payload)
+	})
+
+	t.Run("Write error", func(t *testing.T) {
+		errExpected := fmt.Errorf("write error")
+		stub := prepareStub(testProtocol, mocks.NewDCASClient().WithPutError(errExpected))
+
+		results := invokeContents(t, stub, writeContents, coll1, "content1")
+		require.Len(t, results.Results, 1)
+		require.Contains(t, results.Results[0].Error, errExpected.Error())
+	})
+
+	t.Run("Missing args", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(writeContents), []byte(coll1)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "collection and at least one content item are required")
+	})
+
+	t.Run("Factory error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected factory error")
+		dswf := &cmdmocks.DCASStubWrapperFactory{}
+		dswf.CreateDCASClientStubWrapperReturns(nil, errExpected)
+
+		stub := prepareStubWithFactory(testProtocol, dswf)
+
+		_, err := invoke(stub, [][]byte{[]byte(writeContents), []byte(coll1), []byte("content1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestReadContents(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		invokeContents(t, stub, writeContents, coll1, "content1", "content2")
+
+		address1 := testAddress(t, "content1")
+		address2 := testAddress(t, "content2")
+		address3 := testAddress(t, "content3")
+
+		results := invokeContents(t, stub, readContents, coll1, address1, address3, "", address2)
+		require.Len(t, results.Results, 4)
+		require.False(t, results.Truncated)
+
+		require.Equal(t, address1, results.Results[0].Address)
+		require.Equal(t, []byte("content1"), results.Results[0].Content)
+		require.Equal(t, address3, results.Results[1].Address)
+		require.Equal(t, "content not found", results.Results[1].Error)
+		require.Equal(t, "content address is required", results.Results[2].Error)
+		require.Equal(t, address2, results.Results[3].Address)
+		require.Equal(t, []byte("content2"), results.Results[3].Content)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		invokeContents(t, stub, writeContents, coll1, "content1", "content2", "content3")
+
+		restore := maxContentsResponseSize
+		maxContentsResponseSize = 2 * encodedSize(testAddress(t, "content1"), []byte("content1"), "")
+		defer func() { maxContentsResponseSize = restore }()
+
+		results := invokeContents(t, stub, readContents, coll1, testAddress(t, "content1"), testAddress(t, "content2"), testAddress(t, "content3"))
+		require.True(t, results.Truncated)
+		require.Len(t, results.Results, 2)
+		require.Equal(t, []byte("content1"), results.Results[0].Content)
+		require.Equal(t, []byte("content2"), results.Results[1].Content)
+	})
+
+	t.Run("Content exceeds maximum response size", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		invokeContents(t, stub, writeContents, coll1, "content1")
+
+		restore := maxContentsResponseSize
+		maxContentsResponseSize = encodedSize(testAddress(t, "content1"), []byte("content1"), "") - 1
+		defer func() { maxContentsResponseSize = restore }()
+
+		// An error is returned for the item instead of truncating the results
+		results := invokeContents(t, stub, readContents, coll1, testAddress(t, "content1"))
+		require.False(t, results.Truncated)
+		require.Len(t, results.Results, 1)
+		require.Nil(t, results.Results[0].Content)
+		require.Contains(t, results.Results[0].Error, "exceeds the maximum response size")
+	})
+
+	t.Run("Encoded size", func(t *testing.T) {
+		results := &ContentResults{Results: []*ContentResult{{Address: "address1", Content: []byte("some content")}}}
+
+		resultsBytes, err := json.Marshal(results)
+		require.NoError(t, err)
+		require.True(t, len(resultsBytes) <= encodedSize("address1", []byte("some content"), ""))
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		errExpected := fmt.Errorf("read error")
+		stub := prepareStub(testProtocol, mocks.NewDCASClient().WithGetError(errExpected))
+
+		results := invokeContents(t, stub, readContents, coll1, testAddress(t, "content1"))
+		require.Len(t, results.Results, 1)
+		require.Contains(t, results.Results[0].Error, errExpected.Error())
+	})
+
+	t.Run("Missing args", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(readContents), []byte(coll1)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "collection and at least one content address are required")
+	})
+}
+
+func invokeContents(t *testing.T, stub *cmdmocks.MockStub, fctn string, coll string, items ...string) *ContentResults {
+	args := [][]byte{[]byte(fctn), []byte(coll)}
+	for _, item := range items {
+		args = append(args, []byte(item))
+	}
+
+	payload, err := invoke(stub, args)
+	require.NoError(t, err)
+
+	results := &ContentResults{}
+	require.NoError(t, json.Unmarshal(payload, results))
+
+	return results
+}
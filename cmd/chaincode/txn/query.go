@@ -0,0 +1,415 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/authorization"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+const (
+	// defaultPageSize is the page size used by the anchor queries if one isn't provided
+	defaultPageSize = 100
+
+	// maxPageSize is the maximum page size that may be requested by the anchor queries
+	maxPageSize = 1000
+)
+
+// indexMarker is the value stored for each key in the namespace index
+var indexMarker = []byte{0x00}
+
+// AnchorQueryResults contains a page of anchors returned by one of the anchor queries
+type AnchorQueryResults struct {
+	Anchors  []*AnchorResult `json:"anchors"`
+	Bookmark string          `json:"bookmark,omitempty"`
+}
+
+// IndexResults contains the results of a namespace index backfill
+type IndexResults struct {
+	// Indexed is the number of anchors that were added to the namespace index
+	Indexed int `json:"indexed"`
+	// Next is the anchor string from which the backfill continues, or empty if all anchors have been indexed
+	Next string `json:"next,omitempty"`
+}
+
+// AnchorResult contains an anchor string along with the Sidetree transaction info that was recorded for the anchor
+type AnchorResult struct {
+	AnchorString string          `json:"anchorString"`
+	TxnInfo      json.RawMessage `json:"txnInfo"`
+}
+
+// putNamespaceIndex records a composite key (AnchorPrefix, namespace, anchor string) so that the
+// anchors of a namespace may be scanned efficiently
+func putNamespaceIndex(stub shim.ChaincodeStubInterface, anchor *anchorInfo) error {
+	key, err := stub.CreateCompositeKey(common.AnchorPrefix, []string{anchor.namespace, anchor.anchorString})
+	if err != nil {
+		return err
+	}
+
+	return stub.PutState(key, indexMarker)
+}
+
+// indexAnchors adds existing anchors to the namespace index. Anchors that were written before the namespace
+// index was introduced aren't returned by queryAnchorsByNamespace until they have been indexed, so this function
+// must be invoked (repeatedly, passing the returned 'next' anchor string as the start anchor) after upgrading
+// from a chaincode version without the index. Re-indexing an anchor has no effect. Since indexing writes to the
+// namespace index, the client must be authorized to write anchors for the namespace of each anchor that is indexed.
+// Args: [start anchor], [max anchors]
+func (cc *SidetreeTxnCC) indexAnchors(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) > 2 {
+		errMsg := "expecting [start anchor], [max anchors]"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	startKey := common.AnchorPrefix
+	if len(args) > 0 {
+		startKey += string(args[0])
+	}
+
+	var maxAnchors int32 = defaultPageSize
+	if len(args) > 1 {
+		var err error
+		maxAnchors, _, err = getPagination(args[1:])
+		if err != nil {
+			logger.Debugf("[txID %s] %s", txID, err)
+			return shim.Error(err.Error())
+		}
+	}
+
+	results, err := cc.backfillNamespaceIndex(stub, startKey, int(maxAnchors))
+	if err != nil {
+		if errors.Cause(err) == authorization.ErrUnauthorized {
+			logger.Warnf("[txID %s] %s", txID, err)
+
+			return pb.Response{
+				Status:  403,
+				Message: err.Error(),
+			}
+		}
+
+		errMsg := fmt.Sprintf("failed to index anchors: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	logger.Infof("[txID %s] Indexed %d anchors - Next: [%s]", txID, results.Indexed, results.Next)
+
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to marshal index results: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(resultsBytes)
+}
+
+// backfillNamespaceIndex indexes up to maxAnchors anchors starting at the given key. A paginated query
+// may not be used since the function updates the state, so the number of anchors is limited while iterating.
+// An error is returned if the client isn't authorized to write anchors for the namespace of any of the anchors.
+func (cc *SidetreeTxnCC) backfillNamespaceIndex(stub shim.ChaincodeStubInterface, startKey string, maxAnchors int) (*IndexResults, error) {
+	it, err := stub.GetStateByRange(startKey, common.AnchorPrefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeIterator(it)
+
+	results := &IndexResults{}
+	authorized := make(map[string]bool)
+
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		anchorString := kv.Key[len(common.AnchorPrefix):]
+
+		if results.Indexed == maxAnchors {
+			results.Next = anchorString
+			break
+		}
+
+		txnInfo := &common.TxnInfo{}
+		if err := json.Unmarshal(kv.Value, txnInfo); err != nil {
+			return nil, fmt.Errorf("invalid txn info for anchor [%s]: %s", anchorString, err)
+		}
+
+		if !authorized[txnInfo.Namespace] {
+			if err := cc.authorizeWriter(stub, txnInfo.Namespace); err != nil {
+				return nil, err
+			}
+
+			authorized[txnInfo.Namespace] = true
+		}
+
+		if err := putNamespaceIndex(stub, &anchorInfo{anchorString: anchorString, namespace: txnInfo.Namespace}); err != nil {
+			return nil, err
+		}
+
+		results.Indexed++
+	}
+
+	return results, nil
+}
+
+// authorizeWriter returns an error if the client isn't authorized to write anchors for the given namespace
+func (cc *SidetreeTxnCC) authorizeWriter(stub shim.ChaincodeStubInterface, namespace string) error {
+	sidetreeCfg, err := cc.ForChannel(stub.GetChannelID()).LoadSidetree(namespace)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to load Sidetree config for namespace [%s]", namespace)
+	}
+
+	return authorization.Authorize(stub, namespace, sidetreeCfg.AnchorAuthorization)
+}
+
+// getAnchor returns the transaction info that was recorded for the given anchor string
+func (cc *SidetreeTxnCC) getAnchor(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) != 1 || len(args[0]) == 0 {
+		errMsg := "anchor string is required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	txnInfoBytes, err := stub.GetState(common.AnchorPrefix + string(args[0]))
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to read anchor: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	if txnInfoBytes == nil {
+		return pb.Response{
+			Status:  404,
+			Message: "anchor not found",
+		}
+	}
+
+	return shim.Success(txnInfoBytes)
+}
+
+// queryAnchorsByNamespace returns a page of anchors for the given namespace. Only anchors that are in the
+// namespace index are returned (see indexAnchors).
+// Args: namespace, [page size], [bookmark]
+func (cc *SidetreeTxnCC) queryAnchorsByNamespace(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 1 || len(args) > 3 || len(args[0]) == 0 {
+		errMsg := "namespace is required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	pageSize, bookmark, err := getPagination(args[1:])
+	if err != nil {
+		logger.Debugf("[txID %s] %s", txID, err)
+		return shim.Error(err.Error())
+	}
+
+	it, md, err := stub.GetStateByPartialCompositeKeyWithPagination(common.AnchorPrefix, []string{string(args[0])}, pageSize, bookmark)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to query anchors by namespace: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	results, err := getNamespaceIndexResults(stub, it, md)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to query anchors by namespace: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return marshalResults(txID, results)
+}
+
+// queryAnchorsByPrefix returns a page of anchors whose anchor string starts with the given prefix.
+// Args: prefix, [page size], [bookmark]
+func (cc *SidetreeTxnCC) queryAnchorsByPrefix(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 1 || len(args) > 3 {
+		errMsg := "anchor prefix is required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	prefix := string(args[0])
+
+	return cc.queryAnchors(stub, common.AnchorPrefix+prefix, common.AnchorPrefix+prefix+string(utf8.MaxRune), args[1:])
+}
+
+// queryAnchorsByRange returns a page of anchors whose anchor string is within the given range, where the
+// start anchor is inclusive and the end anchor is exclusive. An empty end anchor means an unbounded range.
+// Args: start anchor, end anchor, [page size], [bookmark]
+func (cc *SidetreeTxnCC) queryAnchorsByRange(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
+	txID := stub.GetTxID()
+	if len(args) < 2 || len(args) > 4 {
+		errMsg := "start and end anchor are required"
+		logger.Debugf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	endKey := common.AnchorPrefix + string(utf8.MaxRune)
+	if len(args[1]) > 0 {
+		endKey = common.AnchorPrefix + string(args[1])
+	}
+
+	return cc.queryAnchors(stub, common.AnchorPrefix+string(args[0]), endKey, args[2:])
+}
+
+func (cc *SidetreeTxnCC) queryAnchors(stub shim.ChaincodeStubInterface, startKey, endKey string, paginationArgs [][]byte) pb.Response {
+	txID := stub.GetTxID()
+
+	pageSize, bookmark, err := getPagination(paginationArgs)
+	if err != nil {
+		logger.Debugf("[txID %s] %s", txID, err)
+		return shim.Error(err.Error())
+	}
+
+	it, md, err := stub.GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to query anchors: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	results, err := getAnchorResults(it, md)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to query anchors: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return marshalResults(txID, results)
+}
+
+func getAnchorResults(it shim.StateQueryIteratorInterface, md *pb.QueryResponseMetadata) (*AnchorQueryResults, error) {
+	defer closeIterator(it)
+
+	results := newAnchorQueryResults(md)
+
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		results.Anchors = append(results.Anchors, &AnchorResult{
+			AnchorString: kv.Key[len(common.AnchorPrefix):],
+			TxnInfo:      kv.Value,
+		})
+	}
+
+	return results, nil
+}
+
+func getNamespaceIndexResults(stub shim.ChaincodeStubInterface, it shim.StateQueryIteratorInterface, md *pb.QueryResponseMetadata) (*AnchorQueryResults, error) {
+	defer closeIterator(it)
+
+	results := newAnchorQueryResults(md)
+
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, attributes, err := stub.SplitCompositeKey(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(attributes) != 2 {
+			return nil, fmt.Errorf("invalid namespace index key [%s]", kv.Key)
+		}
+
+		anchorString := attributes[1]
+
+		txnInfoBytes, err := stub.GetState(common.AnchorPrefix + anchorString)
+		if err != nil {
+			return nil, err
+		}
+
+		if txnInfoBytes == nil {
+			logger.Warnf("Anchor [%s] was not found for namespace index key", anchorString)
+			continue
+		}
+
+		results.Anchors = append(results.Anchors, &AnchorResult{
+			AnchorString: anchorString,
+			TxnInfo:      txnInfoBytes,
+		})
+	}
+
+	return results, nil
+}
+
+func newAnchorQueryResults(md *pb.QueryResponseMetadata) *AnchorQueryResults {
+	results := &AnchorQueryResults{
+		Anchors: []*AnchorResult{},
+	}
+
+	if md != nil {
+		results.Bookmark = md.Bookmark
+	}
+
+	return results
+}
+
+func marshalResults(txID string, results *AnchorQueryResults) pb.Response {
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to marshal anchor query results: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(resultsBytes)
+}
+
+// getPagination returns the page size and bookmark from the optional args: [page size], [bookmark]
+func getPagination(args [][]byte) (int32, string, error) {
+	pageSize := int32(defaultPageSize)
+	bookmark := ""
+
+	if len(args) > 0 && len(args[0]) > 0 {
+		size, err := strconv.ParseInt(string(args[0]), 10, 32)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid page size [%s]", args[0])
+		}
+
+		if size <= 0 || size > maxPageSize {
+			return 0, "", fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+		}
+
+		pageSize = int32(size)
+	}
+
+	if len(args) > 1 {
+		bookmark = string(args[1])
+	}
+
+	return pageSize, bookmark, nil
+}
+
+func closeIterator(it shim.StateQueryIteratorInterface) {
+	if err := it.Close(); err != nil {
+		logger.Warnf("Error closing iterator: %s", err)
+	}
+}
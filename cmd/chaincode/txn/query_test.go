@@ -0,0 +1,313 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmdmocks "github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+func TestGetAnchor(t *testing.T) {
+	stub := prepareDefaultStub()
+
+	txnInfoBytes := getTxnInfoBytes(t, 100)
+
+	_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor1")), txnInfoBytes})
+	require.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		payload, err := invoke(stub, [][]byte{[]byte(getAnchor), []byte(testAnchor(t, "anchor1"))})
+		require.NoError(t, err)
+		require.Equal(t, txnInfoBytes, payload)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		payload, err := invoke(stub, [][]byte{[]byte(getAnchor), []byte(testAnchor(t, "anchor2"))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor not found")
+		require.Nil(t, payload)
+	})
+
+	t.Run("Missing anchor string", func(t *testing.T) {
+		payload, err := invoke(stub, [][]byte{[]byte(getAnchor)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor string is required")
+		require.Nil(t, payload)
+	})
+}
+
+func TestQueryAnchorsByNamespace(t *testing.T) {
+	stub := prepareDefaultStub()
+
+	writeAnchors(t, stub, "ns1", "a1", "a2", "a3")
+	writeAnchors(t, stub, "ns2", "b1", "b2")
+
+	t.Run("Success", func(t *testing.T) {
+		results := queryAnchors(t, stub, queryAnchorsByNamespace, "ns1")
+		require.Equal(t, sortedAnchors(t, "a1", "a2", "a3"), anchorStrings(results))
+		require.Empty(t, results.Bookmark)
+
+		txnInfo := &common.TxnInfo{}
+		require.NoError(t, json.Unmarshal(results.Anchors[0].TxnInfo, txnInfo))
+		require.Equal(t, "ns1", txnInfo.Namespace)
+
+		results = queryAnchors(t, stub, queryAnchorsByNamespace, "ns2")
+		require.Equal(t, sortedAnchors(t, "b1", "b2"), anchorStrings(results))
+
+		results = queryAnchors(t, stub, queryAnchorsByNamespace, "ns3")
+		require.Empty(t, results.Anchors)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		expected := sortedAnchors(t, "a1", "a2", "a3")
+
+		results := queryAnchors(t, stub, queryAnchorsByNamespace, "ns1", "2")
+		require.Equal(t, expected[:2], anchorStrings(results))
+		require.NotEmpty(t, results.Bookmark)
+
+		results = queryAnchors(t, stub, queryAnchorsByNamespace, "ns1", "2", results.Bookmark)
+		require.Equal(t, expected[2:], anchorStrings(results))
+		require.Empty(t, results.Bookmark)
+	})
+
+	t.Run("Missing namespace", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByNamespace)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "namespace is required")
+	})
+
+	t.Run("Invalid page size", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByNamespace), []byte("ns1"), []byte("xxx")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid page size")
+
+		_, err = invoke(stub, [][]byte{[]byte(queryAnchorsByNamespace), []byte("ns1"), []byte("0")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "page size must be between 1 and")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected query error")
+		stub.GetStateByRangeErr = errExpected
+		defer func() { stub.GetStateByRangeErr = nil }()
+
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByNamespace), []byte("ns1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestIndexAnchors(t *testing.T) {
+	stub := prepareDefaultStub()
+
+	writeAnchors(t, stub, "ns1", "a1", "a2", "a3")
+	writeAnchors(t, stub, "ns2", "b1")
+
+	// Remove the namespace index in order to simulate anchors that were written before the index was introduced
+	stub.MockTransactionStart("tx1")
+	for _, kv := range []struct{ ns, name string }{{"ns1", "a1"}, {"ns1", "a2"}, {"ns1", "a3"}, {"ns2", "b1"}} {
+		key, err := stub.CreateCompositeKey(common.AnchorPrefix, []string{kv.ns, queryAnchor(t, kv.name)})
+		require.NoError(t, err)
+		require.NoError(t, stub.DelState(key))
+	}
+	stub.MockTransactionEnd("tx1")
+
+	require.Empty(t, queryAnchors(t, stub, queryAnchorsByNamespace, "ns1").Anchors)
+
+	t.Run("Success", func(t *testing.T) {
+		results := indexAnchorsWithArgs(t, stub, "", "3")
+		require.Equal(t, 3, results.Indexed)
+		require.NotEmpty(t, results.Next)
+
+		results = indexAnchorsWithArgs(t, stub, results.Next, "3")
+		require.Equal(t, 1, results.Indexed)
+		require.Empty(t, results.Next)
+
+		require.Equal(t, sortedAnchors(t, "a1", "a2", "a3"), anchorStrings(queryAnchors(t, stub, queryAnchorsByNamespace, "ns1")))
+		require.Equal(t, sortedAnchors(t, "b1"), anchorStrings(queryAnchors(t, stub, queryAnchorsByNamespace, "ns2")))
+
+		// Re-indexing has no effect
+		results = indexAnchorsWithArgs(t, stub)
+		require.Equal(t, 4, results.Indexed)
+		require.Len(t, queryAnchors(t, stub, queryAnchorsByNamespace, "ns1").Anchors, 3)
+	})
+
+	t.Run("Invalid args", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(indexAnchors), []byte(""), []byte("xxx")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid page size")
+
+		_, err = invoke(stub, [][]byte{[]byte(indexAnchors), []byte(""), []byte("1"), []byte("x")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expecting [start anchor], [max anchors]")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected query error")
+		stub.GetStateByRangeErr = errExpected
+		defer func() { stub.GetStateByRangeErr = nil }()
+
+		_, err := invoke(stub, [][]byte{[]byte(indexAnchors)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func indexAnchorsWithArgs(t *testing.T, stub *cmdmocks.MockStub, args ...string) *IndexResults {
+	invokeArgs := [][]byte{[]byte(indexAnchors)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	payload, err := invoke(stub, invokeArgs)
+	require.NoError(t, err)
+
+	results := &IndexResults{}
+	require.NoError(t, json.Unmarshal(payload, results))
+
+	return results
+}
+
+func TestQueryAnchorsByPrefix(t *testing.T) {
+	stub := prepareDefaultStub()
+
+	writeAnchors(t, stub, "ns1", "a1", "a2", "b1")
+	writeAnchors(t, stub, "ns2", "a3", "b2")
+
+	t.Run("Success", func(t *testing.T) {
+		results := queryAnchors(t, stub, queryAnchorsByPrefix, "1.")
+		require.Equal(t, sortedAnchors(t, "a1", "a2", "a3"), anchorStrings(results))
+
+		results = queryAnchors(t, stub, queryAnchorsByPrefix, "2.")
+		require.Equal(t, sortedAnchors(t, "b1", "b2"), anchorStrings(results))
+
+		results = queryAnchors(t, stub, queryAnchorsByPrefix, "")
+		require.Equal(t, sortedAnchors(t, "a1", "a2", "a3", "b1", "b2"), anchorStrings(results))
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		results := queryAnchors(t, stub, queryAnchorsByPrefix, "", "3")
+		require.Equal(t, sortedAnchors(t, "a1", "a2", "a3"), anchorStrings(results))
+		require.NotEmpty(t, results.Bookmark)
+
+		results = queryAnchors(t, stub, queryAnchorsByPrefix, "", "3", results.Bookmark)
+		require.Equal(t, sortedAnchors(t, "b1", "b2"), anchorStrings(results))
+		require.Empty(t, results.Bookmark)
+	})
+
+	t.Run("Missing prefix", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByPrefix)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "anchor prefix is required")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected query error")
+		stub.GetStateByRangeErr = errExpected
+		defer func() { stub.GetStateByRangeErr = nil }()
+
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByPrefix), []byte("1.")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestQueryAnchorsByRange(t *testing.T) {
+	stub := prepareDefaultStub()
+
+	writeAnchors(t, stub, "ns1", "a1", "a2", "a3", "a4")
+
+	expected := sortedAnchors(t, "a1", "a2", "a3", "a4")
+
+	t.Run("Success", func(t *testing.T) {
+		results := queryAnchors(t, stub, queryAnchorsByRange, expected[1], expected[3])
+		require.Equal(t, expected[1:3], anchorStrings(results))
+
+		results = queryAnchors(t, stub, queryAnchorsByRange, expected[1], "")
+		require.Equal(t, expected[1:], anchorStrings(results))
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		results := queryAnchors(t, stub, queryAnchorsByRange, "", "", "3")
+		require.Equal(t, expected[:3], anchorStrings(results))
+		require.NotEmpty(t, results.Bookmark)
+
+		results = queryAnchors(t, stub, queryAnchorsByRange, "", "", "3", results.Bookmark)
+		require.Equal(t, expected[3:], anchorStrings(results))
+		require.Empty(t, results.Bookmark)
+	})
+
+	t.Run("Missing args", func(t *testing.T) {
+		_, err := invoke(stub, [][]byte{[]byte(queryAnchorsByRange), []byte(queryAnchor(t, "a1"))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "start and end anchor are required")
+	})
+}
+
+func writeAnchors(t *testing.T, stub *cmdmocks.MockStub, namespace string, names ...string) {
+	args := [][]byte{[]byte(writeAnchor)}
+	for _, name := range names {
+		args = append(args, []byte(queryAnchor(t, name)), getNamespaceTxnInfoBytes(t, namespace, 100))
+	}
+
+	_, err := invoke(stub, args)
+	require.NoError(t, err)
+}
+
+func queryAnchors(t *testing.T, stub *cmdmocks.MockStub, fctn string, args ...string) *AnchorQueryResults {
+	invokeArgs := [][]byte{[]byte(fctn)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	payload, err := invoke(stub, invokeArgs)
+	require.NoError(t, err)
+
+	results := &AnchorQueryResults{}
+	require.NoError(t, json.Unmarshal(payload, results))
+
+	return results
+}
+
+func anchorStrings(results *AnchorQueryResults) []string {
+	var anchors []string
+	for _, anchor := range results.Anchors {
+		anchors = append(anchors, anchor.AnchorString)
+	}
+
+	return anchors
+}
+
+// queryAnchor returns an anchor string for the given name. Names starting with 'a' have one operation
+// and all other names have two operations, so that anchors may be queried by prefix.
+func queryAnchor(t *testing.T, name string) string {
+	numOps := 2
+	if name[0] == 'a' {
+		numOps = 1
+	}
+
+	return testAnchorWithOps(t, numOps, name)
+}
+
+// sortedAnchors returns the anchor strings for the given names in the order in which they're stored on the ledger
+func sortedAnchors(t *testing.T, names ...string) []string {
+	var anchors []string
+	for _, name := range names {
+		anchors = append(anchors, queryAnchor(t, name))
+	}
+
+	sort.Strings(anchors)
+
+	return anchors
+}
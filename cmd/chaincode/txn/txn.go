@@ -15,9 +15,13 @@ import (
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/flogging"
 	ccapi "github.com/hyperledger/fabric/extensions/chaincode/api"
+	"github.com/pkg/errors"
 	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/authorization"
 	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/cas"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
 	ctxcommon "github.com/trustbloc/sidetree-fabric/pkg/context/common"
 	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
 )
@@ -32,6 +36,15 @@ const (
 	readContent  = "readContent"
 	writeAnchor  = "writeAnchor"
 	warmup       = "warmup"
+
+	getAnchor               = "getAnchor"
+	queryAnchorsByNamespace = "queryAnchorsByNamespace"
+	queryAnchorsByPrefix    = "queryAnchorsByPrefix"
+	queryAnchorsByRange     = "queryAnchorsByRange"
+	indexAnchors            = "indexAnchors"
+
+	writeContents = "writeContents"
+	readContents  = "readContents"
 )
 
 // funcMap is a map of functions by function name
@@ -47,27 +60,41 @@ type DCASStubWrapperFactory interface {
 	CreateDCASClientStubWrapper(coll string, stub shim.ChaincodeStubInterface) (dcasclient.DCAS, error)
 }
 
+// SidetreeConfigProvider returns the Sidetree configuration service for a given channel
+type SidetreeConfigProvider interface {
+	ForChannel(channelID string) config.SidetreeService
+}
+
 // SidetreeTxnCC ...
 type SidetreeTxnCC struct {
 	name      string
 	functions funcMap
 	ProtocolClientChannelProvider
 	DCASStubWrapperFactory
+	SidetreeConfigProvider
 }
 
 // New returns chaincode
-func New(name string, pccp ProtocolClientChannelProvider, dcasClientFactory DCASStubWrapperFactory) *SidetreeTxnCC {
+func New(name string, pccp ProtocolClientChannelProvider, dcasClientFactory DCASStubWrapperFactory, configProvider SidetreeConfigProvider) *SidetreeTxnCC {
 	cc := &SidetreeTxnCC{
 		name:                          name,
 		functions:                     make(funcMap),
 		ProtocolClientChannelProvider: pccp,
 		DCASStubWrapperFactory:        dcasClientFactory,
+		SidetreeConfigProvider:        configProvider,
 	}
 
 	cc.functions[writeContent] = cc.write
 	cc.functions[readContent] = cc.read
 	cc.functions[writeAnchor] = cc.writeAnchor
 	cc.functions[warmup] = cc.warmup
+	cc.functions[getAnchor] = cc.getAnchor
+	cc.functions[queryAnchorsByNamespace] = cc.queryAnchorsByNamespace
+	cc.functions[queryAnchorsByPrefix] = cc.queryAnchorsByPrefix
+	cc.functions[queryAnchorsByRange] = cc.queryAnchorsByRange
+	cc.functions[indexAnchors] = cc.indexAnchors
+	cc.functions[writeContents] = cc.writeContents
+	cc.functions[readContents] = cc.readContents
 
 	return cc
 }
@@ -183,65 +210,209 @@ func (cc *SidetreeTxnCC) read(stub shim.ChaincodeStubInterface, args [][]byte) p
 	return shim.Success(payload)
 }
 
-// writeAnchor will record anchor info on the ledger
+type anchorInfo struct {
+	anchorString        string
+	namespace           string
+	protocolGenesisTime uint64
+	txnInfoBytes        []byte
+}
+
+// writeAnchor will record anchor info on the ledger. The arguments consist of one or more
+// anchor string/txn info pairs so that the anchors of multiple namespaces may be recorded in
+// a single transaction.
 func (cc *SidetreeTxnCC) writeAnchor(stub shim.ChaincodeStubInterface, args [][]byte) pb.Response {
 	txID := stub.GetTxID()
-	if len(args) != 2 || len(args[0]) == 0 || len(args[1]) == 0 {
+	if len(args) < 2 || len(args)%2 != 0 {
 		errMsg := "missing anchor string and/or txn info"
 		logger.Debugf("[txID %s] %s", txID, errMsg)
 		return shim.Error(errMsg)
 	}
 
-	anchorString := string(args[0])
-	txnInfoBytes := args[1]
+	var anchors []*anchorInfo
+	anchorStrings := make(map[string]struct{})
+	namespaceConfigs := make(map[string]config.Sidetree)
+
+	for i := 0; i < len(args); i += 2 {
+		if len(args[i]) == 0 || len(args[i+1]) == 0 {
+			errMsg := "missing anchor string and/or txn info"
+			logger.Debugf("[txID %s] %s", txID, errMsg)
+			return shim.Error(errMsg)
+		}
+
+		anchorString := string(args[i])
+		txnInfoBytes := args[i+1]
+
+		if _, exists := anchorStrings[anchorString]; exists {
+			errMsg := fmt.Sprintf("duplicate anchor string [%s] in request", anchorString)
+			logger.Debugf("[txID %s] %s", txID, errMsg)
+			return shim.Error(errMsg)
+		}
+
+		anchorStrings[anchorString] = struct{}{}
+
+		anchor, resp, ok := cc.checkAnchor(stub, anchorString, txnInfoBytes, namespaceConfigs)
+		if !ok {
+			return resp
+		}
+
+		anchors = append(anchors, anchor)
+	}
+
+	// All anchors are valid. Record each anchor string on the ledger plus Sidetree transaction info (anchor string, namespace)
+	for _, anchor := range anchors {
+		err := stub.PutState(common.AnchorPrefix+anchor.anchorString, anchor.txnInfoBytes)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to write anchor string: %s", err.Error())
+			logger.Errorf("[txID %s] %s", txID, errMsg)
+			return shim.Error(errMsg)
+		}
+
+		err = putNamespaceIndex(stub, anchor)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to write anchor namespace index: %s", err.Error())
+			logger.Errorf("[txID %s] %s", txID, errMsg)
+			return shim.Error(errMsg)
+		}
+	}
+
+	err := setAnchorEvent(stub, anchors)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to set anchor event: %s", err.Error())
+		logger.Errorf("[txID %s] %s", txID, errMsg)
+		return shim.Error(errMsg)
+	}
+
+	return shim.Success(nil)
+}
+
+// setAnchorEvent emits a chaincode event containing all of the anchors written in the transaction
+func setAnchorEvent(stub shim.ChaincodeStubInterface, anchors []*anchorInfo) error {
+	event := &common.AnchorEvent{}
+
+	for _, anchor := range anchors {
+		event.Anchors = append(event.Anchors, common.TxnInfo{
+			AnchorString:        anchor.anchorString,
+			Namespace:           anchor.namespace,
+			ProtocolGenesisTime: anchor.protocolGenesisTime,
+		})
+	}
+
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return stub.SetEvent(common.AnchorEventName, eventBytes)
+}
+
+// checkAnchor authorizes the client and validates the given anchor. False is returned along with an error
+// response if the anchor may not be written.
+func (cc *SidetreeTxnCC) checkAnchor(stub shim.ChaincodeStubInterface, anchorString string, txnInfoBytes []byte,
+	namespaceConfigs map[string]config.Sidetree) (*anchorInfo, pb.Response, bool) {
+	txID := stub.GetTxID()
 
 	txnInfo := &common.TxnInfo{}
 	err := json.Unmarshal(txnInfoBytes, txnInfo)
 	if err != nil {
 		errMsg := "invalid transaction info payload"
 		logger.Debugf("[txID %s] %s: %s", txID, errMsg, err.Error())
-		return shim.Error(errMsg)
+		return nil, shim.Error(errMsg), false
+	}
+
+	sidetreeCfg, ok := namespaceConfigs[txnInfo.Namespace]
+	if !ok {
+		sidetreeCfg, err = cc.ForChannel(stub.GetChannelID()).LoadSidetree(txnInfo.Namespace)
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to load Sidetree config for namespace [%s]: %s", txnInfo.Namespace, err.Error())
+			logger.Errorf("[txID %s] %s", txID, errMsg)
+			return nil, shim.Error(errMsg), false
+		}
+
+		if resp, ok := checkAuthorization(stub, txnInfo.Namespace, sidetreeCfg.AnchorAuthorization); !ok {
+			return nil, resp, false
+		}
+
+		namespaceConfigs[txnInfo.Namespace] = sidetreeCfg
 	}
 
-	err = cc.validateProtocolVersion(stub.GetChannelID(), txnInfo)
+	pv, err := cc.validateProtocolVersion(stub.GetChannelID(), txnInfo)
 	if err != nil {
-		return shim.Error(err.Error())
+		return nil, shim.Error(err.Error()), false
 	}
 
-	// record anchor string on the ledger plus Sidetree transaction info (anchor string, namespace)
-	err = stub.PutState(common.AnchorPrefix+anchorString, txnInfoBytes)
+	err = cc.validateAnchor(stub, anchorString, pv, sidetreeCfg.AnchorValidation)
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to write anchor string: %s", err.Error())
-		logger.Errorf("[txID %s] %s", txID, errMsg)
-		return shim.Error(errMsg)
+		logger.Debugf("[txID %s] %s", txID, err)
+
+		if errors.Cause(err) == errAnchorExists {
+			// A distinct status is returned so that the client may recognize an anchor that it has already written
+			return nil, pb.Response{
+				Status:  409,
+				Message: err.Error(),
+			}, false
+		}
+
+		return nil, shim.Error(err.Error()), false
 	}
 
-	return shim.Success(nil)
+	anchor := &anchorInfo{
+		anchorString:        anchorString,
+		namespace:           txnInfo.Namespace,
+		protocolGenesisTime: txnInfo.ProtocolGenesisTime,
+		txnInfoBytes:        txnInfoBytes,
+	}
+
+	return anchor, pb.Response{}, true
 }
 
-func (cc *SidetreeTxnCC) validateProtocolVersion(channelID string, txnInfo *common.TxnInfo) error {
+// checkAuthorization returns false along with an error response if the client is not authorized to write anchors
+// for the given namespace
+func checkAuthorization(stub shim.ChaincodeStubInterface, namespace string, rules config.AnchorAuthorization) (pb.Response, bool) {
+	txID := stub.GetTxID()
+
+	err := authorization.Authorize(stub, namespace, rules)
+	if err == nil {
+		return pb.Response{}, true
+	}
+
+	if errors.Cause(err) == authorization.ErrUnauthorized {
+		logger.Warnf("[txID %s] %s", txID, err)
+
+		return pb.Response{
+			Status:  403,
+			Message: err.Error(),
+		}, false
+	}
+
+	errMsg := fmt.Sprintf("failed to authorize client: %s", err.Error())
+	logger.Errorf("[txID %s] %s", txID, errMsg)
+
+	return shim.Error(errMsg), false
+}
+
+func (cc *SidetreeTxnCC) validateProtocolVersion(channelID string, txnInfo *common.TxnInfo) (protocol.Version, error) {
 	pcp, err := cc.ProtocolClientProviderForChannel(channelID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pc, err := pcp.ForNamespace(txnInfo.Namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pv, err := pc.Get(txnInfo.ProtocolGenesisTime)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if txnInfo.ProtocolGenesisTime != pv.Protocol().GenesisTime {
 		logger.Debugf("[%s] Request protocol genesis time [%d] ", channelID)
 
-		return fmt.Errorf("invalid protocol genesis time in request: %d", txnInfo.ProtocolGenesisTime)
+		return nil, fmt.Errorf("invalid protocol genesis time in request: %d", txnInfo.ProtocolGenesisTime)
 	}
 
-	return nil
+	return pv, nil
 }
 
 func (m funcMap) String() string {
@@ -21,25 +21,35 @@ import (
 	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
 
 	cmdmocks "github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	configmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
 	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+	"github.com/trustbloc/sidetree-fabric/pkg/protocolversion"
 )
 
 //go:generate counterfeiter -o ../mocks/protocolclientchannelprovider.gen.go --fake-name ProtocolClientChannelProvider . ProtocolClientChannelProvider
 //go:generate counterfeiter -o ../mocks/dcasstubwrapperfactory.gen.go --fake-name DCASStubWrapperFactory . DCASStubWrapperFactory
+//go:generate counterfeiter -o ../mocks/sidetreeconfigprovider.gen.go --fake-name SidetreeConfigProvider . SidetreeConfigProvider
 
 const (
 	ccName = "sidetreetxncc"
 	coll1  = "coll1"
 )
 
+var testProtocol = protocol.Protocol{
+	GenesisTime:       100,
+	MaxOperationCount: 10,
+	MaxCasURILength:   100,
+}
+
 func TestNew(t *testing.T) {
 	req := require.New(t)
 
 	pccp := &cmdmocks.ProtocolClientChannelProvider{}
 	dswf := &cmdmocks.DCASStubWrapperFactory{}
 
-	cc := New(ccName, pccp, dswf)
+	cc := New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{}))
 	req.NotNil(cc)
 
 	req.Empty(cc.GetDBArtifacts([]string{coll1}))
@@ -74,7 +84,7 @@ func TestWrite(t *testing.T) {
 	t.Run("Write error", func(t *testing.T) {
 		testErr := fmt.Errorf("write error")
 		dcasClient := mocks.NewDCASClient().WithPutError(testErr)
-		stub := prepareStub(protocol.Protocol{GenesisTime: 100}, dcasClient)
+		stub := prepareStub(testProtocol, dcasClient)
 
 		payload, err := invoke(stub, [][]byte{[]byte(writeContent), []byte(coll1), []byte("address")})
 		require.Error(t, err)
@@ -87,7 +97,7 @@ func TestWrite(t *testing.T) {
 		dswf := &cmdmocks.DCASStubWrapperFactory{}
 		dswf.CreateDCASClientStubWrapperReturns(nil, errExpected)
 
-		stub := prepareStubWithFactory(protocol.Protocol{GenesisTime: 100}, dswf)
+		stub := prepareStubWithFactory(testProtocol, dswf)
 
 		_, err := invoke(stub, [][]byte{[]byte(writeContent), []byte(coll1), []byte("address")})
 		require.Error(t, err)
@@ -128,7 +138,7 @@ func TestRead(t *testing.T) {
 	t.Run("Read error", func(t *testing.T) {
 		testErr := fmt.Errorf("read error")
 		dcasClient := mocks.NewDCASClient().WithGetError(testErr)
-		stub := prepareStub(protocol.Protocol{GenesisTime: 100}, dcasClient)
+		stub := prepareStub(testProtocol, dcasClient)
 
 		payload, err := invoke(stub, [][]byte{[]byte(readContent), []byte(coll1), []byte("address")})
 		require.NotNil(t, err)
@@ -141,7 +151,7 @@ func TestRead(t *testing.T) {
 		dswf := &cmdmocks.DCASStubWrapperFactory{}
 		dswf.CreateDCASClientStubWrapperReturns(nil, errExpected)
 
-		stub := prepareStubWithFactory(protocol.Protocol{GenesisTime: 100}, dswf)
+		stub := prepareStubWithFactory(testProtocol, dswf)
 
 		_, err := invoke(stub, [][]byte{[]byte(readContent), []byte(coll1), []byte(testPayloadAddress)})
 		require.Error(t, err)
@@ -159,10 +169,10 @@ func TestRead_MissingAddress(t *testing.T) {
 }
 
 func TestWriteAnchor(t *testing.T) {
-	stub := prepareStub(protocol.Protocol{GenesisTime: 100}, mocks.NewDCASClient())
+	stub := prepareStub(testProtocol, mocks.NewDCASClient())
 
 	t.Run("Success", func(t *testing.T) {
-		anchor := []byte("anchor1")
+		anchor := []byte(testAnchor(t, "anchor1"))
 		txnInfoBytes := getTxnInfoBytes(t, 100)
 
 		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), anchor, txnInfoBytes})
@@ -174,8 +184,64 @@ func TestWriteAnchor(t *testing.T) {
 		require.Equal(t, txnInfoBytes, result)
 	})
 
+	t.Run("Multiple anchors -> success", func(t *testing.T) {
+		txnInfo1 := getTxnInfoBytes(t, 100)
+		txnInfo2 := getNamespaceTxnInfoBytes(t, "ns2", 100)
+
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor3")), txnInfo1, []byte(testAnchor(t, "anchor4")), txnInfo2})
+		require.NoError(t, err)
+		require.Nil(t, payload)
+
+		result, err := stub.GetState(common.AnchorPrefix + testAnchor(t, "anchor3"))
+		require.NoError(t, err)
+		require.Equal(t, txnInfo1, result)
+
+		result, err = stub.GetState(common.AnchorPrefix + testAnchor(t, "anchor4"))
+		require.NoError(t, err)
+		require.Equal(t, txnInfo2, result)
+	})
+
+	t.Run("Anchor event", func(t *testing.T) {
+		anchor1 := testAnchor(t, "event1")
+		anchor2 := testAnchor(t, "event2")
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(anchor1), getTxnInfoBytes(t, 100), []byte(anchor2), getNamespaceTxnInfoBytes(t, "ns2", 100)})
+		require.NoError(t, err)
+
+		require.NotNil(t, stub.Event)
+		require.Equal(t, common.AnchorEventName, stub.Event.EventName)
+
+		event := &common.AnchorEvent{}
+		require.NoError(t, json.Unmarshal(stub.Event.Payload, event))
+		require.Equal(t, []common.TxnInfo{
+			{AnchorString: anchor1, Namespace: "ns", ProtocolGenesisTime: 100},
+			{AnchorString: anchor2, Namespace: "ns2", ProtocolGenesisTime: 100},
+		}, event.Anchors)
+	})
+
+	t.Run("Multiple anchors - duplicate anchor", func(t *testing.T) {
+		txnInfo := getTxnInfoBytes(t, 100)
+
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor5")), txnInfo, []byte(testAnchor(t, "anchor5")), txnInfo})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf("duplicate anchor string [%s] in request", testAnchor(t, "anchor5")))
+		require.Nil(t, payload)
+	})
+
+	t.Run("Multiple anchors - one invalid", func(t *testing.T) {
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor6")), getTxnInfoBytes(t, 100), []byte(testAnchor(t, "anchor7")), getTxnInfoBytes(t, 99)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid protocol genesis time in request")
+		require.Nil(t, payload)
+
+		// Neither anchor should have been written
+		result, err := stub.GetState(common.AnchorPrefix + testAnchor(t, "anchor6"))
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
 	t.Run("Invalid txn info bytes", func(t *testing.T) {
-		anchor := []byte("anchor2")
+		anchor := []byte(testAnchor(t, "anchor2"))
 
 		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), anchor, []byte("invalid TxnInfo")})
 		require.Error(t, err)
@@ -184,7 +250,7 @@ func TestWriteAnchor(t *testing.T) {
 	})
 
 	t.Run("Invalid protocol genesis time", func(t *testing.T) {
-		anchor := []byte("anchor2")
+		anchor := []byte(testAnchor(t, "anchor2"))
 
 		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), anchor, getTxnInfoBytes(t, 99)})
 		require.Error(t, err)
@@ -203,9 +269,9 @@ func TestWriteAnchor(t *testing.T) {
 		pccp.ProtocolClientProviderForChannelReturns(nil, errExpected)
 		dswf := &cmdmocks.DCASStubWrapperFactory{}
 
-		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf))
+		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{})))
 
-		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("anchor"), getTxnInfoBytes(t, 101)})
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor")), getTxnInfoBytes(t, 101)})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errExpected.Error())
 		require.Nil(t, payload)
@@ -221,9 +287,9 @@ func TestWriteAnchor(t *testing.T) {
 		pccp.ProtocolClientProviderForChannelReturns(pcp, nil)
 		dswf := &cmdmocks.DCASStubWrapperFactory{}
 
-		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf))
+		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{})))
 
-		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("anchor"), getTxnInfoBytes(t, 100)})
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor")), getTxnInfoBytes(t, 100)})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errExpected.Error())
 		require.Nil(t, payload)
@@ -242,19 +308,40 @@ func TestWriteAnchor(t *testing.T) {
 		pccp.ProtocolClientProviderForChannelReturns(pcp, nil)
 		dswf := &cmdmocks.DCASStubWrapperFactory{}
 
-		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf))
+		stub := cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{})))
 
-		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("anchor"), getTxnInfoBytes(t, 100)})
+		payload, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "anchor")), getTxnInfoBytes(t, 100)})
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errExpected.Error())
 		require.Nil(t, payload)
 	})
 }
 
+// testAnchor returns a valid anchor string with one operation and a core index file URI that's generated from the given content
+func testAnchor(t *testing.T, content string) string {
+	return testAnchorWithOps(t, 1, content)
+}
+
+func testAnchorWithOps(t *testing.T, numOps int, content string) string {
+	return fmt.Sprintf("%d.%s", numOps, testAddress(t, content))
+}
+
+// testAddress returns the DCAS address of the given content
+func testAddress(t *testing.T, content string) string {
+	address, err := dcas.GetCID([]byte(content), dcas.CIDV1, cid.Raw, mh.SHA2_256)
+	require.NoError(t, err)
+
+	return address
+}
+
 func getTxnInfoBytes(t *testing.T, protocolGenesisTime uint64) []byte {
+	return getNamespaceTxnInfoBytes(t, "ns", protocolGenesisTime)
+}
+
+func getNamespaceTxnInfoBytes(t *testing.T, namespace string, protocolGenesisTime uint64) []byte {
 	txnInfo := &common.TxnInfo{
 		AnchorString:        "anchor",
-		Namespace:           "ns",
+		Namespace:           namespace,
 		ProtocolGenesisTime: protocolGenesisTime,
 	}
 
@@ -290,6 +377,18 @@ func TestWriteAnchor_CheckRequiredArguments(t *testing.T) {
 	require.NotNil(t, err)
 	require.Nil(t, payload)
 	require.Contains(t, err.Error(), "missing anchor string and/or txn info")
+
+	// incomplete anchor/txn info pair
+	payload, err = invoke(stub, [][]byte{[]byte(writeAnchor), []byte("address"), getTxnInfoBytes(t, 100), []byte("address2")})
+	require.NotNil(t, err)
+	require.Nil(t, payload)
+	require.Contains(t, err.Error(), "missing anchor string and/or txn info")
+
+	// empty txn info in second pair
+	payload, err = invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "address")), getTxnInfoBytes(t, 100), []byte("address2"), []byte("")})
+	require.NotNil(t, err)
+	require.Nil(t, payload)
+	require.Contains(t, err.Error(), "missing anchor string and/or txn info")
 }
 
 func TestWarmup(t *testing.T) {
@@ -302,7 +401,7 @@ func TestWarmup(t *testing.T) {
 
 func TestHandlePanic(t *testing.T) {
 	dcasClient := mocks.NewDCASClient().WithPanic("panic")
-	stub := prepareStub(protocol.Protocol{GenesisTime: 100}, dcasClient)
+	stub := prepareStub(testProtocol, dcasClient)
 
 	payload, err := invoke(stub, [][]byte{[]byte(readContent), []byte(coll1), []byte("address")})
 	require.NotNil(t, err)
@@ -321,12 +420,13 @@ func testInvalidFunctionName(t *testing.T, stub *cmdmocks.MockStub) {
 }
 
 func prepareDefaultStub() *cmdmocks.MockStub {
-	return prepareStub(protocol.Protocol{GenesisTime: 100}, mocks.NewDCASClient())
+	return prepareStub(testProtocol, mocks.NewDCASClient())
 }
 
 func prepareStub(p protocol.Protocol, dcasClient dcasclient.DCAS) *cmdmocks.MockStub {
 	pv := &coremocks.ProtocolVersion{}
 	pv.ProtocolReturns(p)
+	pv.VersionReturns(protocolversion.V0_1)
 
 	pc := &mocks.ProtocolClient{}
 	pc.GetReturns(pv, nil)
@@ -340,12 +440,13 @@ func prepareStub(p protocol.Protocol, dcasClient dcasclient.DCAS) *cmdmocks.Mock
 	dswf := &cmdmocks.DCASStubWrapperFactory{}
 	dswf.CreateDCASClientStubWrapperReturns(dcasClient, nil)
 
-	return cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf))
+	return cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{})))
 }
 
 func prepareStubWithFactory(p protocol.Protocol, dswf DCASStubWrapperFactory) *cmdmocks.MockStub {
 	pv := &coremocks.ProtocolVersion{}
 	pv.ProtocolReturns(p)
+	pv.VersionReturns(protocolversion.V0_1)
 
 	pc := &mocks.ProtocolClient{}
 	pc.GetReturns(pv, nil)
@@ -356,7 +457,17 @@ func prepareStubWithFactory(p protocol.Protocol, dswf DCASStubWrapperFactory) *c
 	pccp := &cmdmocks.ProtocolClientChannelProvider{}
 	pccp.ProtocolClientProviderForChannelReturns(pcp, nil)
 
-	return cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf))
+	return cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, newConfigProvider(config.AnchorAuthorization{})))
+}
+
+func newConfigProvider(authCfg config.AnchorAuthorization) *cmdmocks.SidetreeConfigProvider {
+	configService := &configmocks.SidetreeConfigService{}
+	configService.LoadSidetreeReturns(config.Sidetree{AnchorAuthorization: authCfg}, nil)
+
+	configProvider := &cmdmocks.SidetreeConfigProvider{}
+	configProvider.ForChannelReturns(configService)
+
+	return configProvider
 }
 
 func checkInit(t *testing.T, stub *cmdmocks.MockStub, args [][]byte) {
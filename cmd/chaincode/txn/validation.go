@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/pkg/errors"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
+
+	"github.com/trustbloc/sidetree-fabric/cmd/chaincode/cas"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+	"github.com/trustbloc/sidetree-fabric/pkg/protocolversion"
+	vcommon "github.com/trustbloc/sidetree-fabric/pkg/protocolversion/common"
+)
+
+// errAnchorExists indicates that the anchor string was already written to the ledger
+var errAnchorExists = errors.New("anchor string already exists on the ledger")
+
+// anchorData contains the data that is parsed from an anchor string
+type anchorData struct {
+	numOperations    int
+	coreIndexFileURI string
+}
+
+// anchorParser parses an anchor string according to a version of the Sidetree protocol
+type anchorParser func(anchorString string) (*anchorData, error)
+
+// validateAnchor ensures that the anchor string is valid according to the given protocol version, that the anchor
+// doesn't already exist on the ledger and, optionally, that the core index file referenced by the anchor exists in DCAS.
+func (cc *SidetreeTxnCC) validateAnchor(stub shim.ChaincodeStubInterface, anchorString string, pv protocol.Version, cfg config.AnchorValidation) error {
+	parse, err := getAnchorParser(pv.Version())
+	if err != nil {
+		return err
+	}
+
+	ad, err := parse(anchorString)
+	if err != nil {
+		return errors.WithMessage(err, "invalid anchor string")
+	}
+
+	p := pv.Protocol()
+
+	if ad.numOperations > int(p.MaxOperationCount) {
+		return errors.Errorf("invalid anchor string [%s]: number of operations [%d] exceeds maximum [%d]", anchorString, ad.numOperations, p.MaxOperationCount)
+	}
+
+	if len(ad.coreIndexFileURI) > int(p.MaxCasURILength) {
+		return errors.Errorf("invalid anchor string [%s]: CAS URI length [%d] exceeds maximum [%d]", anchorString, len(ad.coreIndexFileURI), p.MaxCasURILength)
+	}
+
+	if err := dcas.ValidateCID(ad.coreIndexFileURI); err != nil {
+		return errors.Errorf("invalid anchor string [%s]: invalid CAS URI: %s", anchorString, err)
+	}
+
+	// Note that reading the anchor adds it to the read set of the transaction, so if the same anchor is submitted
+	// again before this transaction is committed (e.g. by a writer that timed out) then one of the transactions
+	// is invalidated with an MVCC conflict and the other succeeds.
+	existing, err := stub.GetState(common.AnchorPrefix + anchorString)
+	if err != nil {
+		return errors.WithMessage(err, "failed to read anchor")
+	}
+
+	if existing != nil {
+		return errors.WithMessagef(errAnchorExists, "anchor string [%s]", anchorString)
+	}
+
+	if cfg.VerifyCoreIndexFile {
+		return cc.verifyCoreIndexFile(stub, ad.coreIndexFileURI)
+	}
+
+	return nil
+}
+
+// verifyCoreIndexFile ensures that the core index file with the given URI exists in DCAS
+func (cc *SidetreeTxnCC) verifyCoreIndexFile(stub shim.ChaincodeStubInterface, uri string) error {
+	dcasCfg, err := cc.ForChannel(stub.GetChannelID()).LoadDCAS()
+	if err != nil {
+		return errors.WithMessage(err, "failed to load DCAS config")
+	}
+
+	dcasClient, err := cc.CreateDCASClientStubWrapper(dcasCfg.Collection, stub)
+	if err != nil {
+		return errors.WithMessage(err, "failed to create DCAS client")
+	}
+
+	content, err := cas.New(dcasClient).Read(uri)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to read core index file [%s]", uri)
+	}
+
+	if content == nil {
+		return errors.Errorf("core index file [%s] not found in DCAS", uri)
+	}
+
+	return nil
+}
+
+func getAnchorParser(version string) (anchorParser, error) {
+	if vcommon.Version(version).Matches(protocolversion.V0_1) {
+		return parseAnchorV0, nil
+	}
+
+	return nil, fmt.Errorf("protocol version [%s] not supported", version)
+}
+
+func parseAnchorV0(anchorString string) (*anchorData, error) {
+	ad, err := txnprovider.ParseAnchorData(anchorString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anchorData{
+		numOperations:    ad.NumberOfOperations,
+		coreIndexFileURI: ad.CoreIndexFileURI,
+	}, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	cmdmocks "github.com/trustbloc/sidetree-fabric/cmd/chaincode/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	configmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/protocolversion"
+)
+
+const dcasColl = "dcas"
+
+func TestWriteAnchor_Validation(t *testing.T) {
+	t.Run("Invalid anchor format -> error", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("anchor"), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid anchor string")
+
+		_, err = invoke(stub, [][]byte{[]byte(writeAnchor), []byte("0.xxx"), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "number of operations must be positive integer")
+	})
+
+	t.Run("Too many operations -> error", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchorWithOps(t, 11, "content")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "number of operations [11] exceeds maximum [10]")
+	})
+
+	t.Run("CAS URI too long -> error", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("1." + strings.Repeat("x", 101)), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "CAS URI length [101] exceeds maximum [100]")
+	})
+
+	t.Run("Invalid CAS URI -> error", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte("1.xxx"), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CAS URI")
+	})
+
+	t.Run("Anchor already on ledger -> error", func(t *testing.T) {
+		stub := prepareDefaultStub()
+
+		anchor := testAnchor(t, "content")
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(anchor), getTxnInfoBytes(t, 100)})
+		require.NoError(t, err)
+
+		resp := stub.MockInvoke("2", [][]byte{[]byte(writeAnchor), []byte(anchor), getTxnInfoBytes(t, 100)})
+		require.Equal(t, int32(409), resp.Status)
+		require.Contains(t, resp.Message, fmt.Sprintf("anchor string [%s]: anchor string already exists on the ledger", anchor))
+	})
+
+	t.Run("Unsupported protocol version -> error", func(t *testing.T) {
+		pv := &coremocks.ProtocolVersion{}
+		pv.ProtocolReturns(testProtocol)
+		pv.VersionReturns("2.0")
+
+		stub := prepareValidationStub(pv, newValidationConfigProvider(config.AnchorValidation{}, nil), mocks.NewDCASClient())
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "content")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "protocol version [2.0] not supported")
+	})
+}
+
+func TestWriteAnchor_VerifyCoreIndexFile(t *testing.T) {
+	cfg := config.AnchorValidation{VerifyCoreIndexFile: true}
+
+	t.Run("Core index file exists -> success", func(t *testing.T) {
+		dcasClient := mocks.NewDCASClient()
+		stub := prepareValidationStub(newProtocolVersion(), newValidationConfigProvider(cfg, nil), dcasClient)
+
+		uri, err := invoke(stub, [][]byte{[]byte(writeContent), []byte(dcasColl), []byte("content")})
+		require.NoError(t, err)
+
+		_, err = invoke(stub, [][]byte{[]byte(writeAnchor), []byte("1." + string(uri)), getTxnInfoBytes(t, 100)})
+		require.NoError(t, err)
+	})
+
+	t.Run("Core index file not found -> error", func(t *testing.T) {
+		stub := prepareValidationStub(newProtocolVersion(), newValidationConfigProvider(cfg, nil), mocks.NewDCASClient())
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "content")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found in DCAS")
+	})
+
+	t.Run("DCAS read error -> error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected DCAS error")
+
+		stub := prepareValidationStub(newProtocolVersion(), newValidationConfigProvider(cfg, nil), mocks.NewDCASClient().WithGetError(errExpected))
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "content")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("DCAS config error -> error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected DCAS config error")
+
+		stub := prepareValidationStub(newProtocolVersion(), newValidationConfigProvider(cfg, errExpected), mocks.NewDCASClient())
+
+		_, err := invoke(stub, [][]byte{[]byte(writeAnchor), []byte(testAnchor(t, "content")), getTxnInfoBytes(t, 100)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func newProtocolVersion() *coremocks.ProtocolVersion {
+	pv := &coremocks.ProtocolVersion{}
+	pv.ProtocolReturns(testProtocol)
+	pv.VersionReturns(protocolversion.V0_1)
+
+	return pv
+}
+
+func newValidationConfigProvider(cfg config.AnchorValidation, dcasErr error) *cmdmocks.SidetreeConfigProvider {
+	configService := &configmocks.SidetreeConfigService{}
+	configService.LoadSidetreeReturns(config.Sidetree{AnchorValidation: cfg}, nil)
+	configService.LoadDCASReturns(config.DCAS{Collection: dcasColl}, dcasErr)
+
+	configProvider := &cmdmocks.SidetreeConfigProvider{}
+	configProvider.ForChannelReturns(configService)
+
+	return configProvider
+}
+
+func prepareValidationStub(pv *coremocks.ProtocolVersion, configProvider SidetreeConfigProvider, dcasClient *mocks.MockDCASClient) *cmdmocks.MockStub {
+	pc := &mocks.ProtocolClient{}
+	pc.GetReturns(pv, nil)
+
+	pcp := &mocks.ProtocolClientProvider{}
+	pcp.ForNamespaceReturns(pc, nil)
+
+	pccp := &cmdmocks.ProtocolClientChannelProvider{}
+	pccp.ProtocolClientProviderForChannelReturns(pcp, nil)
+
+	dswf := &cmdmocks.DCASStubWrapperFactory{}
+	dswf.CreateDCASClientStubWrapperReturns(dcasClient, nil)
+
+	return cmdmocks.NewMockStub(ccName, New(ccName, pccp, dswf, configProvider))
+}
@@ -19,6 +19,10 @@ require (
 	github.com/hyperledger/fabric-sdk-go v1.0.0-beta3.0.20201103202456-5b6912cc2680
 	github.com/hyperledger/fabric/extensions v0.0.0
 	github.com/ipfs/go-cid v0.0.7
+	github.com/ipfs/go-datastore v0.4.5
+	github.com/ipfs/go-ipfs-ds-help v0.1.1
+	github.com/ipfs/go-merkledag v0.3.2
+	github.com/ipfs/go-unixfs v0.2.4
 	github.com/multiformats/go-multihash v0.0.14
 	github.com/op/go-logging v0.0.0-20160315200505-970db520ece7
 	github.com/pkg/errors v0.9.1
@@ -24,6 +24,7 @@ type Blockchain interface {
 	GetBlockchainInfo() (*cb.BlockchainInfo, error)
 	GetBlockByNumber(blockNumber uint64) (*cb.Block, error)
 	GetBlockByHash(blockHash []byte) (*cb.Block, error)
+	GetBlockByTxID(txID string) (*cb.Block, error)
 }
 
 // BlockchainProvider manages multiple blockchain clients - one per channel
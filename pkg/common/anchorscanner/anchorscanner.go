@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorscanner
+
+import (
+	"encoding/json"
+	"strings"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	"github.com/trustbloc/fabric-peer-ext/pkg/common/blockvisitor"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+var logger = flogging.MustGetLogger("sidetree_anchorscanner")
+
+var errReachedMaxAnchors = errors.New("maximum anchors reached")
+
+// Anchor contains the details of an anchor that was written to a block
+type Anchor struct {
+	BlockNum uint64
+	TxnNum   uint64
+	TxnInfo  common.TxnInfo
+}
+
+// Filter returns true if the given anchor is to be included in the results
+type Filter func(txnInfo *common.TxnInfo) bool
+
+// Scanner scans a block for anchor writes
+type Scanner struct {
+	channelID   string
+	block       *cb.Block
+	sinceTxnNum uint64
+	maxAnchors  int
+	filter      Filter
+	anchors     []*Anchor
+}
+
+// New returns a new anchor scanner for the given block. Only anchors in transactions with a transaction
+// number greater than or equal to sinceTxnNum are returned and, if the optional filter is provided,
+// only the anchors accepted by the filter are returned.
+func New(channelID string, block *cb.Block, sinceTxnNum uint64, maxAnchors int, filter Filter) *Scanner {
+	return &Scanner{
+		channelID:   channelID,
+		block:       block,
+		sinceTxnNum: sinceTxnNum,
+		maxAnchors:  maxAnchors,
+		filter:      filter,
+	}
+}
+
+// Scan returns the anchors in the block (in the order in which they were written). The returned boolean
+// is true if the block contains more than the maximum number of anchors.
+func (s *Scanner) Scan() ([]*Anchor, bool, error) {
+	visitor := blockvisitor.New(s.channelID,
+		blockvisitor.WithWriteHandler(s.handleWrite),
+		blockvisitor.WithErrorHandler(s.handleError),
+	)
+
+	err := visitor.Visit(s.block, nil)
+	if err != nil {
+		if errors.Cause(err) == errReachedMaxAnchors {
+			return s.anchors, true, nil
+		}
+
+		return nil, false, err
+	}
+
+	return s.anchors, false, nil
+}
+
+func (s *Scanner) handleWrite(w *blockvisitor.Write) error {
+	if !strings.HasPrefix(w.Write.Key, common.AnchorPrefix) {
+		logger.Debugf("[%s] Ignoring write to namespace [%s] in block [%d] and TxNum [%d] since the key doesn't have the anchor string prefix [%s]", s.channelID, w.Namespace, w.BlockNum, w.TxNum, common.AnchorPrefix)
+
+		return nil
+	}
+
+	if w.TxNum < s.sinceTxnNum {
+		logger.Debugf("[%s] Ignoring write in block [%d] and TxNum [%d] since the transaction number is less than the 'sinceTxnNum' %d", s.channelID, w.BlockNum, w.TxNum, s.sinceTxnNum)
+
+		return nil
+	}
+
+	txnInfo, err := getTxnInfo(w.Write.Value)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get anchor string [%s] in block [%d] and TxNum [%d]", w.Write.Key, w.BlockNum, w.TxNum)
+	}
+
+	if s.filter != nil && !s.filter(txnInfo) {
+		logger.Debugf("[%s] Ignoring anchor [%s] in block [%d] and TxNum [%d] since it was rejected by the filter", s.channelID, txnInfo.AnchorString, w.BlockNum, w.TxNum)
+
+		return nil
+	}
+
+	if len(s.anchors) >= s.maxAnchors {
+		return errReachedMaxAnchors
+	}
+
+	anchor := &Anchor{
+		BlockNum: w.BlockNum,
+		TxnNum:   w.TxNum,
+		TxnInfo:  *txnInfo,
+	}
+
+	logger.Debugf("[%s] Adding anchor %+v", s.channelID, anchor)
+
+	s.anchors = append(s.anchors, anchor)
+
+	return nil
+}
+
+func (s *Scanner) handleError(err error, ctx *blockvisitor.Context) error {
+	if err == errReachedMaxAnchors {
+		logger.Debugf("[%s] Reached the maximum number of anchors", s.channelID)
+
+		return err
+	}
+
+	logger.Errorf("[%s] Error processing block: %s. Context: %s. Block will be ignored.", s.channelID, err, ctx)
+	return nil
+}
+
+func getTxnInfo(value []byte) (*common.TxnInfo, error) {
+	txnInfo := &common.TxnInfo{}
+	if err := json.Unmarshal(value, txnInfo); err != nil {
+		return nil, err
+	}
+
+	return txnInfo, nil
+}
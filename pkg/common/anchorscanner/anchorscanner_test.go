@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorscanner
+
+import (
+	"fmt"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/mocks"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+const (
+	channel1 = "channel1"
+	blockNum = 1000
+
+	txn1 = "tx1"
+	txn2 = "tx2"
+	txn3 = "tx3"
+
+	anchor1 = "anchor1"
+	anchor2 = "anchor2"
+	anchor3 = "anchor3"
+
+	ns1 = "ns1"
+	ns2 = "ns2"
+)
+
+func TestScanner(t *testing.T) {
+	bb := mocks.NewBlockBuilder(channel1, blockNum)
+	bb.Transaction(txn1, pb.TxValidationCode_VALID).ChaincodeAction("sidetree").Write(common.AnchorPrefix, newTxnInfo(anchor1, ns1))
+	bb.Transaction(txn2, pb.TxValidationCode_VALID).ChaincodeAction("sidetree").Write(common.AnchorPrefix, newTxnInfo(anchor2, ns2))
+	bb.Transaction(txn3, pb.TxValidationCode_VALID).ChaincodeAction("sidetree").Write(common.AnchorPrefix, newTxnInfo(anchor3, ns1))
+	block := bb.Build()
+
+	t.Run("All anchors", func(t *testing.T) {
+		anchors, reachedMax, err := New(channel1, block, 0, 10, nil).Scan()
+		require.NoError(t, err)
+		require.False(t, reachedMax)
+		require.Len(t, anchors, 3)
+		require.Equal(t, anchor1, anchors[0].TxnInfo.AnchorString)
+		require.Equal(t, uint64(blockNum), anchors[0].BlockNum)
+		require.Equal(t, uint64(0), anchors[0].TxnNum)
+		require.Equal(t, anchor3, anchors[2].TxnInfo.AnchorString)
+		require.Equal(t, uint64(2), anchors[2].TxnNum)
+	})
+
+	t.Run("Since transaction number", func(t *testing.T) {
+		anchors, reachedMax, err := New(channel1, block, 1, 10, nil).Scan()
+		require.NoError(t, err)
+		require.False(t, reachedMax)
+		require.Len(t, anchors, 2)
+		require.Equal(t, anchor2, anchors[0].TxnInfo.AnchorString)
+	})
+
+	t.Run("Maximum reached", func(t *testing.T) {
+		anchors, reachedMax, err := New(channel1, block, 0, 2, nil).Scan()
+		require.NoError(t, err)
+		require.True(t, reachedMax)
+		require.Len(t, anchors, 2)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		filter := func(txnInfo *common.TxnInfo) bool {
+			return txnInfo.Namespace == ns1
+		}
+
+		anchors, reachedMax, err := New(channel1, block, 0, 10, filter).Scan()
+		require.NoError(t, err)
+		require.False(t, reachedMax)
+		require.Len(t, anchors, 2)
+		require.Equal(t, anchor1, anchors[0].TxnInfo.AnchorString)
+		require.Equal(t, anchor3, anchors[1].TxnInfo.AnchorString)
+
+		// The maximum only applies to anchors accepted by the filter
+		anchors, reachedMax, err = New(channel1, block, 0, 1, filter).Scan()
+		require.NoError(t, err)
+		require.True(t, reachedMax)
+		require.Len(t, anchors, 1)
+	})
+
+	t.Run("Invalid transaction info -> ignore", func(t *testing.T) {
+		bb := mocks.NewBlockBuilder(channel1, blockNum)
+		bb.Transaction(txn1, pb.TxValidationCode_VALID).ChaincodeAction("sidetree").Write(common.AnchorPrefix, []byte(anchor1))
+
+		anchors, reachedMax, err := New(channel1, bb.Build(), 0, 10, nil).Scan()
+		require.NoError(t, err)
+		require.False(t, reachedMax)
+		require.Empty(t, anchors)
+	})
+}
+
+func newTxnInfo(anchor, namespace string) []byte {
+	return []byte(fmt.Sprintf(`{"anchorString":"%s", "namespace": "%s"}`, anchor, namespace))
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcascid
+
+import (
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	"github.com/pkg/errors"
+)
+
+// ErrMismatch indicates that the content doesn't hash to the multihash of its CID
+var ErrMismatch = errors.New("content does not match CID")
+
+// FromKey returns the CID for the given DCAS data store key (which may have a prefix, e.g. "/blocks/...")
+func FromKey(key string) (string, error) {
+	k := key
+
+	if i := strings.LastIndex(key, "/"); i > 0 {
+		k = key[i:]
+	}
+
+	cID, err := dshelp.DsKeyToCid(datastore.NewKey(k))
+	if err != nil {
+		return "", err
+	}
+
+	return cID.String(), nil
+}
+
+// Verify re-hashes the given content using the hash function of the CID's multihash and returns ErrMismatch
+// if the result doesn't match the CID. An error is also returned if the CID is invalid or if its hash function
+// isn't supported.
+func Verify(cIDStr string, content []byte) error {
+	cID, err := cid.Decode(cIDStr)
+	if err != nil {
+		return errors.WithMessagef(err, "invalid CID [%s]", cIDStr)
+	}
+
+	computed, err := cID.Prefix().Sum(content)
+	if err != nil {
+		return errors.WithMessagef(err, "unable to hash content for CID [%s]", cIDStr)
+	}
+
+	if !computed.Equals(cID) {
+		return errors.WithMessagef(ErrMismatch, "computed CID [%s] for CID [%s]", computed, cIDStr)
+	}
+
+	return nil
+}
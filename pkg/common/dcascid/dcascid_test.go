@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcascid
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+)
+
+var content = []byte("some content")
+
+func TestFromKey(t *testing.T) {
+	cIDStr, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+	require.NoError(t, err)
+
+	cID, err := cid.Decode(cIDStr)
+	require.NoError(t, err)
+
+	key := dshelp.CidToDsKey(cID).String()
+
+	t.Run("No prefix", func(t *testing.T) {
+		c, err := FromKey(key)
+		require.NoError(t, err)
+		require.Equal(t, cIDStr, c)
+	})
+
+	t.Run("With prefix", func(t *testing.T) {
+		c, err := FromKey("/blocks" + key)
+		require.NoError(t, err)
+		require.Equal(t, cIDStr, c)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		c, err := FromKey("/blocks/invalid_key")
+		require.Error(t, err)
+		require.Empty(t, c)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("CIDv1 raw", func(t *testing.T) {
+		cIDStr, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+		require.NoError(t, err)
+
+		require.NoError(t, Verify(cIDStr, content))
+	})
+
+	t.Run("CIDv0", func(t *testing.T) {
+		cIDStr, err := dcas.GetCID(content, dcas.CIDV0, cid.DagProtobuf, mh.SHA2_256)
+		require.NoError(t, err)
+
+		require.NoError(t, Verify(cIDStr, content))
+	})
+
+	t.Run("SHA2-512", func(t *testing.T) {
+		cIDStr, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_512)
+		require.NoError(t, err)
+
+		require.NoError(t, Verify(cIDStr, content))
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		cIDStr, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+		require.NoError(t, err)
+
+		err = Verify(cIDStr, []byte("other content"))
+		require.Error(t, err)
+		require.Equal(t, ErrMismatch, errors.Cause(err))
+	})
+
+	t.Run("Invalid CID", func(t *testing.T) {
+		err := Verify("invalid", content)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CID")
+	})
+
+	t.Run("Unsupported hash", func(t *testing.T) {
+		hash, err := mh.Encode([]byte("1234"), mh.X11)
+		require.NoError(t, err)
+
+		err = Verify(cid.NewCidV1(cid.Raw, hash).String(), content)
+		require.Error(t, err)
+		require.NotEqual(t, ErrMismatch, errors.Cause(err))
+	})
+}
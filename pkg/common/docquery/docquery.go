@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docquery
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	operationFields = `"fields":["uniqueSuffix","type","operationBuffer","transactionTime","transactionNumber","protocolGenesisTime"]`
+
+	byUniqueSuffixTemplate         = `{"selector":{"uniqueSuffix":%s},"use_index":["_design/indexUniqueSuffixDoc","indexUniqueSuffix"],` + operationFields + `}`
+	byTransactionTimeRangeTemplate = `{"selector":{"transactionTime":{"$gte":%d,"$lt":%d}},` + operationFields + `}`
+	allOperationsQuery             = `{"selector":{"uniqueSuffix":{"$exists":true}},"use_index":["_design/indexUniqueSuffixDoc","indexUniqueSuffix"],"fields":["uniqueSuffix"]}`
+)
+
+// ByUniqueSuffix returns the CouchDB query for all operations of the document with the given unique suffix
+func ByUniqueSuffix(uniqueSuffix string) string {
+	return fmt.Sprintf(byUniqueSuffixTemplate, quote(uniqueSuffix))
+}
+
+// ByTransactionTimeRange returns the CouchDB query for all operations whose transaction time
+// is within the given range (from inclusive, to exclusive)
+func ByTransactionTimeRange(from, to uint64) string {
+	return fmt.Sprintf(byTransactionTimeRangeTemplate, from, to)
+}
+
+// AllOperations returns the CouchDB query for all operations. Only the unique suffix of each operation is returned.
+func AllOperations() string {
+	return allOperationsQuery
+}
+
+// quote returns the given value as a JSON string so that it may be safely embedded in a query
+func quote(value string) string {
+	// Marshalling a string never fails
+	b, _ := json.Marshal(value) //nolint:errcheck
+
+	return string(b)
+}
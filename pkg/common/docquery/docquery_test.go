@@ -0,0 +1,38 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package docquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByUniqueSuffix(t *testing.T) {
+	q := ByUniqueSuffix("suffix1")
+	require.Equal(t, `{"selector":{"uniqueSuffix":"suffix1"},"use_index":["_design/indexUniqueSuffixDoc","indexUniqueSuffix"],"fields":["uniqueSuffix","type","operationBuffer","transactionTime","transactionNumber","protocolGenesisTime"]}`, q)
+
+	t.Run("Escaped", func(t *testing.T) {
+		q := ByUniqueSuffix(`suffix1"},"uniqueSuffix":{"$gt":null`)
+
+		query := &struct {
+			Selector map[string]interface{} `json:"selector"`
+		}{}
+		require.NoError(t, json.Unmarshal([]byte(q), query))
+		require.Equal(t, `suffix1"},"uniqueSuffix":{"$gt":null`, query.Selector["uniqueSuffix"])
+	})
+}
+
+func TestByTransactionTimeRange(t *testing.T) {
+	q := ByTransactionTimeRange(10, 20)
+	require.Equal(t, `{"selector":{"transactionTime":{"$gte":10,"$lt":20}},"fields":["uniqueSuffix","type","operationBuffer","transactionTime","transactionNumber","protocolGenesisTime"]}`, q)
+}
+
+func TestAllOperations(t *testing.T) {
+	require.True(t, json.Valid([]byte(AllOperations())))
+}
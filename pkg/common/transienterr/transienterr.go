@@ -24,6 +24,10 @@ const (
 	CodeBlockchain Code = "BLOCKCHAIN"
 	// CodeDB indicates that an error occurred while accessing the database
 	CodeDB Code = "DB"
+	// CodeMVCCConflict indicates that a transaction was invalidated due to a read conflict with another transaction
+	CodeMVCCConflict Code = "MVCC_CONFLICT"
+	// CodeEndorsementMismatch indicates that the endorsements returned by the endorsing peers do not match
+	CodeEndorsementMismatch Code = "ENDORSEMENT_MISMATCH"
 )
 
 // Error is a transient error, meaning that a retry on the request may succeed
@@ -28,11 +28,59 @@ type Observer struct {
 	// occurs then a retry is attempted at the next scheduled interval. After processing has failed
 	// MaxAttempts times, the batch is lost and processing continues at the next transaction in the block.
 	MaxAttempts int
+	// UseChaincodeEvents indicates that the Observer is triggered by the chaincode events emitted by the txn
+	// chaincode (the DCAS chaincode) when anchors are written, rather than by inspecting the KV writes of each
+	// transaction. Anchor events emitted by any other chaincode are ignored.
+	UseChaincodeEvents bool
+}
+
+// AnchorAggregator holds the configuration for the channel-level anchor aggregator. The aggregator collects the
+// anchors written by the batch writers of all namespaces in the channel and writes them to the ledger in a single
+// transaction.
+type AnchorAggregator struct {
+	// Window is the period in which anchors are collected before they're written to the ledger. If 0 then anchors
+	// are not aggregated and each namespace's batch writer writes its own transaction.
+	Window time.Duration
+	// MaxAnchors is the maximum number of anchors written in a single transaction. If the maximum is reached before
+	// the window expires then the anchors are written immediately.
+	MaxAnchors int
+}
+
+// DCASGarbageCollector holds the configuration for the DCAS garbage collector. The collector periodically marks the
+// content in the channel's DCAS collection that's referenced by ledger anchors (core index, provisional index, chunk
+// and proof files), by any version of the file index documents of the file handlers (configured on any peer in the
+// same org) that store files in the same collection, or by a pinned CID. The remaining (unreferenced) content is listed
+// and, optionally, deleted. The peer must have a Sidetree handler for the index namespace of each of these file handlers,
+// otherwise the run is aborted. Nothing is swept while the files referenced by an anchor (or a file index document) can't
+// be read since the referenced content is unknown. Note that the file handlers of other orgs are not considered.
+type DCASGarbageCollector struct {
+	// Period is the period at which garbage collection runs. If 0 then garbage collection is disabled.
+	Period time.Duration
+	// GracePeriod is the minimum amount of time that content must have been found to be unreferenced before it's
+	// listed or deleted. This protects content that has been uploaded but not yet anchored or added to a file index.
+	GracePeriod time.Duration
+	// Delete indicates that unreferenced content is deleted. If false then unreferenced content is only listed (logged).
+	// If true then GracePeriod must be greater than 0.
+	Delete bool
+	// PinnedCIDs contains the CIDs of content that must be kept even if it isn't referenced. Content linked
+	// from a pinned CID is also kept.
+	PinnedCIDs []string
+}
+
+// DCASIntegrityScan holds the configuration for the background integrity scan of the channel's DCAS collection. The
+// scan re-hashes each item of stored content according to the multihash of its CID and reports (logs) the content
+// that doesn't match its CID or that can't be read.
+type DCASIntegrityScan struct {
+	// Period is the period at which the scan runs. If 0 then the background scan is disabled.
+	Period time.Duration
 }
 
 // SidetreePeer holds peer-specific Sidetree config
 type SidetreePeer struct {
-	Observer Observer
+	Observer             Observer
+	AnchorAggregator     AnchorAggregator
+	DCASGarbageCollector DCASGarbageCollector
+	DCASIntegrityScan    DCASIntegrityScan
 }
 
 // DCAS holds Distributed Content Addressable Store (DCAS) configuration
@@ -41,6 +89,50 @@ type DCAS struct {
 	Collection    string
 }
 
+// AnchorEndorsement holds the endorsement options that are used when writing anchors to the ledger
+type AnchorEndorsement struct {
+	// Orgs contains the MSP IDs of the orgs from which endorsers may be selected. If empty then
+	// endorsers may be selected from any org.
+	Orgs []string
+	// Peers contains the endpoints of the peers to which endorsement requests are sent. If empty then
+	// the endorsers are selected according to the chaincode's endorsement policy.
+	Peers []string
+	// MinEndorsements is the minimum number of endorsements that must be collected before the anchor
+	// is committed. If 0 then no minimum is enforced.
+	MinEndorsements int
+	// CommitTimeout is the maximum amount of time to wait for the anchor to be committed. If 0 then
+	// the timeout of the underlying transaction service applies. Note that an anchor that has timed out
+	// may still be committed, so a retry of the anchor waits for the pending transaction instead of
+	// writing the anchor again.
+	CommitTimeout time.Duration
+}
+
+// AnchorAuthorization holds the rules that determine which clients may write anchors for a namespace (or, when used
+// as the QueryAuthorization, which clients may query the operations of a namespace). A client must satisfy all of the
+// rules that are specified. If no rules are specified then any client is authorized.
+type AnchorAuthorization struct {
+	// MSPIDs contains the MSP IDs of the orgs whose clients may write anchors
+	MSPIDs []string
+	// OUs contains the organizational units that must all be present in the client's certificate
+	OUs []string
+	// Attributes contains the attributes that must all be present in the client's certificate
+	Attributes []Attribute
+}
+
+// Attribute is a client certificate attribute. If Value is empty then the attribute must be present
+// but may have any value.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// AnchorValidation holds the validation options that are applied by the txn chaincode when anchors are written
+type AnchorValidation struct {
+	// VerifyCoreIndexFile indicates that the core index file referenced by the anchor string must exist
+	// in DCAS before the anchor is written
+	VerifyCoreIndexFile bool
+}
+
 // Sidetree holds general Sidetree configuration
 type Sidetree struct {
 	ChaincodeName      string
@@ -48,6 +140,32 @@ type Sidetree struct {
 	BatchWriterTimeout time.Duration
 	MethodContext      []string
 	EnableBase         bool
+	// AnchorReceiptCollection is the off-ledger collection (within chaincode ChaincodeName) in which a receipt is
+	// stored for each anchor written by the batch writer. If empty then anchor receipts are not stored.
+	AnchorReceiptCollection string
+	// AnchorEndorsement contains the endorsement options for anchor writes
+	AnchorEndorsement AnchorEndorsement
+	// AnchorAuthorization contains the rules that are enforced by the txn chaincode when anchors are written
+	AnchorAuthorization AnchorAuthorization
+	// AnchorValidation contains the validation options that are applied by the txn chaincode when anchors are written
+	AnchorValidation AnchorValidation
+	// QueryAuthorization contains the rules that are enforced by the document chaincode when operations are queried
+	QueryAuthorization AnchorAuthorization
+}
+
+// DocumentIndexes holds the CouchDB indexes that are created for the document collections (in addition to
+// the default index on uniqueSuffix). The indexes are created when the document chaincode is deployed to the
+// channel, so changes are only applied the next time the chaincode definition is updated.
+type DocumentIndexes struct {
+	Collections []CollectionIndexes
+}
+
+// CollectionIndexes holds the CouchDB indexes for a collection. Each index is a CouchDB JSON index
+// definition, for example:
+// {"index": {"fields": ["transactionTime"]}, "ddoc": "indexTransactionTimeDoc", "name": "indexTransactionTime", "type": "json"}
+type CollectionIndexes struct {
+	Collection string
+	Indexes    []string
 }
 
 // SidetreeService is a service that loads Sidetree configuration
@@ -61,4 +179,5 @@ type SidetreeService interface {
 	LoadBlockchainHandlers(mspID, peerID string) ([]blockchainhandler.Config, error)
 	LoadDiscoveryHandlers(mspID, peerID string) ([]discoveryhandler.Config, error)
 	LoadDCAS() (DCAS, error)
+	LoadDocumentIndexes() (DocumentIndexes, error)
 }
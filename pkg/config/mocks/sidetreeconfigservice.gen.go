@@ -68,6 +68,18 @@ type SidetreeConfigService struct {
 		result1 []discoveryhandler.Config
 		result2 error
 	}
+	LoadDocumentIndexesStub        func() (config.DocumentIndexes, error)
+	loadDocumentIndexesMutex       sync.RWMutex
+	loadDocumentIndexesArgsForCall []struct {
+	}
+	loadDocumentIndexesReturns struct {
+		result1 config.DocumentIndexes
+		result2 error
+	}
+	loadDocumentIndexesReturnsOnCall map[int]struct {
+		result1 config.DocumentIndexes
+		result2 error
+	}
 	LoadFileHandlersStub        func(string, string) ([]filehandler.Config, error)
 	loadFileHandlersMutex       sync.RWMutex
 	loadFileHandlersArgsForCall []struct {
@@ -387,6 +399,61 @@ func (fake *SidetreeConfigService) LoadDiscoveryHandlersReturnsOnCall(i int, res
 	}{result1, result2}
 }
 
+func (fake *SidetreeConfigService) LoadDocumentIndexes() (config.DocumentIndexes, error) {
+	fake.loadDocumentIndexesMutex.Lock()
+	ret, specificReturn := fake.loadDocumentIndexesReturnsOnCall[len(fake.loadDocumentIndexesArgsForCall)]
+	fake.loadDocumentIndexesArgsForCall = append(fake.loadDocumentIndexesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("LoadDocumentIndexes", []interface{}{})
+	fake.loadDocumentIndexesMutex.Unlock()
+	if fake.LoadDocumentIndexesStub != nil {
+		return fake.LoadDocumentIndexesStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.loadDocumentIndexesReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *SidetreeConfigService) LoadDocumentIndexesCallCount() int {
+	fake.loadDocumentIndexesMutex.RLock()
+	defer fake.loadDocumentIndexesMutex.RUnlock()
+	return len(fake.loadDocumentIndexesArgsForCall)
+}
+
+func (fake *SidetreeConfigService) LoadDocumentIndexesCalls(stub func() (config.DocumentIndexes, error)) {
+	fake.loadDocumentIndexesMutex.Lock()
+	defer fake.loadDocumentIndexesMutex.Unlock()
+	fake.LoadDocumentIndexesStub = stub
+}
+
+func (fake *SidetreeConfigService) LoadDocumentIndexesReturns(result1 config.DocumentIndexes, result2 error) {
+	fake.loadDocumentIndexesMutex.Lock()
+	defer fake.loadDocumentIndexesMutex.Unlock()
+	fake.LoadDocumentIndexesStub = nil
+	fake.loadDocumentIndexesReturns = struct {
+		result1 config.DocumentIndexes
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *SidetreeConfigService) LoadDocumentIndexesReturnsOnCall(i int, result1 config.DocumentIndexes, result2 error) {
+	fake.loadDocumentIndexesMutex.Lock()
+	defer fake.loadDocumentIndexesMutex.Unlock()
+	fake.LoadDocumentIndexesStub = nil
+	if fake.loadDocumentIndexesReturnsOnCall == nil {
+		fake.loadDocumentIndexesReturnsOnCall = make(map[int]struct {
+			result1 config.DocumentIndexes
+			result2 error
+		})
+	}
+	fake.loadDocumentIndexesReturnsOnCall[i] = struct {
+		result1 config.DocumentIndexes
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *SidetreeConfigService) LoadFileHandlers(arg1 string, arg2 string) ([]filehandler.Config, error) {
 	fake.loadFileHandlersMutex.Lock()
 	ret, specificReturn := fake.loadFileHandlersReturnsOnCall[len(fake.loadFileHandlersArgsForCall)]
@@ -716,6 +783,8 @@ func (fake *SidetreeConfigService) Invocations() map[string][][]interface{} {
 	defer fake.loadDCASHandlersMutex.RUnlock()
 	fake.loadDiscoveryHandlersMutex.RLock()
 	defer fake.loadDiscoveryHandlersMutex.RUnlock()
+	fake.loadDocumentIndexesMutex.RLock()
+	defer fake.loadDocumentIndexesMutex.RUnlock()
 	fake.loadFileHandlersMutex.RLock()
 	defer fake.loadFileHandlersMutex.RUnlock()
 	fake.loadProtocolsMutex.RLock()
@@ -0,0 +1,231 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchain
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+)
+
+const defaultMaxAnchors = 50
+
+// ErrAggregatorStopped indicates that the anchor could not be written since the aggregator was stopped
+var ErrAggregatorStopped = errors.New("anchor aggregator stopped")
+
+type anchorRequest struct {
+	anchor      string
+	txnInfo     []byte
+	endorsement config.AnchorEndorsement
+	respChan    chan *commitResult
+}
+
+// Aggregator collects the anchors written by the batch writers of multiple namespaces within
+// a time window and writes them to the ledger in a single transaction
+type Aggregator struct {
+	channelID     string
+	chaincodeName string
+	txnProvider   txnServiceProvider
+	window        time.Duration
+	maxAnchors    int
+	reqChan       chan *anchorRequest
+	done          chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewAggregator returns a new anchor aggregator
+func NewAggregator(channelID, chaincodeName string, cfg config.AnchorAggregator, txnProvider txnServiceProvider) *Aggregator {
+	maxAnchors := cfg.MaxAnchors
+	if maxAnchors == 0 {
+		maxAnchors = defaultMaxAnchors
+	}
+
+	logger.Debugf("[%s] Creating anchor aggregator - Window: %s, MaxAnchors: %d", channelID, cfg.Window, maxAnchors)
+
+	return &Aggregator{
+		channelID:     channelID,
+		chaincodeName: chaincodeName,
+		txnProvider:   txnProvider,
+		window:        cfg.Window,
+		maxAnchors:    maxAnchors,
+		reqChan:       make(chan *anchorRequest),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts the aggregator
+func (a *Aggregator) Start() {
+	logger.Debugf("[%s] Starting anchor aggregator", a.channelID)
+
+	go a.listen()
+}
+
+// Stop stops the aggregator. Any anchors that have been collected are written to the ledger.
+func (a *Aggregator) Stop() {
+	a.stopOnce.Do(func() {
+		logger.Debugf("[%s] Stopping anchor aggregator", a.channelID)
+
+		close(a.done)
+	})
+}
+
+// EndorseAndCommit adds the anchor to the current aggregate transaction and blocks until the
+// aggregate transaction is committed. Anchors are only aggregated with other anchors that have
+// the same endorsement options.
+func (a *Aggregator) EndorseAndCommit(anchor string, txnInfo []byte, endorsement config.AnchorEndorsement) (*channel.Response, bool, error) {
+	r := <-a.submit(anchor, txnInfo, endorsement)
+
+	return r.resp, r.committed, r.err
+}
+
+// submit adds the anchor to the current aggregate transaction and returns the channel to which
+// the result is sent once the aggregate transaction has completed
+func (a *Aggregator) submit(anchor string, txnInfo []byte, endorsement config.AnchorEndorsement) <-chan *commitResult {
+	req := &anchorRequest{
+		anchor:      anchor,
+		txnInfo:     txnInfo,
+		endorsement: endorsement,
+		respChan:    make(chan *commitResult, 1),
+	}
+
+	select {
+	case a.reqChan <- req:
+	case <-a.done:
+		req.respChan <- &commitResult{err: transienterr.New(ErrAggregatorStopped, transienterr.CodeBlockchain)}
+	}
+
+	return req.respChan
+}
+
+func (a *Aggregator) listen() {
+	var pending []*anchorRequest
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case req := <-a.reqChan:
+			pending = append(pending, req)
+
+			if len(pending) == 1 {
+				timer = time.After(a.window)
+			}
+
+			if len(pending) >= a.maxAnchors {
+				logger.Debugf("[%s] Reached the maximum number of anchors: %d", a.channelID, a.maxAnchors)
+
+				a.commitAll(pending)
+
+				pending = nil
+				timer = nil
+			}
+
+		case <-timer:
+			a.commitAll(pending)
+
+			pending = nil
+			timer = nil
+
+		case <-a.done:
+			a.commitAll(pending)
+
+			logger.Debugf("[%s] Anchor aggregator stopped", a.channelID)
+
+			return
+		}
+	}
+}
+
+// commitAll groups the given requests by endorsement options and commits each group in its own transaction
+func (a *Aggregator) commitAll(reqs []*anchorRequest) {
+	var groups [][]*anchorRequest
+	groupIndexes := make(map[endorsementKey]int)
+
+	for _, req := range reqs {
+		key := newEndorsementKey(req.endorsement)
+
+		i, ok := groupIndexes[key]
+		if !ok {
+			i = len(groups)
+			groupIndexes[key] = i
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], req)
+	}
+
+	for _, group := range groups {
+		go a.commit(group)
+	}
+}
+
+// commit writes the given anchors in a single transaction. If the chaincode rejects the transaction then, since one
+// invalid anchor (e.g. an anchor of a namespace for which the client isn't authorized) causes the entire transaction to
+// be rejected, each anchor is written in its own transaction so that only the invalid anchors fail.
+func (a *Aggregator) commit(reqs []*anchorRequest) {
+	logger.Debugf("[%s] Writing %d anchor(s) in a single transaction", a.channelID, len(reqs))
+
+	result := a.endorseAndCommit(reqs)
+
+	if result.rejected && len(reqs) > 1 {
+		logger.Warnf("[%s] Transaction with %d anchors was rejected: %s. Writing each anchor in its own transaction.", a.channelID, len(reqs), result.err)
+
+		for _, req := range reqs {
+			req.respChan <- a.endorseAndCommit([]*anchorRequest{req})
+		}
+
+		return
+	}
+
+	for _, req := range reqs {
+		req.respChan <- result
+	}
+}
+
+func (a *Aggregator) endorseAndCommit(reqs []*anchorRequest) *commitResult {
+	txnService, err := a.txnProvider.ForChannel(a.channelID)
+	if err != nil {
+		return &commitResult{err: transienterr.New(errors.Wrap(err, "failed to store anchor string"), transienterr.CodeBlockchain)}
+	}
+
+	args := [][]byte{[]byte(writeAnchorFcn)}
+	for _, req := range reqs {
+		args = append(args, []byte(req.anchor), req.txnInfo)
+	}
+
+	return endorseAndCommit(txnService, a.chaincodeName, args, reqs[0].endorsement)
+}
+
+// endorsementKey is a comparable representation of the endorsement options that is used to group anchors.
+// The commit timeout isn't included since it only applies to the writer that is waiting for the transaction.
+type endorsementKey struct {
+	orgs            string
+	peers           string
+	minEndorsements int
+}
+
+func newEndorsementKey(cfg config.AnchorEndorsement) endorsementKey {
+	return endorsementKey{
+		orgs:            joinSorted(cfg.Orgs),
+		peers:           joinSorted(cfg.Peers),
+		minEndorsements: cfg.MinEndorsements,
+	}
+}
+
+func joinSorted(values []string) string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchain
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	txnapi "github.com/trustbloc/fabric-peer-ext/pkg/txn/api"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	stmocks "github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestAggregator(t *testing.T) {
+	const ns2 = "did:other"
+
+	t.Run("Window expires", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: 100 * time.Millisecond}, txnProvider)
+		require.NotNil(t, a)
+
+		a.Start()
+		defer a.Stop()
+
+		c1 := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+		c2 := New(chID, ccName, ns2, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c1.WriteAnchor("anchor1", nil, 100))
+		}()
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c2.WriteAnchor("anchor2", nil, 100))
+		}()
+
+		wg.Wait()
+
+		require.Equal(t, 1, txnService.EndorseAndCommitCallCount())
+
+		req := txnService.EndorseAndCommitArgsForCall(0)
+		require.Equal(t, ccName, req.ChaincodeID)
+		require.Len(t, req.Args, 5)
+		require.Equal(t, writeAnchorFcn, string(req.Args[0]))
+	})
+
+	t.Run("Max anchors reached", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: time.Minute, MaxAnchors: 1}, txnProvider)
+		a.Start()
+		defer a.Stop()
+
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+
+		require.NoError(t, c.WriteAnchor("anchor1", nil, 100))
+		require.NoError(t, c.WriteAnchor("anchor2", nil, 100))
+		require.Equal(t, 2, txnService.EndorseAndCommitCallCount())
+	})
+
+	t.Run("Different endorsement options", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: 100 * time.Millisecond}, txnProvider)
+		a.Start()
+		defer a.Stop()
+
+		c1 := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+		c2 := New(chID, ccName, ns2, config.AnchorEndorsement{Orgs: []string{"Org1MSP"}}, txnProvider, nil, nil, a)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c1.WriteAnchor("anchor1", nil, 100))
+		}()
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c2.WriteAnchor("anchor2", nil, 100))
+		}()
+
+		wg.Wait()
+
+		// Anchors with different endorsement options are written in separate transactions
+		require.Equal(t, 2, txnService.EndorseAndCommitCallCount())
+	})
+
+	t.Run("Endorsement key", func(t *testing.T) {
+		require.Equal(t,
+			newEndorsementKey(config.AnchorEndorsement{Orgs: []string{"Org1MSP", "Org2MSP"}, MinEndorsements: 2}),
+			newEndorsementKey(config.AnchorEndorsement{Orgs: []string{"Org2MSP", "Org1MSP"}, MinEndorsements: 2, CommitTimeout: time.Second}),
+		)
+		require.NotEqual(t,
+			newEndorsementKey(config.AnchorEndorsement{Peers: []string{"peer1"}}),
+			newEndorsementKey(config.AnchorEndorsement{Orgs: []string{"peer1"}}),
+		)
+	})
+
+	t.Run("Endorse and commit error", func(t *testing.T) {
+		testErr := errors.New("injected commit error")
+
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(nil, false, testErr)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: time.Millisecond}, txnProvider)
+		a.Start()
+		defer a.Stop()
+
+		err := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a).WriteAnchor("anchor1", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), testErr.Error())
+		require.True(t, transienterr.Is(err))
+	})
+
+	t.Run("Rejected by chaincode", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitStub = func(req *txnapi.Request) (*channel.Response, bool, error) {
+			for _, arg := range req.Args {
+				if string(arg) == "invalid-anchor" {
+					return nil, false, status.New(status.EndorserServerStatus, 500, "invalid anchor", nil)
+				}
+			}
+
+			return &channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil
+		}
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: 100 * time.Millisecond}, txnProvider)
+		a.Start()
+		defer a.Stop()
+
+		c1 := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+		c2 := New(chID, ccName, ns2, config.AnchorEndorsement{}, txnProvider, nil, nil, a)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c1.WriteAnchor("anchor1", nil, 100))
+		}()
+
+		go func() {
+			defer wg.Done()
+			err := c2.WriteAnchor("invalid-anchor", nil, 100)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid anchor")
+		}()
+
+		wg.Wait()
+
+		// The aggregate transaction is rejected so each anchor is written in its own transaction
+		require.Equal(t, 3, txnService.EndorseAndCommitCallCount())
+		require.Len(t, txnService.EndorseAndCommitArgsForCall(0).Args, 5)
+		require.Len(t, txnService.EndorseAndCommitArgsForCall(1).Args, 3)
+		require.Len(t, txnService.EndorseAndCommitArgsForCall(2).Args, 3)
+	})
+
+	t.Run("Txn provider error", func(t *testing.T) {
+		testErr := errors.New("injected provider error")
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(nil, testErr)
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: time.Millisecond}, txnProvider)
+		a.Start()
+		defer a.Stop()
+
+		err := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, a).WriteAnchor("anchor1", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), testErr.Error())
+	})
+
+	t.Run("Stopped", func(t *testing.T) {
+		txnProvider := &stmocks.TxnServiceProvider{}
+
+		a := NewAggregator(chID, ccName, config.AnchorAggregator{Window: time.Millisecond}, txnProvider)
+		a.Start()
+		a.Stop()
+		a.Stop()
+
+		resp, committed, err := a.EndorseAndCommit("anchor1", []byte("{}"), config.AnchorEndorsement{})
+		require.EqualError(t, err, ErrAggregatorStopped.Error())
+		require.False(t, committed)
+		require.Nil(t, resp)
+	})
+}
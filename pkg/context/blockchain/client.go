@@ -8,16 +8,28 @@ package blockchain
 
 import (
 	"encoding/json"
+	"math"
+	"sync"
+	"time"
 
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/pkg/errors"
 	txnapi "github.com/trustbloc/fabric-peer-ext/pkg/txn/api"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
-	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/common/anchorscanner"
 	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
 )
 
+var logger = flogging.MustGetLogger("sidetree_context")
+
 const (
 	writeAnchorFcn = "writeAnchor"
 )
@@ -26,31 +38,72 @@ type txnServiceProvider interface {
 	ForChannel(channelID string) (txnapi.Service, error)
 }
 
+type blockchainLedger interface {
+	GetBlockchainInfo() (*cb.BlockchainInfo, error)
+	GetBlockByNumber(blockNumber uint64) (*cb.Block, error)
+	GetBlockByTxID(txID string) (*cb.Block, error)
+}
+
+type receiptStore interface {
+	Put(r *receipt.Receipt) error
+}
+
 // Client implements blockchain client for writing anchors
 type Client struct {
 	channelID     string
 	chaincodeName string
 	txnProvider   txnServiceProvider
 	namespace     string
+	endorsement   config.AnchorEndorsement
+	ledger        blockchainLedger
+	receipts      receiptStore
+	aggregator    *Aggregator
+	mutex         sync.Mutex
+	cursor        *cursor
+	inFlightMutex sync.Mutex
+	inFlight      map[string]<-chan *commitResult
+}
+
+// cursor holds the anchors (of the client's namespace) in the block of the anchor that was last returned by Read
+// along with the index of that anchor within the block and its sequence number. The cursor only avoids rescanning
+// the ledger when reading sequentially; any sequence number may be resolved without it.
+type cursor struct {
+	blockNum uint64
+	anchors  []*anchorscanner.Anchor
+	index    int
+	seq      uint64
 }
 
-// New returns a new blockchain client
-func New(channelID, chaincodeName, namespace string, txnProvider txnServiceProvider) *Client {
+func (c *cursor) current() *anchorscanner.Anchor {
+	return c.anchors[c.index]
+}
+
+// nextSeq returns the sequence number of the first anchor in the block that follows the cursor's block
+func (c *cursor) nextSeq() uint64 {
+	return c.seq - uint64(c.index) + uint64(len(c.anchors))
+}
+
+// New returns a new blockchain client. The receipt store is optional. If it is nil then
+// anchor receipts are not persisted. The aggregator is also optional. If it is nil then
+// each anchor is written in its own transaction.
+func New(channelID, chaincodeName, namespace string, endorsementCfg config.AnchorEndorsement, txnProvider txnServiceProvider,
+	ledger blockchainLedger, receipts receiptStore, aggregator *Aggregator) *Client {
 	return &Client{
 		channelID:     channelID,
 		chaincodeName: chaincodeName,
 		txnProvider:   txnProvider,
 		namespace:     namespace,
+		endorsement:   endorsementCfg,
+		ledger:        ledger,
+		receipts:      receipts,
+		aggregator:    aggregator,
+		inFlight:      make(map[string]<-chan *commitResult),
 	}
 }
 
-// WriteAnchor writes anchor string to blockchain
-func (c *Client) WriteAnchor(anchor string, _ []*operation.Reference, protocolGenesisTime uint64) error {
-	txnService, err := c.txnProvider.ForChannel(c.channelID)
-	if err != nil {
-		return err
-	}
-
+// WriteAnchor writes anchor string to blockchain and waits for the transaction to be committed.
+// If a receipt store was provided then a receipt for the anchor is persisted.
+func (c *Client) WriteAnchor(anchor string, refs []*operation.Reference, protocolGenesisTime uint64) error {
 	txnInfo := common.TxnInfo{
 		AnchorString:        anchor,
 		Namespace:           c.namespace,
@@ -62,19 +115,316 @@ func (c *Client) WriteAnchor(anchor string, _ []*operation.Reference, protocolGe
 		return err
 	}
 
-	_, _, err = txnService.EndorseAndCommit(&txnapi.Request{
-		ChaincodeID: c.chaincodeName,
-		Args:        [][]byte{[]byte(writeAnchorFcn), []byte(anchor), txnInfoBytes},
-	})
+	resp, committed, err := c.endorseAndCommit(anchor, txnInfoBytes)
 	if err != nil {
-		return transienterr.New(errors.Wrap(err, "failed to store anchor string"), transienterr.CodeBlockchain)
+		if errors.Cause(err) == errAnchorExists {
+			logger.Warnf("[%s:%s] Anchor string [%s] was already written to the ledger", c.channelID, c.namespace, anchor)
+
+			return nil
+		}
+
+		return err
+	}
+
+	if !committed || resp == nil {
+		return transienterr.New(errors.Errorf("anchor string [%s] was not committed", anchor), transienterr.CodeBlockchain)
+	}
+
+	txnID := string(resp.TransactionID)
+
+	if isMVCCConflict(resp.TxValidationCode) {
+		return transienterr.New(errors.Errorf("transaction [%s] for anchor string [%s] was invalidated due to MVCC conflict [%s]", txnID, anchor, resp.TxValidationCode), transienterr.CodeMVCCConflict)
+	}
+
+	if resp.TxValidationCode != pb.TxValidationCode_VALID {
+		return errors.Errorf("transaction [%s] for anchor string [%s] was committed with validation code [%s]", txnID, anchor, resp.TxValidationCode)
 	}
 
+	logger.Debugf("[%s:%s] Anchor string [%s] was committed in transaction [%s]", c.channelID, c.namespace, anchor, txnID)
+
+	c.storeReceipt(&receipt.Receipt{
+		AnchorString:        anchor,
+		Namespace:           c.namespace,
+		OperationCount:      len(refs),
+		TxnID:               txnID,
+		ValidationCode:      resp.TxValidationCode.String(),
+		ProtocolGenesisTime: protocolGenesisTime,
+	})
+
 	return nil
 }
 
-// Read ledger transaction
+// endorseAndCommit writes the anchor to the ledger, either in its own transaction or, if an aggregator
+// was provided, along with the anchors of other namespaces.
+//
+// If a commit timeout is configured and the transaction isn't committed within the timeout then the outcome
+// of the transaction is unknown, i.e. the anchor may still be committed. In this case the pending transaction
+// is remembered and, when the batch writer retries the anchor, the outcome of the pending transaction is
+// awaited instead of submitting the anchor again.
+func (c *Client) endorseAndCommit(anchor string, txnInfoBytes []byte) (*channel.Response, bool, error) {
+	resultChan := c.pendingOrSubmit(anchor, txnInfoBytes)
+
+	if c.endorsement.CommitTimeout == 0 {
+		r := <-resultChan
+
+		return r.resp, r.committed, r.err
+	}
+
+	timer := time.NewTimer(c.endorsement.CommitTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-resultChan:
+		return r.resp, r.committed, r.err
+	case <-timer.C:
+		c.inFlightMutex.Lock()
+		c.inFlight[anchor] = resultChan
+		c.inFlightMutex.Unlock()
+
+		return nil, false, transienterr.New(
+			errors.Errorf("timed out after %s waiting for anchor [%s] to be committed - the outcome is unknown", c.endorsement.CommitTimeout, anchor),
+			transienterr.CodeBlockchain,
+		)
+	}
+}
+
+// pendingOrSubmit returns the result channel of the pending transaction for the given anchor (i.e. a transaction
+// that previously timed out) or, if there is no pending transaction, submits the anchor
+func (c *Client) pendingOrSubmit(anchor string, txnInfoBytes []byte) <-chan *commitResult {
+	c.inFlightMutex.Lock()
+	defer c.inFlightMutex.Unlock()
+
+	resultChan, ok := c.inFlight[anchor]
+	if ok {
+		logger.Infof("[%s:%s] Waiting for the outcome of the pending transaction for anchor [%s]", c.channelID, c.namespace, anchor)
+
+		delete(c.inFlight, anchor)
+
+		return resultChan
+	}
+
+	return c.submit(anchor, txnInfoBytes)
+}
+
+func (c *Client) submit(anchor string, txnInfoBytes []byte) <-chan *commitResult {
+	if c.aggregator != nil {
+		return c.aggregator.submit(anchor, txnInfoBytes, c.endorsement)
+	}
+
+	resultChan := make(chan *commitResult, 1)
+
+	go func() {
+		txnService, err := c.txnProvider.ForChannel(c.channelID)
+		if err != nil {
+			resultChan <- &commitResult{err: err}
+			return
+		}
+
+		resultChan <- endorseAndCommit(txnService, c.chaincodeName, [][]byte{[]byte(writeAnchorFcn), []byte(anchor), txnInfoBytes}, c.endorsement)
+	}()
+
+	return resultChan
+}
+
+// storeReceipt persists the given receipt. Errors are logged and not returned since the anchor has already been committed.
+func (c *Client) storeReceipt(r *receipt.Receipt) {
+	if c.receipts == nil {
+		return
+	}
+
+	r.BlockNumber = c.getBlockNumber(r.TxnID)
+
+	if err := c.receipts.Put(r); err != nil {
+		logger.Errorf("[%s:%s] Error storing receipt for anchor [%s] in transaction [%s]: %s", c.channelID, c.namespace, r.AnchorString, r.TxnID, err)
+	}
+}
+
+// getBlockNumber returns the number of the block that contains the given transaction
+// or 0 if the block could not be retrieved
+func (c *Client) getBlockNumber(txnID string) uint64 {
+	if c.ledger == nil {
+		return 0
+	}
+
+	block, err := c.ledger.GetBlockByTxID(txnID)
+	if err != nil {
+		logger.Warnf("[%s:%s] Unable to retrieve block for transaction [%s]: %s", c.channelID, c.namespace, txnID, err)
+
+		return 0
+	}
+
+	return block.Header.Number
+}
+
+// Read returns the anchor (of the client's namespace) that follows the anchor with the given transaction number.
+// The TransactionNumber of a transaction returned by Read is the sequence number of the anchor among all of the
+// anchors of the namespace on the ledger (starting at 0) and the TransactionTime is the number of the block that
+// contains the anchor. A negative transaction number reads from the start of the ledger. The sequence number is
+// resolved by scanning the ledger, so reading may resume from any transaction number (e.g. after a restart).
+//
+// The returned boolean is true if more transactions may follow the returned transaction. The AnchorWriter interface
+// doesn't allow for errors so, if an error occurs, the error is logged and false is returned along with a nil
+// transaction. ReadSince may be used in order to get the error.
 func (c *Client) Read(sinceTransactionNumber int) (bool, *txn.SidetreeTxn) {
-	// TODO: Not sure where/if this function is used
-	panic("not implemented")
+	more, sidetreeTxn, err := c.readNext(sinceTransactionNumber)
+	if err != nil {
+		logger.Errorf("[%s:%s] Error reading transactions since transaction number %d: %s", c.channelID, c.namespace, sinceTransactionNumber, err)
+
+		return false, nil
+	}
+
+	return more, sidetreeTxn
+}
+
+// ReadSince returns the anchors (of the client's namespace) in the first transaction after the given transaction
+// time (block number) and transaction number (within the block). If the transaction number is negative then the
+// anchors in the first transaction at or after the given block are returned. The returned boolean is true if
+// more transactions may follow the returned transaction.
+func (c *Client) ReadSince(sinceTransactionTime uint64, sinceTransactionNumber int) (bool, []*txn.SidetreeTxn, error) {
+	if c.ledger == nil {
+		return false, nil, errors.New("ledger not available")
+	}
+
+	bcInfo, err := c.ledger.GetBlockchainInfo()
+	if err != nil {
+		return false, nil, errors.WithMessage(err, "error getting blockchain info")
+	}
+
+	blockNum := sinceTransactionTime
+	var txnNum uint64
+
+	if sinceTransactionNumber >= 0 {
+		txnNum = uint64(sinceTransactionNumber) + 1
+	}
+
+	if blockNum == 0 {
+		// Block 0 is the genesis (config) block so start at block 1
+		blockNum = 1
+		txnNum = 0
+	}
+
+	logger.Debugf("[%s:%s] Reading the first transaction starting at block:txNum [%d:%d]", c.channelID, c.namespace, blockNum, txnNum)
+
+	for ; blockNum < bcInfo.Height; blockNum++ {
+		anchors, err := c.getAnchors(blockNum, txnNum)
+		if err != nil {
+			return false, nil, err
+		}
+
+		// The transaction number only applies to the first block
+		txnNum = 0
+
+		if len(anchors) == 0 {
+			continue
+		}
+
+		more := blockNum+1 < bcInfo.Height
+
+		var sidetreeTxns []*txn.SidetreeTxn
+
+		for _, anchor := range anchors {
+			if anchor.TxnNum != anchors[0].TxnNum {
+				more = true
+				break
+			}
+
+			sidetreeTxns = append(sidetreeTxns, newSidetreeTxn(anchor, anchor.TxnNum))
+		}
+
+		return more, sidetreeTxns, nil
+	}
+
+	return false, nil, nil
+}
+
+// readNext returns the anchor whose sequence number follows the given sequence number. If the given sequence number
+// is that of the anchor that was last returned then the scan resumes from the cursor, otherwise the ledger is scanned
+// from the start.
+func (c *Client) readNext(sinceSeq int) (bool, *txn.SidetreeTxn, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ledger == nil {
+		return false, nil, errors.New("ledger not available")
+	}
+
+	bcInfo, err := c.ledger.GetBlockchainInfo()
+	if err != nil {
+		return false, nil, errors.WithMessage(err, "error getting blockchain info")
+	}
+
+	var seq uint64
+	if sinceSeq >= 0 {
+		seq = uint64(sinceSeq) + 1
+	}
+
+	// Block 0 is the genesis (config) block so start at block 1
+	blockNum := uint64(1)
+	blockSeq := uint64(0)
+
+	if cur := c.cursor; cur != nil && sinceSeq >= 0 && cur.seq == uint64(sinceSeq) {
+		if cur.index+1 < len(cur.anchors) {
+			cur.index++
+			cur.seq++
+
+			return cur.index+1 < len(cur.anchors) || cur.blockNum+1 < bcInfo.Height, newSidetreeTxn(cur.current(), cur.seq), nil
+		}
+
+		blockNum = cur.blockNum + 1
+		blockSeq = cur.nextSeq()
+	}
+
+	logger.Debugf("[%s:%s] Reading anchor with sequence number %d starting at block %d", c.channelID, c.namespace, seq, blockNum)
+
+	for ; blockNum < bcInfo.Height; blockNum++ {
+		anchors, err := c.getAnchors(blockNum, 0)
+		if err != nil {
+			return false, nil, err
+		}
+
+		if seq >= blockSeq+uint64(len(anchors)) {
+			blockSeq += uint64(len(anchors))
+
+			continue
+		}
+
+		c.cursor = &cursor{
+			blockNum: blockNum,
+			anchors:  anchors,
+			index:    int(seq - blockSeq),
+			seq:      seq,
+		}
+
+		return c.cursor.index+1 < len(anchors) || blockNum+1 < bcInfo.Height, newSidetreeTxn(c.cursor.current(), seq), nil
+	}
+
+	return false, nil, nil
+}
+
+func (c *Client) getAnchors(blockNum, sinceBlockTxnNum uint64) ([]*anchorscanner.Anchor, error) {
+	block, err := c.ledger.GetBlockByNumber(blockNum)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error getting block %d", blockNum)
+	}
+
+	anchors, _, err := anchorscanner.New(c.channelID, block, sinceBlockTxnNum, math.MaxInt32, c.isNamespace).Scan()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error scanning block %d", blockNum)
+	}
+
+	return anchors, nil
+}
+
+func (c *Client) isNamespace(txnInfo *common.TxnInfo) bool {
+	return txnInfo.Namespace == c.namespace
+}
+
+func newSidetreeTxn(anchor *anchorscanner.Anchor, txnNum uint64) *txn.SidetreeTxn {
+	return &txn.SidetreeTxn{
+		TransactionTime:     anchor.BlockNum,
+		TransactionNumber:   txnNum,
+		AnchorString:        anchor.TxnInfo.AnchorString,
+		Namespace:           anchor.TxnInfo.Namespace,
+		ProtocolGenesisTime: anchor.TxnInfo.ProtocolGenesisTime,
+	}
 }
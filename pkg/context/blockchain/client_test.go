@@ -7,22 +7,38 @@ SPDX-License-Identifier: Apache-2.0
 package blockchain
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/mocks"
+	txnapi "github.com/trustbloc/fabric-peer-ext/pkg/txn/api"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
 	stmocks "github.com/trustbloc/sidetree-fabric/pkg/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
 )
 
 const (
 	chID      = "mychannel"
 	ccName    = "cc1"
 	namespace = "did:sidetree"
+	txnID     = "txn1"
 )
 
 func TestNew(t *testing.T) {
 	txnProvider := &stmocks.TxnServiceProvider{}
-	c := New(chID, ccName, namespace, txnProvider)
+	c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
 	require.NotNil(t, c)
 }
 
@@ -32,7 +48,7 @@ func TestGetClientError(t *testing.T) {
 	txnProvider := &stmocks.TxnServiceProvider{}
 	txnProvider.ForChannelReturns(nil, testErr)
 
-	c := New(chID, ccName, namespace, txnProvider)
+	c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
 	require.NotNil(t, c)
 
 	err := c.WriteAnchor("anchor", nil, 100)
@@ -41,35 +57,349 @@ func TestGetClientError(t *testing.T) {
 
 func TestWriteAnchor(t *testing.T) {
 	txnService := &stmocks.TxnService{}
+	txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil)
+
 	txnProvider := &stmocks.TxnServiceProvider{}
 	txnProvider.ForChannelReturns(txnService, nil)
 
-	c := New(chID, ccName, namespace, txnProvider)
+	t.Run("No receipt store", func(t *testing.T) {
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
 
-	err := c.WriteAnchor("anchor", nil, 100)
-	require.Nil(t, err)
+		require.NoError(t, c.WriteAnchor("anchor", nil, 100))
+	})
+
+	t.Run("With receipt store", func(t *testing.T) {
+		olp := &obmocks.OffLedgerClientProvider{}
+		olp.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		receipts := receipt.NewStore(chID, "doccc", "receipts", olp)
+
+		blocks := &obmocks.BlockchainClient{}
+		blocks.GetBlockByTxIDReturns(&cb.Block{Header: &cb.BlockHeader{Number: 1001}}, nil)
+
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, blocks, receipts, nil)
+
+		refs := []*operation.Reference{{UniqueSuffix: "suffix1"}, {UniqueSuffix: "suffix2"}}
+
+		require.NoError(t, c.WriteAnchor("anchor1", refs, 100))
+
+		r, err := receipts.Get("anchor1")
+		require.NoError(t, err)
+		require.Equal(t, "anchor1", r.AnchorString)
+		require.Equal(t, namespace, r.Namespace)
+		require.Equal(t, 2, r.OperationCount)
+		require.Equal(t, txnID, r.TxnID)
+		require.Equal(t, uint64(1001), r.BlockNumber)
+		require.Equal(t, pb.TxValidationCode_VALID.String(), r.ValidationCode)
+		require.Equal(t, uint64(100), r.ProtocolGenesisTime)
+
+		blocks.GetBlockByTxIDReturns(nil, errors.New("injected block error"))
+
+		require.NoError(t, c.WriteAnchor("anchor2", refs, 100))
+
+		r, err = receipts.Get("anchor2")
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), r.BlockNumber)
+	})
+
+	t.Run("Receipt store error", func(t *testing.T) {
+		olp := &obmocks.OffLedgerClientProvider{}
+		olp.ForChannelReturns(nil, errors.New("injected provider error"))
+
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, receipt.NewStore(chID, "doccc", "receipts", olp), nil)
+
+		// The error is logged since the anchor was already committed
+		require.NoError(t, c.WriteAnchor("anchor", nil, 100))
+	})
 }
 
 func TestWriteAnchorError(t *testing.T) {
+	t.Run("Endorse and commit error", func(t *testing.T) {
+		testErr := errors.New("channel error")
 
-	testErr := errors.New("channel error")
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(nil, false, testErr)
 
-	txnService := &stmocks.TxnService{}
-	txnService.EndorseAndCommitReturns(nil, false, testErr)
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
 
-	txnProvider := &stmocks.TxnServiceProvider{}
-	txnProvider.ForChannelReturns(txnService, nil)
-	bc := New(chID, ccName, namespace, txnProvider)
+		err := bc.WriteAnchor("anchor", nil, 100)
+		require.NotNil(t, err)
+		require.Contains(t, err.Error(), testErr.Error())
+		require.True(t, transienterr.Is(err))
+	})
+
+	t.Run("Not committed", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID}, false, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
 
-	err := bc.WriteAnchor("anchor", nil, 100)
-	require.NotNil(t, err)
-	require.Contains(t, err.Error(), testErr.Error())
+		err := bc.WriteAnchor("anchor", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "was not committed")
+		require.True(t, transienterr.Is(err))
+	})
+
+	t.Run("MVCC conflict", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT}, true, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
+
+		err := bc.WriteAnchor("anchor", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "was invalidated due to MVCC conflict")
+		require.Equal(t, transienterr.CodeMVCCConflict, transienterr.GetCode(err))
+	})
+
+	t.Run("Commit timeout", func(t *testing.T) {
+		release := make(chan struct{})
+
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitStub = func(*txnapi.Request) (*channel.Response, bool, error) {
+			<-release
+			return &channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}, true, nil
+		}
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{CommitTimeout: 10 * time.Millisecond}, txnProvider, nil, nil, nil)
+
+		err := bc.WriteAnchor("anchor", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the outcome is unknown")
+		require.True(t, transienterr.Is(err))
+
+		close(release)
+
+		// The retry waits for the pending transaction instead of writing the anchor again
+		require.NoError(t, bc.WriteAnchor("anchor", nil, 100))
+		require.Equal(t, 1, txnService.EndorseAndCommitCallCount())
+
+		require.NoError(t, bc.WriteAnchor("anchor2", nil, 100))
+		require.Equal(t, 2, txnService.EndorseAndCommitCallCount())
+	})
+
+	t.Run("Anchor already exists", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(nil, false, status.New(status.EndorserServerStatus, 409, "anchor string already exists on the ledger", nil))
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
+
+		require.NoError(t, bc.WriteAnchor("anchor", nil, 100))
+	})
+
+	t.Run("Invalid validation code", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(&channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE}, true, nil)
+
+		txnProvider := &stmocks.TxnServiceProvider{}
+		txnProvider.ForChannelReturns(txnService, nil)
+		bc := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
+
+		err := bc.WriteAnchor("anchor", nil, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "was committed with validation code [ENDORSEMENT_POLICY_FAILURE]")
+	})
 }
 
 func TestClient_Read(t *testing.T) {
-	require.PanicsWithValue(t, "not implemented", func() {
-		txnProvider := &stmocks.TxnServiceProvider{}
-		c := New(chID, ccName, namespace, txnProvider)
-		c.Read(1000)
+	const ns2 = "did:other"
+
+	txnProvider := &stmocks.TxnServiceProvider{}
+
+	// Block 1: anchor1 (ns), anchor2 (ns2)
+	// Block 2: anchor3 (ns) and anchor4 (ns) in the same transaction
+	// Block 3: anchor5 (ns)
+	bb1 := mocks.NewBlockBuilder(chID, 1)
+	bb1.Transaction("tx1", pb.TxValidationCode_VALID).ChaincodeAction(ccName).Write(common.AnchorPrefix+"anchor1", getTxnInfo("anchor1", namespace))
+	bb1.Transaction("tx2", pb.TxValidationCode_VALID).ChaincodeAction(ccName).Write(common.AnchorPrefix+"anchor2", getTxnInfo("anchor2", ns2))
+
+	bb2 := mocks.NewBlockBuilder(chID, 2)
+	tx3 := bb2.Transaction("tx3", pb.TxValidationCode_VALID).ChaincodeAction(ccName)
+	tx3.Write(common.AnchorPrefix+"anchor3", getTxnInfo("anchor3", namespace))
+	tx3.Write(common.AnchorPrefix+"anchor4", getTxnInfo("anchor4", namespace))
+
+	bb3 := mocks.NewBlockBuilder(chID, 3)
+	bb3.Transaction("tx5", pb.TxValidationCode_VALID).ChaincodeAction(ccName).Write(common.AnchorPrefix+"anchor5", getTxnInfo("anchor5", namespace))
+
+	blocks := []*cb.Block{mocks.NewBlockBuilder(chID, 0).Build(), bb1.Build(), bb2.Build(), bb3.Build()}
+
+	newLedger := func() *obmocks.BlockchainClient {
+		ledger := &obmocks.BlockchainClient{}
+		ledger.GetBlockchainInfoReturns(&cb.BlockchainInfo{Height: uint64(len(blocks))}, nil)
+		ledger.GetBlockByNumberStub = func(blockNum uint64) (*cb.Block, error) {
+			return blocks[blockNum], nil
+		}
+
+		return ledger
+	}
+
+	t.Run("Read all", func(t *testing.T) {
+		ledger := newLedger()
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, ledger, nil, nil)
+
+		var anchors []string
+		var times []uint64
+		var numbers []uint64
+
+		since := -1
+		for {
+			more, sidetreeTxn := c.Read(since)
+			require.NotNil(t, sidetreeTxn)
+			require.Equal(t, namespace, sidetreeTxn.Namespace)
+
+			anchors = append(anchors, sidetreeTxn.AnchorString)
+			times = append(times, sidetreeTxn.TransactionTime)
+			numbers = append(numbers, sidetreeTxn.TransactionNumber)
+
+			if !more {
+				break
+			}
+
+			since = int(sidetreeTxn.TransactionNumber)
+		}
+
+		require.Equal(t, []string{"anchor1", "anchor3", "anchor4", "anchor5"}, anchors)
+		require.Equal(t, []uint64{1, 2, 2, 3}, times)
+		require.Equal(t, []uint64{0, 1, 2, 3}, numbers)
+
+		more, sidetreeTxn := c.Read(3)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+
+		// Each read resumes from the block of the previously read anchor
+		require.Equal(t, 3, ledger.GetBlockByNumberCallCount())
+	})
+
+	t.Run("Read from any transaction number", func(t *testing.T) {
+		// A new client (e.g. after a restart) resolves the transaction number by scanning the ledger
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, newLedger(), nil, nil)
+
+		more, sidetreeTxn := c.Read(1)
+		require.True(t, more)
+		require.NotNil(t, sidetreeTxn)
+		require.Equal(t, "anchor4", sidetreeTxn.AnchorString)
+		require.Equal(t, uint64(2), sidetreeTxn.TransactionNumber)
+		require.Equal(t, uint64(2), sidetreeTxn.TransactionTime)
+
+		// Reading out of sequence doesn't use the cursor
+		more, sidetreeTxn = c.Read(0)
+		require.True(t, more)
+		require.NotNil(t, sidetreeTxn)
+		require.Equal(t, "anchor3", sidetreeTxn.AnchorString)
+
+		more, sidetreeTxn = c.Read(2)
+		require.False(t, more)
+		require.NotNil(t, sidetreeTxn)
+		require.Equal(t, "anchor5", sidetreeTxn.AnchorString)
+		require.Equal(t, uint64(3), sidetreeTxn.TransactionNumber)
+	})
+
+	t.Run("Read since", func(t *testing.T) {
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, newLedger(), nil, nil)
+
+		more, sidetreeTxns, err := c.ReadSince(0, -1)
+		require.NoError(t, err)
+		require.True(t, more)
+		require.Len(t, sidetreeTxns, 1)
+		require.Equal(t, "anchor1", sidetreeTxns[0].AnchorString)
+		require.Equal(t, uint64(1), sidetreeTxns[0].TransactionTime)
+		require.Equal(t, uint64(0), sidetreeTxns[0].TransactionNumber)
+
+		more, sidetreeTxns, err = c.ReadSince(1, 0)
+		require.NoError(t, err)
+		require.True(t, more)
+		require.Len(t, sidetreeTxns, 2)
+		require.Equal(t, "anchor3", sidetreeTxns[0].AnchorString)
+		require.Equal(t, "anchor4", sidetreeTxns[1].AnchorString)
+
+		_, sidetreeTxns, err = c.ReadSince(2, -1)
+		require.NoError(t, err)
+		require.Len(t, sidetreeTxns, 2)
+
+		more, sidetreeTxns, err = c.ReadSince(2, 0)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Len(t, sidetreeTxns, 1)
+		require.Equal(t, "anchor5", sidetreeTxns[0].AnchorString)
+
+		more, sidetreeTxns, err = c.ReadSince(3, 0)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Empty(t, sidetreeTxns)
+	})
+
+	t.Run("Transaction number past the end", func(t *testing.T) {
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, newLedger(), nil, nil)
+
+		more, sidetreeTxn := c.Read(5)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+
+		_, sidetreeTxn = c.Read(-1)
+		require.NotNil(t, sidetreeTxn)
+
+		more, sidetreeTxn = c.Read(3)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+	})
+
+	t.Run("No ledger", func(t *testing.T) {
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, nil, nil, nil)
+
+		more, sidetreeTxn := c.Read(-1)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+
+		_, _, err := c.ReadSince(0, -1)
+		require.EqualError(t, err, "ledger not available")
+	})
+
+	t.Run("Blockchain info error", func(t *testing.T) {
+		errExpected := errors.New("injected info error")
+
+		ledger := newLedger()
+		ledger.GetBlockchainInfoReturns(nil, errExpected)
+
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, ledger, nil, nil)
+
+		more, sidetreeTxn := c.Read(-1)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+
+		_, _, err := c.ReadSince(0, -1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
 	})
+
+	t.Run("Get block error", func(t *testing.T) {
+		errExpected := errors.New("injected block error")
+
+		ledger := newLedger()
+		ledger.GetBlockByNumberStub = nil
+		ledger.GetBlockByNumberReturns(nil, errExpected)
+
+		c := New(chID, ccName, namespace, config.AnchorEndorsement{}, txnProvider, ledger, nil, nil)
+
+		more, sidetreeTxn := c.Read(-1)
+		require.False(t, more)
+		require.Nil(t, sidetreeTxn)
+
+		_, _, err := c.ReadSince(0, -1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func getTxnInfo(anchor, ns string) []byte {
+	return []byte(fmt.Sprintf(`{"anchorString":"%s","namespace":"%s"}`, anchor, ns))
 }
@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchain
+
+import (
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/pkg/errors"
+	txnapi "github.com/trustbloc/fabric-peer-ext/pkg/txn/api"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+)
+
+const (
+	// chaincodeErrorThreshold is the status code at or above which a chaincode response is considered to be an error
+	chaincodeErrorThreshold = 400
+
+	// anchorExistsStatus is the status returned by the chaincode if the anchor already exists on the ledger
+	anchorExistsStatus = 409
+)
+
+// errAnchorExists indicates that the anchor was already written to the ledger, e.g. by a previous attempt
+// whose outcome was unknown
+var errAnchorExists = errors.New("anchor already exists on the ledger")
+
+type commitResult struct {
+	resp      *channel.Response
+	committed bool
+	err       error
+	// rejected is true if the chaincode rejected the transaction
+	rejected bool
+}
+
+// endorseAndCommit collects endorsements for the given chaincode invocation according to the given endorsement
+// options and commits the transaction. Errors that may succeed on a retry are returned as transient errors.
+func endorseAndCommit(txnService txnapi.Service, chaincodeName string, args [][]byte, cfg config.AnchorEndorsement) *commitResult {
+	req, err := newTxnRequest(txnService, chaincodeName, args, cfg)
+	if err != nil {
+		return &commitResult{err: err}
+	}
+
+	return doEndorseAndCommit(txnService, req, cfg.MinEndorsements)
+}
+
+func doEndorseAndCommit(txnService txnapi.Service, req *txnapi.Request, minEndorsements int) *commitResult {
+	if minEndorsements == 0 {
+		resp, committed, err := txnService.EndorseAndCommit(req)
+		if err != nil {
+			return newErrorResult(err)
+		}
+
+		return &commitResult{resp: resp, committed: committed}
+	}
+
+	endorsementResp, err := txnService.Endorse(req)
+	if err != nil {
+		return newErrorResult(err)
+	}
+
+	if len(endorsementResp.Responses) < minEndorsements {
+		return &commitResult{
+			err: transienterr.New(
+				errors.Errorf("insufficient endorsements for anchor - got %d but at least %d are required", len(endorsementResp.Responses), minEndorsements),
+				transienterr.CodeBlockchain,
+			),
+		}
+	}
+
+	resp, committed, err := txnService.CommitEndorsements(&txnapi.CommitRequest{EndorsementResponse: endorsementResp})
+	if err != nil {
+		return newErrorResult(err)
+	}
+
+	return &commitResult{resp: resp, committed: committed}
+}
+
+func newTxnRequest(txnService txnapi.Service, chaincodeName string, args [][]byte, cfg config.AnchorEndorsement) (*txnapi.Request, error) {
+	req := &txnapi.Request{
+		ChaincodeID: chaincodeName,
+		Args:        args,
+	}
+
+	for _, endpoint := range cfg.Peers {
+		peer, err := txnService.GetPeer(endpoint)
+		if err != nil {
+			return nil, transienterr.New(errors.WithMessagef(err, "unable to get endorsement target [%s]", endpoint), transienterr.CodeBlockchain)
+		}
+
+		req.Targets = append(req.Targets, peer)
+	}
+
+	if len(cfg.Orgs) > 0 {
+		req.PeerFilter = newOrgFilter(cfg.Orgs)
+	}
+
+	return req, nil
+}
+
+func newErrorResult(err error) *commitResult {
+	if isAnchorExists(err) {
+		return &commitResult{
+			err:      errors.WithMessage(errAnchorExists, err.Error()),
+			rejected: true,
+		}
+	}
+
+	if isRejectedByChaincode(err) {
+		// The chaincode rejected the anchor (e.g. the client isn't authorized or the anchor is invalid)
+		// so a retry would also fail
+		return &commitResult{
+			err:      errors.WithMessage(err, "anchor string rejected by chaincode"),
+			rejected: true,
+		}
+	}
+
+	return &commitResult{err: classifyError(err)}
+}
+
+// isRejectedByChaincode returns true if the given error is an error response from the chaincode
+func isRejectedByChaincode(err error) bool {
+	s, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return false
+	}
+
+	return s.Group == status.ChaincodeStatus || (s.Group == status.EndorserServerStatus && s.Code >= chaincodeErrorThreshold)
+}
+
+// isAnchorExists returns true if the chaincode rejected the transaction since the anchor already exists on the ledger
+func isAnchorExists(err error) bool {
+	s, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return false
+	}
+
+	return s.Group == status.EndorserServerStatus && s.Code == anchorExistsStatus
+}
+
+// classifyError wraps the given error in a transient error with a code that indicates the cause
+func classifyError(err error) error {
+	if s, ok := status.FromError(errors.Cause(err)); ok {
+		if s.Group == status.EndorserClientStatus && s.Code == status.EndorsementMismatch.ToInt32() {
+			return transienterr.New(errors.Wrap(err, "endorsement mismatch while storing anchor string"), transienterr.CodeEndorsementMismatch)
+		}
+
+		if s.Group == status.EventServerStatus && isMVCCConflict(pb.TxValidationCode(s.Code)) {
+			return transienterr.New(errors.Wrap(err, "MVCC conflict while storing anchor string"), transienterr.CodeMVCCConflict)
+		}
+	}
+
+	return transienterr.New(errors.Wrap(err, "failed to store anchor string"), transienterr.CodeBlockchain)
+}
+
+func isMVCCConflict(code pb.TxValidationCode) bool {
+	return code == pb.TxValidationCode_MVCC_READ_CONFLICT || code == pb.TxValidationCode_PHANTOM_READ_CONFLICT
+}
+
+// orgFilter accepts only the peers that belong to one of the given orgs
+type orgFilter struct {
+	mspIDs map[string]struct{}
+}
+
+func newOrgFilter(mspIDs []string) *orgFilter {
+	f := &orgFilter{mspIDs: make(map[string]struct{})}
+
+	for _, mspID := range mspIDs {
+		f.mspIDs[mspID] = struct{}{}
+	}
+
+	return f
+}
+
+// Accept returns true if the given peer belongs to one of the orgs
+func (f *orgFilter) Accept(peer txnapi.Peer) bool {
+	_, ok := f.mspIDs[peer.MSPID()]
+
+	return ok
+}
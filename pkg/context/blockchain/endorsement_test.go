@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchain
+
+import (
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	txnapi "github.com/trustbloc/fabric-peer-ext/pkg/txn/api"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	stmocks "github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestEndorseAndCommit(t *testing.T) {
+	args := [][]byte{[]byte(writeAnchorFcn), []byte("anchor"), []byte("{}")}
+	validResp := &channel.Response{TransactionID: txnID, TxValidationCode: pb.TxValidationCode_VALID}
+
+	t.Run("Default options", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(validResp, true, nil)
+
+		resp, committed, err := endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{})
+		require.NoError(t, err)
+		require.True(t, committed)
+		require.Equal(t, validResp, resp)
+
+		req := txnService.EndorseAndCommitArgsForCall(0)
+		require.Equal(t, ccName, req.ChaincodeID)
+		require.Empty(t, req.Targets)
+		require.Nil(t, req.PeerFilter)
+	})
+
+	t.Run("Targets and orgs", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseAndCommitReturns(validResp, true, nil)
+		txnService.GetPeerReturns(fabmocks.NewMockPeer("peer1", "peer1.org1.com:7051"), nil)
+
+		cfg := config.AnchorEndorsement{
+			Orgs:  []string{"Org1MSP"},
+			Peers: []string{"peer1.org1.com:7051"},
+		}
+
+		_, _, err := endorseAndCommitResult(txnService, ccName, args, cfg)
+		require.NoError(t, err)
+
+		req := txnService.EndorseAndCommitArgsForCall(0)
+		require.Len(t, req.Targets, 1)
+		require.NotNil(t, req.PeerFilter)
+		require.True(t, req.PeerFilter.Accept(&mockPeer{mspID: "Org1MSP"}))
+		require.False(t, req.PeerFilter.Accept(&mockPeer{mspID: "Org2MSP"}))
+	})
+
+	t.Run("Get peer error", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.GetPeerReturns(nil, errors.New("injected peer error"))
+
+		_, _, err := endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{Peers: []string{"peer1.org1.com:7051"}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to get endorsement target")
+		require.True(t, transienterr.Is(err))
+	})
+
+	t.Run("Minimum endorsements", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseReturns(&channel.Response{Responses: []*fab.TransactionProposalResponse{{}, {}}}, nil)
+		txnService.CommitEndorsementsReturns(validResp, true, nil)
+
+		resp, committed, err := endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{MinEndorsements: 2})
+		require.NoError(t, err)
+		require.True(t, committed)
+		require.Equal(t, validResp, resp)
+		require.Equal(t, 0, txnService.EndorseAndCommitCallCount())
+		require.Equal(t, 1, txnService.CommitEndorsementsCallCount())
+
+		_, _, err = endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{MinEndorsements: 3})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "insufficient endorsements for anchor - got 2 but at least 3 are required")
+		require.True(t, transienterr.Is(err))
+		require.Equal(t, 1, txnService.CommitEndorsementsCallCount())
+	})
+
+	t.Run("Minimum endorsements - errors", func(t *testing.T) {
+		txnService := &stmocks.TxnService{}
+		txnService.EndorseReturns(nil, errors.New("injected endorse error"))
+
+		_, _, err := endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{MinEndorsements: 1})
+		require.Error(t, err)
+		require.Equal(t, transienterr.CodeBlockchain, transienterr.GetCode(err))
+
+		txnService.EndorseReturns(&channel.Response{Responses: []*fab.TransactionProposalResponse{{}}}, nil)
+		txnService.CommitEndorsementsReturns(nil, false, errors.New("injected commit error"))
+
+		_, _, err = endorseAndCommitResult(txnService, ccName, args, config.AnchorEndorsement{MinEndorsements: 1})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected commit error")
+		require.Equal(t, transienterr.CodeBlockchain, transienterr.GetCode(err))
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Run("Endorsement mismatch", func(t *testing.T) {
+		err := classifyError(errors.WithStack(status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "ProposalResponsePayloads do not match", nil)))
+		require.Equal(t, transienterr.CodeEndorsementMismatch, transienterr.GetCode(err))
+
+		err = classifyError(errors.New("one or more proposal responses do not match"))
+		require.Equal(t, transienterr.CodeBlockchain, transienterr.GetCode(err))
+	})
+
+	t.Run("MVCC conflict", func(t *testing.T) {
+		err := classifyError(status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil))
+		require.Equal(t, transienterr.CodeMVCCConflict, transienterr.GetCode(err))
+
+		err = classifyError(status.New(status.EventServerStatus, int32(pb.TxValidationCode_PHANTOM_READ_CONFLICT), "received invalid transaction", nil))
+		require.Equal(t, transienterr.CodeMVCCConflict, transienterr.GetCode(err))
+	})
+
+	t.Run("Other", func(t *testing.T) {
+		err := classifyError(status.New(status.EventServerStatus, int32(pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE), "received invalid transaction", nil))
+		require.Equal(t, transienterr.CodeBlockchain, transienterr.GetCode(err))
+
+		err = classifyError(errors.New("some error"))
+		require.Equal(t, transienterr.CodeBlockchain, transienterr.GetCode(err))
+	})
+}
+
+type mockPeer struct {
+	mspID    string
+	endpoint string
+}
+
+func (p *mockPeer) MSPID() string {
+	return p.mspID
+}
+
+func (p *mockPeer) Endpoint() string {
+	return p.endpoint
+}
+
+func endorseAndCommitResult(txnService txnapi.Service, chaincodeName string, args [][]byte, cfg config.AnchorEndorsement) (*channel.Response, bool, error) {
+	r := endorseAndCommit(txnService, chaincodeName, args, cfg)
+
+	return r.resp, r.committed, r.err
+}
+
+func TestIsRejectedByChaincode(t *testing.T) {
+	require.True(t, isRejectedByChaincode(errors.WithStack(status.New(status.EndorserServerStatus, 500, "invalid anchor", nil))))
+	require.True(t, isRejectedByChaincode(status.New(status.ChaincodeStatus, 403, "unauthorized", nil)))
+	require.False(t, isRejectedByChaincode(status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "mismatch", nil)))
+	require.False(t, isRejectedByChaincode(errors.New("some error")))
+}
+
+func TestNewErrorResult(t *testing.T) {
+	r := newErrorResult(errors.WithStack(status.New(status.EndorserServerStatus, 409, "anchor string already exists on the ledger", nil)))
+	require.True(t, r.rejected)
+	require.Equal(t, errAnchorExists, errors.Cause(r.err))
+
+	r = newErrorResult(status.New(status.EndorserServerStatus, 500, "invalid anchor", nil))
+	require.True(t, r.rejected)
+	require.NotEqual(t, errAnchorExists, errors.Cause(r.err))
+	require.False(t, transienterr.Is(r.err))
+
+	r = newErrorResult(errors.WithStack(status.New(status.EndorserServerStatus, 403, "client is not authorized to write anchors", nil)))
+	require.True(t, r.rejected)
+	require.False(t, transienterr.Is(r.err))
+
+	r = newErrorResult(status.New(status.ChaincodeStatus, 400, "unsupported protocol version", nil))
+	require.True(t, r.rejected)
+	require.False(t, transienterr.Is(r.err))
+	require.Contains(t, r.err.Error(), "unsupported protocol version")
+
+	r = newErrorResult(status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "received invalid transaction", nil))
+	require.False(t, r.rejected)
+	require.True(t, transienterr.Is(r.err))
+}
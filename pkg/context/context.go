@@ -20,6 +20,7 @@ import (
 	"github.com/trustbloc/sidetree-fabric/pkg/config"
 	"github.com/trustbloc/sidetree-fabric/pkg/context/blockchain"
 	"github.com/trustbloc/sidetree-fabric/pkg/context/protocol"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
 	"github.com/trustbloc/sidetree-fabric/pkg/rest/sidetreehandler"
 )
 
@@ -57,6 +58,10 @@ type cachingOpProcessorProvider interface {
 	CreateCachingOperationProcessor(channelID string, cfg sidetreehandler.Config, resolver dochandler.OperationProcessor) dochandler.OperationProcessor
 }
 
+type receiptStore interface {
+	Put(r *receipt.Receipt) error
+}
+
 // Providers contains the providers required by the SidetreeContext
 type Providers struct {
 	TxnProvider                txnServiceProvider
@@ -67,28 +72,42 @@ type Providers struct {
 	OperationProcessorProvider cachingOpProcessorProvider
 }
 
-// New creates new Sidetree context
+// New creates new Sidetree context. The anchor aggregator is optional. If it is nil then the anchors
+// for the namespace are written in their own transactions.
 func New(
 	channelID, namespace string,
 	dcasCfg config.DCAS,
+	sidetreeCfg config.Sidetree,
 	casClient casApi.Client,
 	protocolVersions []protocolApi.Version,
+	aggregator *blockchain.Aggregator,
 	providers *Providers) (*SidetreeContext, error) {
 	opQueue, err := providers.OperationQueueProvider.Create(channelID, namespace)
 	if err != nil {
 		return nil, err
 	}
 
+	peerLedger := providers.LedgerProvider.GetLedger(channelID)
+
 	return &SidetreeContext{
 		channelID:      channelID,
 		namespace:      namespace,
-		protocolClient: protocol.New(protocolVersions, providers.LedgerProvider.GetLedger(channelID)),
+		protocolClient: protocol.New(protocolVersions, peerLedger),
 		casClient:      casClient,
-		anchorWriter:   blockchain.New(channelID, dcasCfg.ChaincodeName, namespace, providers.TxnProvider),
+		anchorWriter:   blockchain.New(channelID, dcasCfg.ChaincodeName, namespace, sidetreeCfg.AnchorEndorsement, providers.TxnProvider, peerLedger, newReceiptStore(channelID, sidetreeCfg, providers.OffLedgerProvider), aggregator),
 		opQueue:        opQueue,
 	}, nil
 }
 
+// newReceiptStore returns an anchor receipt store if a receipt collection is configured for the namespace, otherwise nil is returned
+func newReceiptStore(channelID string, sidetreeCfg config.Sidetree, offLedgerProvider offLedgerClientProvider) receiptStore {
+	if sidetreeCfg.AnchorReceiptCollection == "" {
+		return nil
+	}
+
+	return receipt.NewStore(channelID, sidetreeCfg.ChaincodeName, sidetreeCfg.AnchorReceiptCollection, offLedgerProvider)
+}
+
 // Namespace returns the namespace
 func (m *SidetreeContext) Namespace() string {
 	return m.namespace
@@ -55,15 +55,21 @@ func TestNew(t *testing.T) {
 		OperationProcessorProvider: cacheUpdater,
 	}
 
+	sidetreeCfg := config.Sidetree{
+		ChaincodeName:           ccName,
+		Collection:              coll,
+		AnchorReceiptCollection: "receipts",
+	}
+
 	casClient := &mocks.CasClient{}
 
-	sctx, err := New(channelID, namespace, dcasCfg, casClient, nil, p)
+	sctx, err := New(channelID, namespace, dcasCfg, sidetreeCfg, casClient, nil, nil, p)
 	require.EqualError(t, err, errExpected.Error())
 	require.Nil(t, sctx)
 
 	opQueueProvider.CreateReturns(&opqueue.MemQueue{}, nil)
 
-	sctx, err = New(channelID, namespace, dcasCfg, casClient, nil, p)
+	sctx, err = New(channelID, namespace, dcasCfg, sidetreeCfg, casClient, nil, nil, p)
 	require.NoError(t, err)
 	require.NotNil(t, sctx)
 
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package receipt
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/context/common"
+)
+
+var logger = flogging.MustGetLogger("sidetree_context")
+
+// ErrNotFound indicates that the receipt for a given anchor was not found
+var ErrNotFound = errors.New("receipt not found")
+
+// Receipt contains the ledger details of a committed anchor
+type Receipt struct {
+	AnchorString   string `json:"anchorString"`
+	Namespace      string `json:"namespace"`
+	OperationCount int    `json:"operationCount"`
+	TxnID          string `json:"transactionId"`
+	// BlockNumber is the number of the block in which the anchor was committed. A value of 0 indicates
+	// that the block number was not known at the time the receipt was stored.
+	BlockNumber uint64 `json:"blockNumber"`
+	// ValidationCode is the validation code of the committed transaction (e.g. VALID)
+	ValidationCode string `json:"validationCode"`
+	// ProtocolGenesisTime is the genesis time of the protocol that was used to create the anchor
+	ProtocolGenesisTime uint64 `json:"protocolGenesisTime"`
+}
+
+// Store persists anchor receipts (keyed by anchor string) to an off-ledger collection
+type Store struct {
+	channelID         string
+	chaincodeName     string
+	collection        string
+	offLedgerProvider common.OffLedgerClientProvider
+}
+
+// NewStore returns a new anchor receipt store
+func NewStore(channelID, chaincodeName, collection string, offLedgerProvider common.OffLedgerClientProvider) *Store {
+	return &Store{
+		channelID:         channelID,
+		chaincodeName:     chaincodeName,
+		collection:        collection,
+		offLedgerProvider: offLedgerProvider,
+	}
+}
+
+// Put persists the given receipt
+func (s *Store) Put(r *Receipt) error {
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return errors.WithMessage(err, "error marshalling anchor receipt")
+	}
+
+	client, err := s.offLedgerProvider.ForChannel(s.channelID)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("[%s] Storing receipt for anchor [%s]: %s", s.channelID, r.AnchorString, bytes)
+
+	return client.Put(s.chaincodeName, s.collection, r.AnchorString, bytes)
+}
+
+// Get returns the receipt for the given anchor string. ErrNotFound is returned if no receipt exists for the anchor.
+func (s *Store) Get(anchorString string) (*Receipt, error) {
+	client, err := s.offLedgerProvider.ForChannel(s.channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := client.Get(s.chaincodeName, s.collection, anchorString)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error retrieving anchor receipt")
+	}
+
+	if len(data) == 0 {
+		logger.Debugf("[%s] No receipt exists for anchor [%s]", s.channelID, anchorString)
+
+		return nil, ErrNotFound
+	}
+
+	r := &Receipt{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, errors.WithMessage(err, "error unmarshalling anchor receipt")
+	}
+
+	return r, nil
+}
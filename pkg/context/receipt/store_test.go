@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package receipt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+)
+
+const (
+	channel1 = "channel1"
+	cc1      = "cc1"
+	coll1    = "coll1"
+	anchor1  = "1.anchor1"
+)
+
+func TestStore(t *testing.T) {
+	olp := &obmocks.OffLedgerClientProvider{}
+
+	t.Run("Provider error", func(t *testing.T) {
+		s := NewStore(channel1, cc1, coll1, olp)
+		require.NotNil(t, s)
+
+		errProvider := errors.New("injected provider error")
+		olp.ForChannelReturns(nil, errProvider)
+
+		r, err := s.Get(anchor1)
+		require.EqualError(t, err, errProvider.Error())
+		require.Nil(t, r)
+
+		require.EqualError(t, s.Put(&Receipt{AnchorString: anchor1}), errProvider.Error())
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		s := NewStore(channel1, cc1, coll1, olp)
+
+		ols := obmocks.NewMockOffLedgerClient()
+		olp.ForChannelReturns(ols, nil)
+
+		ols.GetErr = errors.New("injected Get error")
+		ols.PutErr = errors.New("injected Put error")
+
+		r, err := s.Get(anchor1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), ols.GetErr.Error())
+		require.Nil(t, r)
+
+		err = s.Put(&Receipt{AnchorString: anchor1})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), ols.PutErr.Error())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		s := NewStore(channel1, cc1, coll1, olp)
+
+		olp.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		r, err := s.Get(anchor1)
+		require.Equal(t, ErrNotFound, err)
+		require.Nil(t, r)
+	})
+
+	t.Run("Put and get -> success", func(t *testing.T) {
+		s := NewStore(channel1, cc1, coll1, olp)
+
+		olp.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		r := &Receipt{
+			AnchorString:   anchor1,
+			Namespace:      "did:sidetree",
+			OperationCount: 2,
+			TxnID:          "txn1",
+			BlockNumber:    1001,
+			ValidationCode: "VALID",
+		}
+
+		require.NoError(t, s.Put(r))
+
+		r2, err := s.Get(anchor1)
+		require.NoError(t, err)
+		require.Equal(t, r, r2)
+	})
+}
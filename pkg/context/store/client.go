@@ -8,7 +8,6 @@ package store
 
 import (
 	"encoding/json"
-	"fmt"
 	"sort"
 
 	"github.com/pkg/errors"
@@ -19,13 +18,10 @@ import (
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-fabric/pkg/common/docquery"
 	"github.com/trustbloc/sidetree-fabric/pkg/common/transienterr"
 )
 
-const (
-	queryByUniqueSuffixTemplate = `{"selector":{"uniqueSuffix":"%s"},"use_index":["_design/indexUniqueSuffixDoc","indexUniqueSuffix"],"fields":["uniqueSuffix","type","operationBuffer","transactionTime","transactionNumber","protocolGenesisTime"]}`
-)
-
 var logger = flogging.MustGetLogger("sidetree_context")
 
 type store interface {
@@ -53,7 +49,7 @@ func NewClient(channelID, namespace string, s store) *Client {
 func (c *Client) Get(uniqueSuffix string) ([]*operation.AnchoredOperation, error) {
 	logger.Debugf("[%s-%s] Querying for operations for ID [%s]", c.channelID, c.namespace, uniqueSuffix)
 
-	iter, err := c.store.Query(fmt.Sprintf(queryByUniqueSuffixTemplate, uniqueSuffix))
+	iter, err := c.store.Query(docquery.ByUniqueSuffix(uniqueSuffix))
 	if err != nil {
 		return nil, transienterr.New(errors.Wrap(err, "failed to query document operations"), transienterr.CodeDB)
 	}
@@ -0,0 +1,287 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasgc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+var logger = flogging.MustGetLogger("sidetree_dcasgc")
+
+type blockchainLedger interface {
+	GetBlockchainInfo() (*cb.BlockchainInfo, error)
+	GetBlockByNumber(blockNumber uint64) (*cb.Block, error)
+}
+
+type dcasClientProvider interface {
+	GetDCASClient(channelID string, namespace string, coll string) (dcasclient.DCAS, error)
+}
+
+type protocolClientProvider interface {
+	ForNamespace(namespace string) (protocol.Client, error)
+}
+
+type fileIndexProvider interface {
+	GetFileIndexes(chaincodeName, collection string) ([]FileIndex, error)
+}
+
+// DocumentVersionResolver resolves every version of a Sidetree document
+type DocumentVersionResolver interface {
+	ResolveDocumentVersions(id string) ([]*document.ResolutionResult, error)
+}
+
+// FileIndex identifies a file index document. The files that are mapped in any version of the document
+// are referenced since a file may be retrieved from a previous version of the index.
+type FileIndex struct {
+	// DocID is the ID of the file index document
+	DocID string
+	// Resolver resolves the versions of the file index document
+	Resolver DocumentVersionResolver
+}
+
+// Providers contains the providers required by the garbage collector
+type Providers struct {
+	Ledger          blockchainLedger
+	DCASProvider    dcasClientProvider
+	OffLedger       common.OffLedgerClientProvider
+	ProtocolClients protocolClientProvider
+	// FileIndexProvider provides the file index documents of the file handlers that store files in the DCAS
+	// collection. The file indexes are retrieved on each run so that changes to the file handlers are picked up.
+	FileIndexProvider fileIndexProvider
+}
+
+// Result contains the result of a garbage collection run
+type Result struct {
+	// Stored is the number of items of content in the DCAS collection
+	Stored int
+	// Referenced is the number of items of content that are referenced (including linked content)
+	Referenced int
+	// Unresolved is the number of anchors and file index documents whose referenced content couldn't be determined.
+	// If greater than 0 then nothing is swept.
+	Unresolved int
+	// Unreferenced contains the CIDs of the stored content that has been unreferenced for longer than the grace period
+	Unreferenced []string
+	// Deleted contains the CIDs of the unreferenced content that was deleted
+	Deleted []string
+}
+
+// Collector is a mark-and-sweep garbage collector for the content in a DCAS collection. The set of referenced CIDs is
+// computed from the anchors on the ledger, all versions of the file index documents and the pinned CIDs (along with all of the
+// content linked from these CIDs). Stored content that isn't referenced is listed and, if configured, deleted once it
+// has been unreferenced for longer than the grace period.
+//
+// If the index files of an anchor can't be read (for example, because they're not yet stored on this peer) then the
+// anchor is retried on each run and nothing is swept until all of the anchors (and file index documents) are resolved,
+// since the content that they reference is unknown. Only the core index file of an anchor in a namespace that isn't
+// served by this peer is marked, since the other files can't be determined without the namespace's protocol.
+//
+// Note that the unreferenced content is tracked in memory, so the grace period starts again if the peer is restarted.
+type Collector struct {
+	*Providers
+	channelID   string
+	dcasCfg     config.DCAS
+	cfg         config.DCASGarbageCollector
+	compression *compression.Registry
+
+	mutex sync.Mutex
+	// anchorRefs contains the CIDs of the files referenced by the anchors in all blocks before nextBlock. Since
+	// anchors are never removed from the ledger, only the blocks committed since the previous run are scanned.
+	anchorRefs map[string]struct{}
+	nextBlock  uint64
+	// pendingAnchors contains the anchors (keyed by anchor string) whose files couldn't be read on a previous run
+	pendingAnchors map[string]*common.TxnInfo
+	// candidates contains the time at which each item of unreferenced content was first found to be unreferenced
+	candidates map[string]time.Time
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New returns a new garbage collector for the given DCAS collection
+func New(channelID string, dcasCfg config.DCAS, cfg config.DCASGarbageCollector, providers *Providers) *Collector {
+	return &Collector{
+		Providers:   providers,
+		channelID:   channelID,
+		dcasCfg:     dcasCfg,
+		cfg:         cfg,
+		compression: compression.New(compression.WithDefaultAlgorithms()),
+		anchorRefs:  make(map[string]struct{}),
+		// Block 0 is the genesis (config) block so start at block 1
+		nextBlock:      1,
+		pendingAnchors: make(map[string]*common.TxnInfo),
+		candidates:     make(map[string]time.Time),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start starts the periodic garbage collection
+func (c *Collector) Start() {
+	logger.Infof("[%s] Starting DCAS garbage collector for [%s:%s] - Period: %s, GracePeriod: %s, Delete: %t",
+		c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, c.cfg.Period, c.cfg.GracePeriod, c.cfg.Delete)
+
+	go c.run()
+}
+
+// Stop stops the periodic garbage collection
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		logger.Debugf("[%s] Stopping DCAS garbage collector", c.channelID)
+
+		close(c.done)
+	})
+}
+
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.cfg.Period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := c.Collect(); err != nil {
+				logger.Warnf("[%s] DCAS garbage collection failed: %s", c.channelID, err)
+			}
+		case <-c.done:
+			logger.Debugf("[%s] DCAS garbage collector stopped", c.channelID)
+
+			return
+		}
+	}
+}
+
+// Collect performs a single garbage collection run. The stored content is listed before the referenced content is
+// marked so that content which becomes referenced during the run is not swept. If an error occurs while marking
+// then the run is aborted without sweeping since the set of referenced content would be incomplete. For the same
+// reason, nothing is swept if the content referenced by an anchor or file index document can't be determined.
+func (c *Collector) Collect() (*Result, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	logger.Debugf("[%s] Starting DCAS garbage collection for [%s:%s]", c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection)
+
+	dcasClient, err := c.DCASProvider.GetDCASClient(c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get DCAS client")
+	}
+
+	stored, err := c.listContent()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to list DCAS content")
+	}
+
+	referenced, unresolved, err := c.mark(dcasClient)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to mark referenced DCAS content")
+	}
+
+	result := &Result{
+		Stored:     len(stored),
+		Referenced: len(referenced),
+		Unresolved: unresolved,
+	}
+
+	if unresolved > 0 {
+		logger.Warnf("[%s] Not sweeping DCAS content in [%s:%s] since the content referenced by %d anchor(s) or file index document(s) couldn't be determined",
+			c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, unresolved)
+
+		return result, nil
+	}
+
+	result.Unreferenced = c.sweep(stored, referenced)
+
+	for _, cID := range result.Unreferenced {
+		logger.Infof("[%s] Unreferenced DCAS content in [%s:%s]: %s", c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, cID)
+	}
+
+	if c.cfg.Delete && len(result.Unreferenced) > 0 {
+		result.Deleted, err = c.delete(dcasClient, result.Unreferenced)
+		if err != nil {
+			return result, errors.WithMessage(err, "failed to delete unreferenced DCAS content")
+		}
+	}
+
+	logger.Infof("[%s] Completed DCAS garbage collection for [%s:%s] - Stored: %d, Referenced: %d, Unreferenced: %d, Deleted: %d",
+		c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, result.Stored, result.Referenced, len(result.Unreferenced), len(result.Deleted))
+
+	return result, nil
+}
+
+// sweep returns the stored content that has been unreferenced for longer than the grace period. Content that is
+// referenced (or no longer stored) is removed from the candidates.
+func (c *Collector) sweep(stored []string, referenced map[string]struct{}) []string {
+	now := time.Now()
+	candidates := make(map[string]time.Time)
+
+	var unreferenced []string
+
+	for _, cID := range stored {
+		if _, ok := referenced[cID]; ok {
+			continue
+		}
+
+		firstSeen, ok := c.candidates[cID]
+		if !ok {
+			firstSeen = now
+		}
+
+		candidates[cID] = firstSeen
+
+		if now.Sub(firstSeen) >= c.cfg.GracePeriod {
+			unreferenced = append(unreferenced, cID)
+		}
+	}
+
+	c.candidates = candidates
+
+	sort.Strings(unreferenced)
+
+	return unreferenced
+}
+
+func (c *Collector) delete(dcasClient dcasclient.DCAS, cIDs []string) ([]string, error) {
+	var deleted []string
+
+	for _, cID := range cIDs {
+		if err := deleteContent(dcasClient, cID); err != nil {
+			return deleted, errors.WithMessagef(err, "failed to delete [%s]", cID)
+		}
+
+		logger.Infof("[%s] Deleted unreferenced DCAS content in [%s:%s]: %s", c.channelID, c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, cID)
+
+		delete(c.candidates, cID)
+
+		deleted = append(deleted, cID)
+	}
+
+	return deleted, nil
+}
+
+// deleteContent deletes the given content from DCAS. Some DCAS data stores don't implement deletion and panic,
+// so the panic is converted to an error.
+func deleteContent(dcasClient dcasclient.DCAS, cID string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("delete not supported by the DCAS data store: %v", r)
+		}
+	}()
+
+	return dcasClient.Delete(cID)
+}
@@ -0,0 +1,565 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasgc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/ipfs/go-cid"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+	extmocks "github.com/trustbloc/fabric-peer-ext/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/filehandler"
+)
+
+const (
+	channel1   = "channel1"
+	ns1        = "did:bloc"
+	ccName     = "document_cc"
+	collection = "dcas"
+	fileIdxID  = "file:idx:EiAuE7R5Jj1EkaoW3pHaoKZTPSiptp8Hw7iAs3kVUVaUkg"
+	txn1       = "txn1"
+)
+
+var dcasCfg = config.DCAS{
+	ChaincodeName: ccName,
+	Collection:    collection,
+}
+
+func TestCollector_Collect(t *testing.T) {
+	t.Run("List only", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, len(ctx.stored), result.Stored)
+		require.Equal(t, sorted(ctx.orphans...), result.Unreferenced)
+		require.Empty(t, result.Deleted)
+
+		for _, cID := range ctx.referenced {
+			require.NotContains(t, result.Unreferenced, cID)
+		}
+	})
+
+	t.Run("Pinned CID", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{PinnedCIDs: []string{ctx.orphans[0]}})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, sorted(ctx.orphans[1:]...), result.Unreferenced)
+	})
+
+	t.Run("Grace period", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{GracePeriod: time.Hour})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Empty(t, result.Unreferenced)
+		require.Len(t, c.candidates, len(ctx.orphans))
+
+		// Make the first orphan appear to have been unreferenced for longer than the grace period
+		c.candidates[ctx.orphans[0]] = time.Now().Add(-2 * time.Hour)
+
+		result, err = c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, []string{ctx.orphans[0]}, result.Unreferenced)
+	})
+
+	t.Run("Incremental block scan", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		_, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, ctx.ledger.GetBlockByNumberCallCount())
+
+		// The anchor references are retained so that the block isn't scanned again
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, ctx.ledger.GetBlockByNumberCallCount())
+		require.Equal(t, sorted(ctx.orphans...), result.Unreferenced)
+	})
+
+	t.Run("Previous version of file index", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.NotContains(t, result.Unreferenced, ctx.prevFileCID)
+
+		// Only the latest version of the file index document
+		ctx.resolver.versions = ctx.resolver.versions[1:]
+
+		result, err = c.Collect()
+		require.NoError(t, err)
+		require.Contains(t, result.Unreferenced, ctx.prevFileCID)
+	})
+
+	t.Run("Deactivated file index", func(t *testing.T) {
+		ctx := newTestContext(t)
+		ctx.resolver.versions = ctx.resolver.versions[1:]
+		ctx.resolver.deactivated = true
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, sorted(append(ctx.orphans, ctx.fileRefs...)...), result.Unreferenced)
+	})
+
+	t.Run("File index not found -> not swept", func(t *testing.T) {
+		ctx := newTestContext(t)
+		ctx.resolver.err = errors.New("document not found")
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Unresolved)
+		require.Empty(t, result.Unreferenced)
+		require.Empty(t, c.candidates)
+	})
+
+	t.Run("Anchor file not found -> pending", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		pif := bytes.NewBuffer(nil)
+		require.NoError(t, ctx.dcasClient.Get(ctx.pifCID, pif))
+
+		// The provisional index file hasn't been stored on this peer yet
+		ctx.dcasClient.WithData(ctx.pifCID, nil)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Unresolved)
+		require.Empty(t, result.Unreferenced)
+		require.Len(t, c.pendingAnchors, 1)
+
+		// The block isn't scanned again but the pending anchor is retried
+		result, err = c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Unresolved)
+		require.Empty(t, result.Unreferenced)
+		require.Equal(t, 1, ctx.ledger.GetBlockByNumberCallCount())
+
+		ctx.dcasClient.WithData(ctx.pifCID, pif.Bytes())
+
+		result, err = c.Collect()
+		require.NoError(t, err)
+		require.Zero(t, result.Unresolved)
+		require.Equal(t, sorted(ctx.orphans...), result.Unreferenced)
+		require.Empty(t, c.pendingAnchors)
+	})
+
+	t.Run("Invalid anchor file -> pending", func(t *testing.T) {
+		ctx := newTestContext(t)
+		ctx.dcasClient.WithData(ctx.cifCID, []byte("not compressed"))
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Unresolved)
+		require.Empty(t, result.Unreferenced)
+		require.Len(t, c.pendingAnchors, 1)
+	})
+
+	t.Run("Namespace not served", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+		c.ProtocolClients = coremocks.NewMockProtocolClientProvider()
+
+		// Only the core index file is marked since the other anchor files can't be decompressed
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Zero(t, result.Unresolved)
+		require.Equal(t, sorted(append(ctx.orphans, ctx.anchorFileRefs...)...), result.Unreferenced)
+		require.NotContains(t, result.Unreferenced, ctx.cifCID)
+		require.Empty(t, c.pendingAnchors)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		ctx := newTestContext(t)
+		dcasClient := &deletableDCASClient{MockDCASClient: ctx.dcasClient}
+		ctx.dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{Delete: true})
+
+		result, err := c.Collect()
+		require.NoError(t, err)
+		require.Equal(t, sorted(ctx.orphans...), result.Unreferenced)
+		require.Equal(t, result.Unreferenced, result.Deleted)
+		require.Equal(t, result.Unreferenced, dcasClient.deleted)
+		require.Empty(t, c.candidates)
+	})
+
+	t.Run("Delete not supported -> error", func(t *testing.T) {
+		ctx := newTestContext(t)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{Delete: true})
+
+		result, err := c.Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete not supported")
+		require.NotNil(t, result)
+		require.Equal(t, sorted(ctx.orphans...), result.Unreferenced)
+		require.Empty(t, result.Deleted)
+	})
+
+	t.Run("DCAS provider error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected DCAS provider error")
+		ctx.dcasProvider.GetDCASClientReturns(nil, errExpected)
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Off-ledger provider error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected off-ledger provider error")
+		ctx.olProvider.ForChannelReturns(nil, errExpected)
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Contains(t, err.Error(), "failed to list DCAS content")
+	})
+
+	t.Run("Blockchain info error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected blockchain info error")
+		ctx.ledger.GetBlockchainInfoReturns(nil, errExpected)
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Contains(t, err.Error(), "failed to mark referenced DCAS content")
+	})
+
+	t.Run("Get block error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected get block error")
+		ctx.ledger.GetBlockByNumberReturns(nil, errExpected)
+
+		c := ctx.newCollector(config.DCASGarbageCollector{})
+
+		_, err := c.Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Equal(t, uint64(1), c.nextBlock)
+	})
+
+	t.Run("File index provider error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected file index provider error")
+		ctx.fileIndexes.err = errExpected
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Resolve error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected resolve error")
+		ctx.resolver.err = errExpected
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("GetNode error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected GetNode error")
+		ctx.dcasClient.WithGetNodeError(errExpected)
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		errExpected := errors.New("injected Get error")
+		ctx.dcasClient.WithGetError(errExpected)
+
+		_, err := ctx.newCollector(config.DCASGarbageCollector{}).Collect()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestCollector_StartStop(t *testing.T) {
+	ctx := newTestContext(t)
+
+	c := ctx.newCollector(config.DCASGarbageCollector{Period: 10 * time.Millisecond})
+
+	c.Start()
+
+	time.Sleep(100 * time.Millisecond)
+
+	c.Stop()
+	require.NotPanics(t, c.Stop)
+
+	require.True(t, ctx.ledger.GetBlockchainInfoCallCount() > 0)
+}
+
+type testContext struct {
+	dcasClient   *mocks.MockDCASClient
+	dcasProvider *mocks.DCASClientProvider
+	olProvider   *obmocks.OffLedgerClientProvider
+	ledger       *obmocks.BlockchainClient
+	resolver     *mockResolver
+	fileIndexes  *mockFileIndexProvider
+
+	// stored contains the CIDs of all stored content
+	stored []string
+	// referenced contains the CIDs of the stored content that's referenced
+	referenced []string
+	// fileRefs contains the CIDs of the stored content that's referenced only by the file index document
+	fileRefs []string
+	// orphans contains the CIDs of the stored content that isn't referenced
+	orphans []string
+	// prevFileCID is the CID of the file that's mapped only in the previous version of the file index document
+	prevFileCID string
+	// cifCID is the CID of the core index file that's referenced by the anchor
+	cifCID string
+	// pifCID is the CID of the provisional index file that's referenced by the core index file
+	pifCID string
+	// anchorFileRefs contains the CIDs of the anchor files that are referenced (directly or indirectly) by the core index file
+	anchorFileRefs []string
+}
+
+func newTestContext(t *testing.T) *testContext {
+	ctx := &testContext{
+		dcasClient:   mocks.NewDCASClient(),
+		dcasProvider: &mocks.DCASClientProvider{},
+		olProvider:   &obmocks.OffLedgerClientProvider{},
+		ledger:       &obmocks.BlockchainClient{},
+		resolver:     &mockResolver{},
+	}
+
+	ctx.fileIndexes = &mockFileIndexProvider{fileIndexes: []FileIndex{{DocID: fileIdxID, Resolver: ctx.resolver}}}
+
+	ctx.dcasProvider.GetDCASClientReturns(ctx.dcasClient, nil)
+
+	// Anchor files
+	chunkCID := ctx.put(t, ctx.compress(t, []byte(`{"deltas":[]}`)))
+	ppfCID := ctx.put(t, ctx.compress(t, []byte(`{"operations":{}}`)))
+	ctx.pifCID = ctx.put(t, ctx.compress(t, marshal(t, &models.ProvisionalIndexFile{
+		ProvisionalProofFileURI: ppfCID,
+		Chunks:                  []models.Chunk{{ChunkFileURI: chunkCID}},
+	})))
+	cpfCID := ctx.put(t, ctx.compress(t, []byte(`{"operations":{"recover":[]}}`)))
+	ctx.cifCID = ctx.put(t, ctx.compress(t, marshal(t, &models.CoreIndexFile{
+		ProvisionalIndexFileURI: ctx.pifCID,
+		CoreProofFileURI:        cpfCID,
+	})))
+
+	ctx.anchorFileRefs = append(ctx.anchorFileRefs, chunkCID, ppfCID, ctx.pifCID, cpfCID)
+	ctx.referenced = append(ctx.referenced, ctx.cifCID)
+	ctx.referenced = append(ctx.referenced, ctx.anchorFileRefs...)
+
+	// Files mapped in the file index document
+	fileCID := ctx.put(t, marshal(t, &filehandler.File{ContentType: "text/plain", Content: []byte("file content")}))
+	fileChunk1CID := ctx.put(t, []byte("chunk1"))
+	fileChunk2CID := ctx.put(t, []byte("chunk2"))
+	manifestCID := ctx.put(t, marshal(t, &filehandler.FileManifest{
+		ContentType: "text/plain",
+		Chunks:      []string{fileChunk1CID, fileChunk2CID},
+	}))
+
+	// A large file whose content is split into linked blocks
+	block1CID := ctx.put(t, []byte("block1"))
+	block2CID := ctx.put(t, []byte("block2"))
+	largeFileCID := ctx.put(t, []byte("large file"))
+	ctx.dcasClient.WithNode(largeFileCID, &dcasclient.Node{
+		Links: []dcasclient.Link{{Hash: block1CID}, {Hash: block2CID}},
+	})
+
+	// A file that was replaced in the latest version of the file index document
+	ctx.prevFileCID = ctx.put(t, marshal(t, &filehandler.File{ContentType: "text/plain", Content: []byte("previous content")}))
+
+	ctx.fileRefs = append(ctx.fileRefs, fileCID, fileChunk1CID, fileChunk2CID, manifestCID, block1CID, block2CID, largeFileCID, ctx.prevFileCID)
+	ctx.referenced = append(ctx.referenced, ctx.fileRefs...)
+
+	ctx.resolver.versions = []map[string]string{
+		{
+			"file1.txt": ctx.prevFileCID,
+		},
+		{
+			"file1.txt": fileCID,
+			"file2.txt": manifestCID,
+			"large.bin": largeFileCID,
+			"invalid":   "invalid-cid",
+		},
+	}
+
+	ctx.orphans = append(ctx.orphans, ctx.put(t, []byte("orphan1")), ctx.put(t, []byte("orphan2")))
+
+	bb := extmocks.NewBlockBuilder(channel1, 1)
+	bb.Transaction(txn1, pb.TxValidationCode_VALID).ChaincodeAction("sidetreetxn_cc").
+		Write(common.AnchorPrefix+"1", []byte(fmt.Sprintf(`{"anchorString":"1.%s","namespace":"%s"}`, ctx.cifCID, ns1))).
+		Write(common.AnchorPrefix+"2", []byte(fmt.Sprintf(`{"anchorString":"invalid","namespace":"%s"}`, ns1)))
+
+	ctx.ledger.GetBlockchainInfoReturns(&cb.BlockchainInfo{Height: 2}, nil)
+	ctx.ledger.GetBlockByNumberReturns(bb.Build(), nil)
+
+	var kvs []*queryresult.KV
+	for _, cID := range ctx.stored {
+		kvs = append(kvs, &queryresult.KV{Key: "/blocks" + toKey(t, cID)})
+	}
+
+	kvs = append(kvs, &queryresult.KV{Key: "/blocks/invalid_key"})
+
+	ctx.olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithQueryResults(ccName, collection, allContentQuery, kvs), nil)
+
+	return ctx
+}
+
+func (ctx *testContext) newCollector(cfg config.DCASGarbageCollector) *Collector {
+	return New(channel1, dcasCfg, cfg,
+		&Providers{
+			Ledger:            ctx.ledger,
+			DCASProvider:      ctx.dcasProvider,
+			OffLedger:         ctx.olProvider,
+			ProtocolClients:   coremocks.NewMockProtocolClientProvider().WithProtocolClient(ns1, coremocks.NewMockProtocolClient()),
+			FileIndexProvider: ctx.fileIndexes,
+		},
+	)
+}
+
+func (ctx *testContext) put(t *testing.T, content []byte) string {
+	cID, err := ctx.dcasClient.Put(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	ctx.stored = append(ctx.stored, cID)
+
+	return cID
+}
+
+func (ctx *testContext) compress(t *testing.T, content []byte) []byte {
+	compressed, err := compression.New(compression.WithDefaultAlgorithms()).Compress("GZIP", content)
+	require.NoError(t, err)
+
+	return compressed
+}
+
+func toKey(t *testing.T, cIDStr string) string {
+	cID, err := cid.Decode(cIDStr)
+	require.NoError(t, err)
+
+	return dshelp.CidToDsKey(cID).String()
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func sorted(values ...string) []string {
+	s := make([]string, len(values))
+	copy(s, values)
+	sort.Strings(s)
+
+	return s
+}
+
+type mockFileIndexProvider struct {
+	fileIndexes []FileIndex
+	err         error
+}
+
+func (m *mockFileIndexProvider) GetFileIndexes(chaincodeName, collection string) ([]FileIndex, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.fileIndexes, nil
+}
+
+type mockResolver struct {
+	// versions contains the file mappings of each version of the file index document
+	versions []map[string]string
+	// deactivated indicates that the latest version of the document is deactivated
+	deactivated bool
+	err         error
+}
+
+func (m *mockResolver) ResolveDocumentVersions(id string) ([]*document.ResolutionResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var results []*document.ResolutionResult
+
+	for i, mappings := range m.versions {
+		results = append(results, &document.ResolutionResult{
+			Document: document.Document{
+				"id": id,
+				"fileIndex": map[string]interface{}{
+					"basePath": "/content",
+					"mappings": mappings,
+				},
+			},
+			DocumentMetadata: document.Metadata{
+				document.DeactivatedProperty: m.deactivated && i == len(m.versions)-1,
+			},
+		})
+	}
+
+	return results, nil
+}
+
+// deletableDCASClient is a mock DCAS client that supports deletion
+type deletableDCASClient struct {
+	*mocks.MockDCASClient
+	deleted []string
+}
+
+func (m *deletableDCASClient) Delete(cids ...string) error {
+	m.deleted = append(m.deleted, cids...)
+
+	return nil
+}
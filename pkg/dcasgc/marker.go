@@ -0,0 +1,418 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasgc
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/anchorscanner"
+	"github.com/trustbloc/sidetree-fabric/pkg/common/dcascid"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/filehandler"
+)
+
+// allContentQuery is the CouchDB query that returns the keys of all of the content in a DCAS collection
+const allContentQuery = `{"selector":{"_id":{"$gt":null}},"fields":["_id"]}`
+
+// listContent returns the CIDs of all of the content stored in the DCAS collection
+func (c *Collector) listContent() ([]string, error) {
+	olClient, err := c.OffLedger.ForChannel(c.channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get off-ledger client")
+	}
+
+	it, err := olClient.Query(c.dcasCfg.ChaincodeName, c.dcasCfg.Collection, allContentQuery)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to query DCAS collection")
+	}
+
+	defer it.Close()
+
+	var cIDs []string
+
+	for {
+		next, err := it.Next()
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to retrieve next DCAS key")
+		}
+
+		if next == nil {
+			break
+		}
+
+		key := next.(*queryresult.KV).Key
+
+		cID, err := dcascid.FromKey(key)
+		if err != nil {
+			logger.Warnf("[%s] Ignoring invalid DCAS key [%s]: %s", c.channelID, key, err)
+
+			continue
+		}
+
+		cIDs = append(cIDs, cID)
+	}
+
+	return cIDs, nil
+}
+
+// mark returns the CIDs of all of the referenced content, including the content linked from referenced content,
+// along with the number of anchors and file index documents whose referenced content couldn't be determined
+func (c *Collector) mark(dcasClient dcasclient.DCAS) (map[string]struct{}, int, error) {
+	if err := c.markAnchors(dcasClient); err != nil {
+		return nil, 0, err
+	}
+
+	roots := make(map[string]struct{})
+
+	for cID := range c.anchorRefs {
+		roots[cID] = struct{}{}
+	}
+
+	for _, cID := range c.cfg.PinnedCIDs {
+		roots[cID] = struct{}{}
+	}
+
+	unresolvedFileIndexes, err := c.markFileIndexes(dcasClient, roots)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	referenced := make(map[string]struct{})
+
+	for cID := range roots {
+		if err := c.markLinks(dcasClient, cID, referenced); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return referenced, len(c.pendingAnchors) + unresolvedFileIndexes, nil
+}
+
+// markAnchors adds the files referenced by the anchors in the blocks committed since the previous run. The anchors
+// whose files couldn't be read on a previous run are retried first. An anchor whose files can't be read is added to
+// the pending anchors so that it's retried on the next run.
+func (c *Collector) markAnchors(dcasClient dcasclient.DCAS) error {
+	for anchorString, txnInfo := range c.pendingAnchors {
+		marked, err := c.markAnchor(dcasClient, txnInfo)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to mark pending anchor [%s]", anchorString)
+		}
+
+		if marked {
+			logger.Debugf("[%s] Marked the files of pending anchor [%s]", c.channelID, anchorString)
+
+			delete(c.pendingAnchors, anchorString)
+		}
+	}
+
+	bcInfo, err := c.Ledger.GetBlockchainInfo()
+	if err != nil {
+		return errors.WithMessage(err, "failed to get blockchain info")
+	}
+
+	for ; c.nextBlock < bcInfo.Height; c.nextBlock++ {
+		block, err := c.Ledger.GetBlockByNumber(c.nextBlock)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to get block %d", c.nextBlock)
+		}
+
+		anchors, _, err := anchorscanner.New(c.channelID, block, 0, math.MaxInt32, nil).Scan()
+		if err != nil {
+			return errors.WithMessagef(err, "failed to scan block %d", c.nextBlock)
+		}
+
+		for _, anchor := range anchors {
+			txnInfo := anchor.TxnInfo
+
+			marked, err := c.markAnchor(dcasClient, &txnInfo)
+			if err != nil {
+				return errors.WithMessagef(err, "failed to mark anchor [%s] in block %d", txnInfo.AnchorString, c.nextBlock)
+			}
+
+			if !marked {
+				logger.Warnf("[%s] Unable to read the files of anchor [%s] in block %d. The anchor will be retried on the next run.",
+					c.channelID, txnInfo.AnchorString, c.nextBlock)
+
+				c.pendingAnchors[txnInfo.AnchorString] = &txnInfo
+			}
+		}
+	}
+
+	return nil
+}
+
+// markAnchor adds the core index file referenced by the anchor along with the provisional index, chunk and proof
+// files that are referenced by the core index file. False is returned if the core or provisional index file
+// couldn't be read (i.e. it's not yet stored on this peer or it can't be decompressed or parsed), in which case
+// the files that it references are unknown.
+func (c *Collector) markAnchor(dcasClient dcasclient.DCAS, txnInfo *common.TxnInfo) (bool, error) {
+	ad, err := txnprovider.ParseAnchorData(txnInfo.AnchorString)
+	if err != nil {
+		// The anchor doesn't reference any files
+		logger.Warnf("[%s] Ignoring invalid anchor [%s]: %s", c.channelID, txnInfo.AnchorString, err)
+
+		return true, nil
+	}
+
+	c.addAnchorRef(ad.CoreIndexFileURI)
+
+	pc, err := c.ProtocolClients.ForNamespace(txnInfo.Namespace)
+	if err != nil {
+		// The anchor files can't be decompressed without the protocol of the namespace
+		logger.Debugf("[%s] Namespace [%s] isn't served by this peer. Only the core index file [%s] is marked: %s",
+			c.channelID, txnInfo.Namespace, ad.CoreIndexFileURI, err)
+
+		return true, nil
+	}
+
+	pv, err := pc.Get(txnInfo.ProtocolGenesisTime)
+	if err != nil {
+		logger.Warnf("[%s] Unable to get protocol version for namespace [%s]: %s", c.channelID, txnInfo.Namespace, err)
+
+		return false, nil
+	}
+
+	alg := pv.Protocol().CompressionAlgorithm
+
+	content, err := c.readAnchorFile(dcasClient, ad.CoreIndexFileURI, alg)
+	if err != nil || content == nil {
+		return false, err
+	}
+
+	cif, err := models.ParseCoreIndexFile(content)
+	if err != nil {
+		logger.Warnf("[%s] Unable to parse core index file [%s]: %s", c.channelID, ad.CoreIndexFileURI, err)
+
+		return false, nil
+	}
+
+	c.addAnchorRef(cif.CoreProofFileURI)
+
+	if cif.ProvisionalIndexFileURI == "" {
+		return true, nil
+	}
+
+	c.addAnchorRef(cif.ProvisionalIndexFileURI)
+
+	content, err = c.readAnchorFile(dcasClient, cif.ProvisionalIndexFileURI, alg)
+	if err != nil || content == nil {
+		return false, err
+	}
+
+	pif, err := models.ParseProvisionalIndexFile(content)
+	if err != nil {
+		logger.Warnf("[%s] Unable to parse provisional index file [%s]: %s", c.channelID, cif.ProvisionalIndexFileURI, err)
+
+		return false, nil
+	}
+
+	c.addAnchorRef(pif.ProvisionalProofFileURI)
+
+	for _, chunk := range pif.Chunks {
+		c.addAnchorRef(chunk.ChunkFileURI)
+	}
+
+	return true, nil
+}
+
+func (c *Collector) addAnchorRef(uri string) {
+	if uri != "" {
+		c.anchorRefs[uri] = struct{}{}
+	}
+}
+
+// readAnchorFile reads and decompresses the given anchor file. Nil is returned if the file is not found or
+// if it can't be decompressed.
+func (c *Collector) readAnchorFile(dcasClient dcasclient.DCAS, uri, alg string) ([]byte, error) {
+	content, err := readContent(dcasClient, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if content == nil {
+		logger.Debugf("[%s] Anchor file [%s] not found", c.channelID, uri)
+
+		return nil, nil
+	}
+
+	content, err = c.compression.Decompress(alg, content)
+	if err != nil {
+		logger.Warnf("[%s] Unable to decompress anchor file [%s]: %s", c.channelID, uri, err)
+
+		return nil, nil
+	}
+
+	return content, nil
+}
+
+// markFileIndexes adds the files that are mapped in any version of each file index document. If a file
+// was stored in chunks then the chunks are also added. The number of file index documents that couldn't
+// be found is returned.
+func (c *Collector) markFileIndexes(dcasClient dcasclient.DCAS, roots map[string]struct{}) (int, error) {
+	fileIndexes, err := c.FileIndexProvider.GetFileIndexes(c.dcasCfg.ChaincodeName, c.dcasCfg.Collection)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get file index documents")
+	}
+
+	unresolved := 0
+
+	for _, fileIndex := range fileIndexes {
+		files, found, err := c.resolveFileIndex(fileIndex)
+		if err != nil {
+			return 0, errors.WithMessagef(err, "failed to resolve file index document [%s]", fileIndex.DocID)
+		}
+
+		if !found {
+			unresolved++
+
+			continue
+		}
+
+		for cID := range files {
+			roots[cID] = struct{}{}
+
+			chunks, err := getChunks(dcasClient, cID)
+			if err != nil {
+				return 0, errors.WithMessagef(err, "failed to read file [%s]", cID)
+			}
+
+			for _, chunk := range chunks {
+				roots[chunk] = struct{}{}
+			}
+		}
+	}
+
+	return unresolved, nil
+}
+
+// resolveFileIndex returns the CIDs of the files that are mapped in any version of the given file index document.
+// The versions in which the document is deactivated are ignored. False is returned if the document isn't found
+// (for example, if it hasn't been processed by the observer yet).
+func (c *Collector) resolveFileIndex(fileIndex FileIndex) (map[string]struct{}, bool, error) {
+	results, err := fileIndex.Resolver.ResolveDocumentVersions(fileIndex.DocID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logger.Warnf("[%s] File index document [%s] not found", c.channelID, fileIndex.DocID)
+
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	files := make(map[string]struct{})
+
+	for _, result := range results {
+		if deactivated, ok := result.DocumentMetadata[document.DeactivatedProperty].(bool); ok && deactivated {
+			logger.Debugf("[%s] Ignoring deactivated version of file index document [%s]", c.channelID, fileIndex.DocID)
+
+			continue
+		}
+
+		docBytes, err := json.Marshal(result.Document)
+		if err != nil {
+			return nil, false, err
+		}
+
+		fileIndexDoc := &filehandler.FileIndexDoc{}
+		if err := json.Unmarshal(docBytes, fileIndexDoc); err != nil {
+			return nil, false, errors.WithMessage(err, "invalid file index document")
+		}
+
+		for _, cID := range fileIndexDoc.FileIndex.Mappings {
+			files[cID] = struct{}{}
+		}
+	}
+
+	return files, true, nil
+}
+
+// getChunks returns the CIDs of the chunks if the given file was stored as a chunked file manifest
+func getChunks(dcasClient dcasclient.DCAS, cID string) ([]string, error) {
+	content, err := readContent(dcasClient, cID)
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	manifest := &filehandler.FileManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		// Not a file or manifest
+		return nil, nil
+	}
+
+	return manifest.Chunks, nil
+}
+
+// markLinks adds the given CID along with all of the nodes that are linked from it (for example, the
+// blocks of a large file)
+func (c *Collector) markLinks(dcasClient dcasclient.DCAS, cIDStr string, referenced map[string]struct{}) error {
+	cID, err := normalize(cIDStr)
+	if err != nil {
+		logger.Debugf("[%s] Ignoring invalid CID [%s]: %s", c.channelID, cIDStr, err)
+
+		return nil
+	}
+
+	if _, ok := referenced[cID]; ok {
+		return nil
+	}
+
+	referenced[cID] = struct{}{}
+
+	node, err := dcasClient.GetNode(cID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get node [%s]", cID)
+	}
+
+	if node == nil {
+		return nil
+	}
+
+	for _, link := range node.Links {
+		if err := c.markLinks(dcasClient, link.Hash, referenced); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readContent returns the content for the given CID or nil if the content is not found
+func readContent(dcasClient dcasclient.DCAS, cID string) ([]byte, error) {
+	content := bytes.NewBuffer(nil)
+
+	if err := dcasClient.Get(cID, content); err != nil {
+		return nil, errors.WithMessagef(err, "failed to read [%s]", cID)
+	}
+
+	if content.Len() == 0 {
+		return nil, nil
+	}
+
+	return content.Bytes(), nil
+}
+
+// normalize returns the canonical string form of the given CID so that it may be compared with the stored CIDs
+func normalize(cIDStr string) (string, error) {
+	cID, err := cid.Decode(cIDStr)
+	if err != nil {
+		return "", err
+	}
+
+	return cID.String(), nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasintegrity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/dcascid"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+var logger = flogging.MustGetLogger("sidetree_dcasintegrity")
+
+// allContentQuery is the CouchDB query that returns all of the content (keys and values) in a DCAS collection
+const allContentQuery = `{"selector":{"_id":{"$gt":null}}}`
+
+// ErrScanInProgress is returned by Scan if a scan is already running
+var ErrScanInProgress = errors.New("integrity scan in progress")
+
+// Report contains the result of an integrity scan
+type Report struct {
+	// Scanned is the number of items of content that were scanned
+	Scanned int `json:"scanned"`
+	// Verified is the number of items of content that match their CID
+	Verified int `json:"verified"`
+	// Mismatched contains the CIDs of the content that doesn't match its CID
+	Mismatched []string `json:"mismatched,omitempty"`
+	// Unreadable contains the entries that couldn't be verified
+	Unreadable []*Entry `json:"unreadable,omitempty"`
+}
+
+// Entry contains the data store key of an entry that couldn't be verified along with the reason
+type Entry struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// Scanner verifies the integrity of the content in a DCAS collection. Each item of content is re-hashed according to
+// the multihash of its CID and the content that doesn't match its CID, or that can't be read, is reported.
+//
+// The content is read directly from the off-ledger collection so the stored blocks (rather than the content that's
+// reassembled from the blocks of a large file) are verified.
+type Scanner struct {
+	channelID  string
+	namespace  string
+	collection string
+	olProvider common.OffLedgerClientProvider
+
+	mutex   sync.Mutex
+	running bool
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New returns a new integrity scanner for the given DCAS collection
+func New(channelID, namespace, collection string, olProvider common.OffLedgerClientProvider) *Scanner {
+	return &Scanner{
+		channelID:  channelID,
+		namespace:  namespace,
+		collection: collection,
+		olProvider: olProvider,
+		done:       make(chan struct{}),
+	}
+}
+
+// Namespace returns the namespace (chaincode name) of the DCAS collection
+func (s *Scanner) Namespace() string {
+	return s.namespace
+}
+
+// Collection returns the name of the DCAS collection
+func (s *Scanner) Collection() string {
+	return s.collection
+}
+
+// Start starts a background scan at the given period
+func (s *Scanner) Start(period time.Duration) {
+	logger.Infof("[%s] Starting DCAS integrity scanner for [%s:%s] - Period: %s", s.channelID, s.namespace, s.collection, period)
+
+	go s.run(period)
+}
+
+// Stop stops the background scan
+func (s *Scanner) Stop() {
+	s.stopOnce.Do(func() {
+		logger.Debugf("[%s] Stopping DCAS integrity scanner", s.channelID)
+
+		close(s.done)
+	})
+}
+
+func (s *Scanner) run(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Scan(); err != nil {
+				if err == ErrScanInProgress {
+					logger.Debugf("[%s] Skipping DCAS integrity scan since a scan is already running", s.channelID)
+
+					continue
+				}
+
+				logger.Warnf("[%s] DCAS integrity scan failed: %s", s.channelID, err)
+			}
+		case <-s.done:
+			logger.Debugf("[%s] DCAS integrity scanner stopped", s.channelID)
+
+			return
+		}
+	}
+}
+
+// Scan verifies all of the content in the DCAS collection. Only one scan runs at a time, so ErrScanInProgress is
+// returned (rather than waiting) if a scan is already running.
+func (s *Scanner) Scan() (*Report, error) {
+	if !s.start() {
+		return nil, ErrScanInProgress
+	}
+
+	defer s.finish()
+
+	logger.Debugf("[%s] Starting DCAS integrity scan for [%s:%s]", s.channelID, s.namespace, s.collection)
+
+	olClient, err := s.olProvider.ForChannel(s.channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get off-ledger client")
+	}
+
+	it, err := olClient.Query(s.namespace, s.collection, allContentQuery)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to query DCAS collection")
+	}
+
+	defer it.Close()
+
+	report := &Report{}
+
+	for {
+		next, err := it.Next()
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to retrieve next DCAS entry")
+		}
+
+		if next == nil {
+			break
+		}
+
+		s.verify(next.(*queryresult.KV), report)
+	}
+
+	logger.Infof("[%s] Completed DCAS integrity scan for [%s:%s] - Scanned: %d, Verified: %d, Mismatched: %d, Unreadable: %d",
+		s.channelID, s.namespace, s.collection, report.Scanned, report.Verified, len(report.Mismatched), len(report.Unreadable))
+
+	return report, nil
+}
+
+func (s *Scanner) start() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running {
+		return false
+	}
+
+	s.running = true
+
+	return true
+}
+
+func (s *Scanner) finish() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.running = false
+}
+
+func (s *Scanner) verify(kv *queryresult.KV, report *Report) {
+	report.Scanned++
+
+	cID, err := dcascid.FromKey(kv.Key)
+	if err != nil {
+		s.addUnreadable(report, kv.Key, errors.WithMessage(err, "invalid key"))
+
+		return
+	}
+
+	if len(kv.Value) == 0 {
+		s.addUnreadable(report, kv.Key, errors.New("no content"))
+
+		return
+	}
+
+	if err := dcascid.Verify(cID, kv.Value); err != nil {
+		if errors.Cause(err) == dcascid.ErrMismatch {
+			logger.Errorf("[%s] DCAS content in [%s:%s] doesn't match its CID: %s", s.channelID, s.namespace, s.collection, err)
+
+			report.Mismatched = append(report.Mismatched, cID)
+
+			return
+		}
+
+		s.addUnreadable(report, kv.Key, err)
+
+		return
+	}
+
+	report.Verified++
+}
+
+func (s *Scanner) addUnreadable(report *Report, key string, err error) {
+	logger.Warnf("[%s] Unable to verify DCAS entry [%s] in [%s:%s]: %s", s.channelID, key, s.namespace, s.collection, err)
+
+	report.Unreadable = append(report.Unreadable, &Entry{
+		Key:   key,
+		Error: err.Error(),
+	})
+}
@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasintegrity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/ipfs/go-cid"
+	dshelp "github.com/ipfs/go-ipfs-ds-help"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/client"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+)
+
+const (
+	channel1   = "channel1"
+	ccName     = "document_cc"
+	collection = "dcas"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	content1 := []byte("content1")
+	content2 := []byte("content2")
+
+	cID1 := getCID(t, content1)
+	cID2 := getCID(t, content2)
+	cID3 := getCID(t, []byte("content3"))
+
+	t.Run("Success", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithQueryResults(ccName, collection, allContentQuery,
+			[]*queryresult.KV{
+				{Key: toKey(t, cID1), Value: content1},
+				{Key: toKey(t, cID2), Value: content2},
+				{Key: toKey(t, cID3), Value: content1},
+				{Key: toKey(t, cID2), Value: nil},
+				{Key: "/blocks/invalid_key", Value: content1},
+			},
+		), nil)
+
+		s := New(channel1, ccName, collection, olProvider)
+		require.NotNil(t, s)
+
+		report, err := s.Scan()
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		require.Equal(t, 5, report.Scanned)
+		require.Equal(t, 2, report.Verified)
+		require.Equal(t, []string{cID3}, report.Mismatched)
+		require.Len(t, report.Unreadable, 2)
+		require.Equal(t, toKey(t, cID2), report.Unreadable[0].Key)
+		require.Equal(t, "no content", report.Unreadable[0].Error)
+		require.Equal(t, "/blocks/invalid_key", report.Unreadable[1].Key)
+		require.Contains(t, report.Unreadable[1].Error, "invalid key")
+	})
+
+	t.Run("Unsupported hash", func(t *testing.T) {
+		hash, err := mh.Encode([]byte("1234"), mh.X11)
+		require.NoError(t, err)
+
+		key := "/blocks" + dshelp.CidToDsKey(cid.NewCidV1(cid.Raw, hash)).String()
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithDefaultQueryResults(
+			[]*queryresult.KV{{Key: key, Value: content1}},
+		), nil)
+
+		report, err := New(channel1, ccName, collection, olProvider).Scan()
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Scanned)
+		require.Empty(t, report.Mismatched)
+		require.Len(t, report.Unreadable, 1)
+		require.Equal(t, key, report.Unreadable[0].Key)
+	})
+
+	t.Run("Off-ledger provider error", func(t *testing.T) {
+		errExpected := errors.New("injected off-ledger provider error")
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(nil, errExpected)
+
+		report, err := New(channel1, ccName, collection, olProvider).Scan()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Nil(t, report)
+	})
+
+	t.Run("Scan in progress", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelStub = func(string) (client.OffLedger, error) {
+			close(started)
+			<-release
+
+			return obmocks.NewMockOffLedgerClient(), nil
+		}
+
+		s := New(channel1, ccName, collection, olProvider)
+
+		done := make(chan error)
+
+		go func() {
+			_, err := s.Scan()
+			done <- err
+		}()
+
+		<-started
+
+		report, err := s.Scan()
+		require.Equal(t, ErrScanInProgress, err)
+		require.Nil(t, report)
+
+		close(release)
+		require.NoError(t, <-done)
+
+		// Another scan may run once the previous scan has completed
+		olProvider.ForChannelStub = nil
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		_, err = s.Scan()
+		require.NoError(t, err)
+	})
+}
+
+func TestScanner_StartStop(t *testing.T) {
+	olProvider := &obmocks.OffLedgerClientProvider{}
+	olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+	s := New(channel1, ccName, collection, olProvider)
+
+	s.Start(10 * time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	s.Stop()
+	require.NotPanics(t, s.Stop)
+
+	require.True(t, olProvider.ForChannelCallCount() > 0)
+}
+
+func getCID(t *testing.T, content []byte) string {
+	cID, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+	require.NoError(t, err)
+
+	return cID
+}
+
+func toKey(t *testing.T, cIDStr string) string {
+	cID, err := cid.Decode(cIDStr)
+	require.NoError(t, err)
+
+	return "/blocks" + dshelp.CidToDsKey(cID).String()
+}
@@ -0,0 +1,251 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasquota
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/common"
+)
+
+var logger = flogging.MustGetLogger("sidetree_dcasquota")
+
+// rateWindow is the period over which the number of uploaded objects is limited
+const rateWindow = time.Hour
+
+var (
+	// ErrQuotaExceeded indicates that the storage quota of the token has been used up
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrRateLimitExceeded indicates that the maximum number of uploads in the current hour has been reached
+	ErrRateLimitExceeded = errors.New("upload rate limit exceeded")
+	// ErrNoQuota indicates that no quota is configured for the token
+	ErrNoQuota = errors.New("no quota configured for token")
+)
+
+// Quota defines the upload limits for a write token. A value of 0 means that the corresponding limit is not enforced.
+type Quota struct {
+	// MaxBytes is the maximum total number of bytes that may be uploaded with the token
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+	// MaxObjectsPerHour is the maximum number of objects that may be uploaded with the token in any one hour
+	MaxObjectsPerHour int `json:"maxObjectsPerHour,omitempty"`
+	// MaxObjectSize is the maximum size (in bytes) of a single uploaded object
+	MaxObjectSize int64 `json:"maxObjectSize,omitempty"`
+}
+
+// Usage contains the upload usage of a token
+type Usage struct {
+	// Token is the name of the token
+	Token string `json:"token"`
+	// Quota contains the quota configured for the token
+	Quota Quota `json:"quota"`
+	// Bytes is the total number of bytes uploaded with the token
+	Bytes int64 `json:"bytes"`
+	// Objects is the total number of objects uploaded with the token
+	Objects int64 `json:"objects"`
+	// WindowStart is the start of the current rate-limiting window
+	WindowStart time.Time `json:"windowStart"`
+	// WindowObjects is the number of uploads that were started in the current rate-limiting window. Uploads are
+	// counted when they're reserved, so an upload that fails still counts towards the rate limit.
+	WindowObjects int `json:"windowObjects"`
+}
+
+// Allowance contains the limits that apply to a single upload. A value of 0 means that the limit is not enforced.
+type Allowance struct {
+	// MaxObjectSize is the maximum size of the object
+	MaxObjectSize int64
+	// RemainingBytes is the number of bytes remaining in the storage quota
+	RemainingBytes int64
+}
+
+// Manager enforces per-token upload quotas for a DCAS collection. The usage of each token is persisted in an
+// off-ledger collection of the DCAS chaincode so that it survives peer restarts and is shared by all peers that
+// are members of the collection (typically the peers of one org).
+//
+// The number of uploads is counted when the upload is reserved, so the rate limit can't be exceeded by concurrent
+// uploads on the same peer. The size of an upload is only known once it completes, however, so concurrent uploads with
+// the same token are checked against the same storage usage and the storage quota may be exceeded by the uploads that
+// are in progress at the time the quota is reached.
+//
+// The mutex only serializes the updates to the usage on this peer. The usage is read, modified and written back to
+// the off-ledger collection (which doesn't detect conflicting writes), so if uploads with the same token are handled
+// concurrently by more than one peer then an update from one peer may overwrite an update from another peer and the
+// usage is undercounted. The quotas are therefore only enforced exactly if the uploads for a token are handled by a
+// single peer.
+type Manager struct {
+	channelID       string
+	namespace       string
+	collection      string
+	usageCollection string
+	quotas          map[string]Quota
+	olProvider      common.OffLedgerClientProvider
+	now             func() time.Time
+
+	mutex sync.Mutex
+}
+
+// New returns a new quota manager for the given DCAS collection. The usage is persisted in usageCollection, which
+// must be an off-ledger collection of the given namespace (chaincode).
+func New(channelID, namespace, collection, usageCollection string, quotas map[string]Quota, olProvider common.OffLedgerClientProvider) *Manager {
+	return &Manager{
+		channelID:       channelID,
+		namespace:       namespace,
+		collection:      collection,
+		usageCollection: usageCollection,
+		quotas:          quotas,
+		olProvider:      olProvider,
+		now:             time.Now,
+	}
+}
+
+// Reserve checks the quota of the given token before an upload and returns the limits that apply to the upload.
+// ErrQuotaExceeded is returned if the storage quota is used up and ErrRateLimitExceeded is returned if the maximum
+// number of uploads in the current hour has been reached. Otherwise the upload is counted in the current rate-limiting
+// window. If no quota is configured for the token then the upload is not limited.
+func (m *Manager) Reserve(token string) (*Allowance, error) {
+	quota, ok := m.quotas[token]
+	if !ok {
+		return &Allowance{}, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	usage, err := m.load(token, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	if quota.MaxObjectsPerHour > 0 && usage.WindowObjects >= quota.MaxObjectsPerHour {
+		logger.Debugf("[%s] Upload rate limit of %d objects per hour exceeded for token [%s]", m.channelID, quota.MaxObjectsPerHour, token)
+
+		return nil, ErrRateLimitExceeded
+	}
+
+	allowance := &Allowance{MaxObjectSize: quota.MaxObjectSize}
+
+	if quota.MaxBytes > 0 {
+		allowance.RemainingBytes = quota.MaxBytes - usage.Bytes
+
+		if allowance.RemainingBytes <= 0 {
+			logger.Debugf("[%s] Storage quota of %d bytes exceeded for token [%s]", m.channelID, quota.MaxBytes, token)
+
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	usage.WindowObjects++
+
+	if err := m.store(usage); err != nil {
+		return nil, err
+	}
+
+	return allowance, nil
+}
+
+// Record adds the size of a completed upload (which was previously reserved) to the usage of the given token
+func (m *Manager) Record(token string, size int64) error {
+	quota, ok := m.quotas[token]
+	if !ok {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	usage, err := m.load(token, quota)
+	if err != nil {
+		return err
+	}
+
+	usage.Bytes += size
+	usage.Objects++
+
+	return m.store(usage)
+}
+
+// Usage returns the usage of the given token. ErrNoQuota is returned if no quota is configured for the token.
+func (m *Manager) Usage(token string) (*Usage, error) {
+	quota, ok := m.quotas[token]
+	if !ok {
+		return nil, ErrNoQuota
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.load(token, quota)
+}
+
+// RetryAfter returns the duration until the start of the next rate-limiting window
+func (m *Manager) RetryAfter() time.Duration {
+	now := m.now()
+
+	return now.Truncate(rateWindow).Add(rateWindow).Sub(now)
+}
+
+func (m *Manager) load(token string, quota Quota) (*Usage, error) {
+	olClient, err := m.olProvider.ForChannel(m.channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get off-ledger client")
+	}
+
+	usageBytes, err := olClient.Get(m.namespace, m.usageCollection, m.key(token))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to load usage for token [%s]", token)
+	}
+
+	usage := &Usage{}
+
+	if len(usageBytes) > 0 {
+		if err := json.Unmarshal(usageBytes, usage); err != nil {
+			return nil, errors.WithMessagef(err, "failed to unmarshal usage for token [%s]", token)
+		}
+	}
+
+	usage.Token = token
+	usage.Quota = quota
+
+	windowStart := m.now().Truncate(rateWindow)
+	if !usage.WindowStart.Equal(windowStart) {
+		usage.WindowStart = windowStart
+		usage.WindowObjects = 0
+	}
+
+	return usage, nil
+}
+
+func (m *Manager) store(usage *Usage) error {
+	olClient, err := m.olProvider.ForChannel(m.channelID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get off-ledger client")
+	}
+
+	usageBytes, err := json.Marshal(usage)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to marshal usage for token [%s]", usage.Token)
+	}
+
+	if err := olClient.Put(m.namespace, m.usageCollection, m.key(usage.Token), usageBytes); err != nil {
+		return errors.WithMessagef(err, "failed to store usage for token [%s]", usage.Token)
+	}
+
+	logger.Debugf("[%s] Updated usage for token [%s] - Bytes: %d, Objects: %d, Objects in current hour: %d",
+		m.channelID, usage.Token, usage.Bytes, usage.Objects, usage.WindowObjects)
+
+	return nil
+}
+
+// key returns the key of the usage for the given token. The key includes the DCAS collection so that the
+// same usage collection may be used for more than one DCAS collection.
+func (m *Manager) key(token string) string {
+	return m.collection + "_" + token
+}
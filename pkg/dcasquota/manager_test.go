@@ -0,0 +1,207 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcasquota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+)
+
+const (
+	channel1   = "channel1"
+	ccName     = "document_cc"
+	collection = "dcas"
+	usageColl  = "usage"
+
+	token1 = "token1"
+	token2 = "token2"
+	token3 = "token3"
+)
+
+var quotas = map[string]Quota{
+	token1: {MaxBytes: 100, MaxObjectSize: 60},
+	token2: {MaxObjectsPerHour: 2},
+}
+
+func TestManager_Reserve(t *testing.T) {
+	olProvider := &obmocks.OffLedgerClientProvider{}
+	olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+	t.Run("No quota", func(t *testing.T) {
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+
+		allowance, err := m.Reserve(token3)
+		require.NoError(t, err)
+		require.Equal(t, &Allowance{}, allowance)
+
+		require.NoError(t, m.Record(token3, 1000))
+	})
+
+	t.Run("Storage quota", func(t *testing.T) {
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+
+		allowance, err := m.Reserve(token1)
+		require.NoError(t, err)
+		require.Equal(t, int64(60), allowance.MaxObjectSize)
+		require.Equal(t, int64(100), allowance.RemainingBytes)
+
+		require.NoError(t, m.Record(token1, 60))
+
+		allowance, err = m.Reserve(token1)
+		require.NoError(t, err)
+		require.Equal(t, int64(40), allowance.RemainingBytes)
+
+		require.NoError(t, m.Record(token1, 40))
+
+		allowance, err = m.Reserve(token1)
+		require.Equal(t, ErrQuotaExceeded, err)
+		require.Nil(t, allowance)
+
+		usage, err := m.Usage(token1)
+		require.NoError(t, err)
+		require.Equal(t, token1, usage.Token)
+		require.Equal(t, quotas[token1], usage.Quota)
+		require.Equal(t, int64(100), usage.Bytes)
+		require.Equal(t, int64(2), usage.Objects)
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		now := time.Date(2020, 5, 1, 10, 15, 0, 0, time.UTC)
+
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+		m.now = func() time.Time { return now }
+
+		require.Equal(t, 45*time.Minute, m.RetryAfter())
+
+		for i := 0; i < 2; i++ {
+			_, err := m.Reserve(token2)
+			require.NoError(t, err)
+			require.NoError(t, m.Record(token2, 10))
+		}
+
+		_, err := m.Reserve(token2)
+		require.Equal(t, ErrRateLimitExceeded, err)
+
+		now = now.Add(time.Hour)
+
+		allowance, err := m.Reserve(token2)
+		require.NoError(t, err)
+		require.Equal(t, &Allowance{}, allowance)
+
+		usage, err := m.Usage(token2)
+		require.NoError(t, err)
+		require.Equal(t, int64(20), usage.Bytes)
+		require.Equal(t, int64(2), usage.Objects)
+		require.Equal(t, 1, usage.WindowObjects)
+		require.Equal(t, now.Truncate(time.Hour), usage.WindowStart)
+	})
+
+	t.Run("Rate limit with uploads in progress", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+
+		// The uploads are counted when they're reserved, so the limit applies before the uploads are recorded
+		for i := 0; i < 2; i++ {
+			_, err := m.Reserve(token2)
+			require.NoError(t, err)
+		}
+
+		_, err := m.Reserve(token2)
+		require.Equal(t, ErrRateLimitExceeded, err)
+
+		usage, err := m.Usage(token2)
+		require.NoError(t, err)
+		require.Zero(t, usage.Objects)
+		require.Equal(t, 2, usage.WindowObjects)
+	})
+
+	t.Run("Usage persisted", func(t *testing.T) {
+		usage, err := New(channel1, ccName, collection, usageColl, quotas, olProvider).Usage(token1)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), usage.Bytes)
+
+		usage, err = New(channel1, ccName, "dcas2", usageColl, quotas, olProvider).Usage(token1)
+		require.NoError(t, err)
+		require.Zero(t, usage.Bytes)
+	})
+
+	t.Run("No quota for usage", func(t *testing.T) {
+		usage, err := New(channel1, ccName, collection, usageColl, quotas, olProvider).Usage(token3)
+		require.Equal(t, ErrNoQuota, err)
+		require.Nil(t, usage)
+	})
+}
+
+func TestManager_Error(t *testing.T) {
+	t.Run("Off-ledger provider error", func(t *testing.T) {
+		errExpected := errors.New("injected off-ledger provider error")
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(nil, errExpected)
+
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+
+		_, err := m.Reserve(token1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		err = m.Record(token1, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		_, err = m.Usage(token1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		errExpected := errors.New("injected get error")
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithGetError(errExpected), nil)
+
+		_, err := New(channel1, ccName, collection, usageColl, quotas, olProvider).Reserve(token1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Put error", func(t *testing.T) {
+		errExpected := errors.New("injected put error")
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithPutError(errExpected), nil)
+
+		m := New(channel1, ccName, collection, usageColl, quotas, olProvider)
+
+		_, err := m.Reserve(token1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		err = m.Record(token1, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		olClient := obmocks.NewMockOffLedgerClient()
+		require.NoError(t, olClient.Put(ccName, usageColl, collection+"_"+token1, []byte("{")))
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(olClient, nil)
+
+		_, err := New(channel1, ccName, collection, usageColl, quotas, olProvider).Usage(token1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal usage")
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// ETagHeader is the name of the ETag header field
+	ETagHeader = "ETag"
+	// CacheControlHeader is the name of the Cache-Control header field
+	CacheControlHeader = "Cache-Control"
+	// IfNoneMatchHeader is the name of the If-None-Match header field
+	IfNoneMatchHeader = "If-None-Match"
+
+	// CacheControlImmutable is used for content that never changes for a given URL (e.g. content addressed by hash).
+	// The content may be cached for up to a year without revalidation.
+	CacheControlImmutable = "public, max-age=31536000, immutable"
+	// CacheControlRevalidate is used for content that may change for a given URL. The content may be cached
+	// but must be revalidated (using the ETag) before it's used.
+	CacheControlRevalidate = "no-cache"
+)
+
+// ETag returns a strong entity tag for the given content ID
+func ETag(id string) string {
+	return `"` + id + `"`
+}
+
+// IfNoneMatch returns true if the If-None-Match header in the request matches the given entity tag (or is "*"), in
+// which case the client's cached copy is still valid. Since "*" matches any current representation of the resource,
+// IfNoneMatch must only be called after the existence of the resource has been checked.
+func IfNoneMatch(req *http.Request, etag string) bool {
+	return ifNoneMatch(req, etag, true)
+}
+
+// IfNoneMatchETag returns true if the If-None-Match header in the request contains the given entity tag. Unlike
+// IfNoneMatch, "*" isn't a match, so IfNoneMatchETag may be called before the existence of the resource is checked.
+func IfNoneMatchETag(req *http.Request, etag string) bool {
+	return ifNoneMatch(req, etag, false)
+}
+
+func ifNoneMatch(req *http.Request, etag string, matchAny bool) bool {
+	header := req.Header.Get(IfNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			if matchAny {
+				return true
+			}
+
+			continue
+		}
+
+		// If-None-Match uses the weak comparison function so a weak tag matches the strong tag
+		if strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteNotModified writes a 304 (Not Modified) response along with the given caching headers
+func (rw *ResponseWriter) WriteNotModified(etag, cacheControl string) {
+	rw.setCacheHeaders(etag, cacheControl)
+	rw.WriteHeader(http.StatusNotModified)
+}
+
+// WriteContent writes the content from the given reader along with the given caching headers. Conditional
+// requests (If-None-Match) and Range requests are supported.
+func (rw *ResponseWriter) WriteContent(req *http.Request, content io.ReadSeeker, contentType, etag, cacheControl string) {
+	rw.Header().Set(ContentTypeHeader, contentType)
+	rw.setCacheHeaders(etag, cacheControl)
+
+	logger.Debugf("Writing content - ETag: %s, Range: %s", etag, req.Header.Get("Range"))
+
+	http.ServeContent(rw.ResponseWriter, req, "", time.Time{}, content)
+}
+
+func (rw *ResponseWriter) setCacheHeaders(etag, cacheControl string) {
+	rw.Header().Set(ETagHeader, etag)
+	rw.Header().Set(CacheControlHeader, cacheControl)
+}
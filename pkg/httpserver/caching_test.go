@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	require.Equal(t, `"abc"`, ETag("abc"))
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	etag := ETag("abc")
+
+	req := httptest.NewRequest(http.MethodGet, "/content", nil)
+	require.False(t, IfNoneMatch(req, etag))
+
+	for _, header := range []string{`"abc"`, `W/"abc"`, `"xyz", "abc"`, `*`} {
+		req.Header.Set(IfNoneMatchHeader, header)
+		require.Truef(t, IfNoneMatch(req, etag), "expecting [%s] to match", header)
+	}
+
+	req.Header.Set(IfNoneMatchHeader, `"xyz"`)
+	require.False(t, IfNoneMatch(req, etag))
+}
+
+func TestIfNoneMatchETag(t *testing.T) {
+	etag := ETag("abc")
+
+	req := httptest.NewRequest(http.MethodGet, "/content", nil)
+	require.False(t, IfNoneMatchETag(req, etag))
+
+	for _, header := range []string{`"abc"`, `W/"abc"`, `*, "abc"`} {
+		req.Header.Set(IfNoneMatchHeader, header)
+		require.Truef(t, IfNoneMatchETag(req, etag), "expecting [%s] to match", header)
+	}
+
+	for _, header := range []string{`"xyz"`, `*`} {
+		req.Header.Set(IfNoneMatchHeader, header)
+		require.Falsef(t, IfNoneMatchETag(req, etag), "expecting [%s] not to match", header)
+	}
+}
+
+func TestResponseWriter_WriteNotModified(t *testing.T) {
+	r := httptest.NewRecorder()
+	NewResponseWriter(r).WriteNotModified(ETag("abc"), CacheControlImmutable)
+
+	require.Equal(t, http.StatusNotModified, r.Code)
+	require.Equal(t, `"abc"`, r.Header().Get(ETagHeader))
+	require.Equal(t, CacheControlImmutable, r.Header().Get(CacheControlHeader))
+	require.Empty(t, r.Body.String())
+}
+
+func TestResponseWriter_WriteContent(t *testing.T) {
+	const content = "0123456789"
+
+	etag := ETag("abc")
+
+	t.Run("Full content", func(t *testing.T) {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/content", nil)
+
+		NewResponseWriter(r).WriteContent(req, strings.NewReader(content), ContentTypeText, etag, CacheControlRevalidate)
+
+		require.Equal(t, http.StatusOK, r.Code)
+		require.Equal(t, content, r.Body.String())
+		require.Equal(t, ContentTypeText, r.Header().Get(ContentTypeHeader))
+		require.Equal(t, etag, r.Header().Get(ETagHeader))
+		require.Equal(t, CacheControlRevalidate, r.Header().Get(CacheControlHeader))
+		require.Equal(t, "bytes", r.Header().Get("Accept-Ranges"))
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/content", nil)
+		req.Header.Set(IfNoneMatchHeader, etag)
+
+		NewResponseWriter(r).WriteContent(req, strings.NewReader(content), ContentTypeText, etag, CacheControlRevalidate)
+
+		require.Equal(t, http.StatusNotModified, r.Code)
+		require.Empty(t, r.Body.String())
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/content", nil)
+		req.Header.Set("Range", "bytes=2-5")
+
+		NewResponseWriter(r).WriteContent(req, strings.NewReader(content), ContentTypeText, etag, CacheControlRevalidate)
+
+		require.Equal(t, http.StatusPartialContent, r.Code)
+		require.Equal(t, "2345", r.Body.String())
+		require.Equal(t, "bytes 2-5/10", r.Header().Get("Content-Range"))
+	})
+
+	t.Run("Range not satisfiable", func(t *testing.T) {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/content", nil)
+		req.Header.Set("Range", "bytes=20-30")
+
+		NewResponseWriter(r).WriteContent(req, strings.NewReader(content), ContentTypeText, etag, CacheControlRevalidate)
+
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, r.Code)
+	})
+
+	t.Run("If-Range with stale ETag", func(t *testing.T) {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/content", nil)
+		req.Header.Set("Range", "bytes=2-5")
+		req.Header.Set("If-Range", ETag("xyz"))
+
+		NewResponseWriter(r).WriteContent(req, strings.NewReader(content), ContentTypeText, etag, CacheControlRevalidate)
+
+		require.Equal(t, http.StatusOK, r.Code)
+		require.Equal(t, content, r.Body.String())
+	})
+}
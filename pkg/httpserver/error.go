@@ -22,6 +22,14 @@ const (
 	StatusNotFound StatusMsg = "not-found"
 	// StatusServerError indicates that the server experienced an unexpected error
 	StatusServerError StatusMsg = "server-error"
+	// StatusContentTooLarge indicates that the uploaded content exceeds the maximum allowed size
+	StatusContentTooLarge StatusMsg = "content-too-large"
+	// StatusCIDMismatch indicates that the CID of the uploaded content doesn't match the CID expected by the client
+	StatusCIDMismatch StatusMsg = "cid-mismatch"
+	// StatusQuotaExceeded indicates that the upload would exceed the storage quota of the caller
+	StatusQuotaExceeded StatusMsg = "quota-exceeded"
+	// StatusRateLimitExceeded indicates that the caller has exceeded the maximum number of uploads in the current period
+	StatusRateLimitExceeded StatusMsg = "rate-limit-exceeded"
 )
 
 var (
@@ -31,6 +39,16 @@ var (
 	NotFoundError = NewError(http.StatusNotFound, StatusNotFound)
 	// BadRequestError indicates that the request is invalid
 	BadRequestError = NewError(http.StatusBadRequest, StatusBadRequest)
+	// EmptyContentError indicates that no content was uploaded
+	EmptyContentError = NewError(http.StatusBadRequest, StatusEmptyContent)
+	// ContentTooLargeError indicates that the uploaded content exceeds the maximum allowed size
+	ContentTooLargeError = NewError(http.StatusRequestEntityTooLarge, StatusContentTooLarge)
+	// CIDMismatchError indicates that the CID of the uploaded content doesn't match the CID expected by the client
+	CIDMismatchError = NewError(http.StatusBadRequest, StatusCIDMismatch)
+	// QuotaExceededError indicates that the upload would exceed the storage quota of the caller
+	QuotaExceededError = NewError(http.StatusRequestEntityTooLarge, StatusQuotaExceeded)
+	// RateLimitExceededError indicates that the caller has exceeded the maximum number of uploads in the current period
+	RateLimitExceededError = NewError(http.StatusTooManyRequests, StatusRateLimitExceeded)
 )
 
 // Error holds additional context associated with the HTTP request
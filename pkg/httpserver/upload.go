@@ -0,0 +1,259 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+)
+
+const (
+	// ExpectedCIDHeader is the name of the header in which a client may provide the CID that it expects the
+	// uploaded content to have. If the CID computed by the server doesn't match then the upload fails.
+	ExpectedCIDHeader = "X-Expected-CID"
+
+	// ContentCIDHeader is the name of the header that contains the CID of the uploaded content (computed in the
+	// same way as the CID that's compared with the expected CID). It's returned by handlers that store the content
+	// in a wrapper (whose CID is returned) so that the client may match the upload with its expected CID.
+	ContentCIDHeader = "X-Content-CID"
+
+	// ContentSizeHeader is the name of the header (or trailer, if the content is streamed) that contains the
+	// size of the uploaded or downloaded content in bytes
+	ContentSizeHeader = "X-Content-Size"
+
+	// ContentTypeMultipartForm is the multipart form content-type
+	ContentTypeMultipartForm = "multipart/form-data"
+)
+
+// UploadedContent contains the content (and content type) read from an upload request
+type UploadedContent struct {
+	ContentType string
+	Content     []byte
+}
+
+// UploadReader streams the uploaded content of a request. If a maximum size was specified then the reader returns
+// ContentTooLargeError as soon as the content exceeds the maximum size, so the upload may be aborted early.
+type UploadReader struct {
+	reader      *bufio.Reader
+	contentType string
+	maxSize     int64
+	size        int64
+	exceeded    bool
+}
+
+// ContentType returns the content type of the uploaded content
+func (r *UploadReader) ContentType() string {
+	return r.contentType
+}
+
+// Size returns the number of bytes read so far
+func (r *UploadReader) Size() int64 {
+	return r.size
+}
+
+// SizeExceeded returns true if the content exceeded the maximum size
+func (r *UploadReader) SizeExceeded() bool {
+	return r.exceeded
+}
+
+// Read reads the next bytes of the uploaded content
+func (r *UploadReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+
+	if r.maxSize > 0 && r.size > r.maxSize {
+		logger.Debugf("Uploaded content exceeds the maximum size of %d bytes", r.maxSize)
+
+		r.exceeded = true
+
+		return n, ContentTooLargeError
+	}
+
+	return n, err
+}
+
+// MediaType returns the media type of the request (i.e. the Content-Type header without parameters) or
+// an empty string if the Content-Type header isn't set or is invalid
+func MediaType(req *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get(ContentTypeHeader))
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+// OpenUpload returns a reader for the uploaded content of the request. If the request is a multipart form then the
+// content and content type are taken from the first file in the form. Otherwise the request body is the content and
+// the Content-Type header is the content type. EmptyContentError is returned if there's no content. If maxSize is
+// greater than 0 then the reader fails once more than maxSize bytes have been read.
+func OpenUpload(req *http.Request, maxSize int64) (*UploadReader, error) {
+	var body io.Reader = req.Body
+	contentType := req.Header.Get(ContentTypeHeader)
+
+	if MediaType(req) == ContentTypeMultipartForm {
+		part, err := nextFilePart(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body = part
+		contentType = part.Header.Get(ContentTypeHeader)
+	}
+
+	if body == nil {
+		return nil, EmptyContentError
+	}
+
+	r := &UploadReader{
+		reader:      bufio.NewReader(body),
+		contentType: contentType,
+		maxSize:     maxSize,
+	}
+
+	if _, err := r.reader.Peek(1); err != nil {
+		if err == io.EOF {
+			return nil, EmptyContentError
+		}
+
+		logger.Debugf("Error reading uploaded content: %s", err)
+
+		return nil, BadRequestError
+	}
+
+	return r, nil
+}
+
+// ReadUpload reads all of the uploaded content from the request (see OpenUpload). If maxSize is greater than 0
+// and the content exceeds maxSize bytes then ContentTooLargeError is returned.
+func ReadUpload(req *http.Request, maxSize int64) (*UploadedContent, error) {
+	r, err := OpenUpload(req, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		if r.SizeExceeded() {
+			return nil, ContentTooLargeError
+		}
+
+		logger.Debugf("Error reading uploaded content: %s", err)
+
+		return nil, BadRequestError
+	}
+
+	return &UploadedContent{
+		ContentType: r.ContentType(),
+		Content:     content,
+	}, nil
+}
+
+// VerifyCID returns CIDMismatchError if the client provided an expected CID in the request and it doesn't match
+// the CID of the given content. The CID is computed in the same way that DCAS computes the CID of raw content
+// (a CIDv1 with the raw codec and a SHA2-256 multihash) so that the content may be verified before it's stored.
+func VerifyCID(req *http.Request, content []byte) error {
+	if req.Header.Get(ExpectedCIDHeader) == "" {
+		return nil
+	}
+
+	cID, err := ContentCID(content)
+	if err != nil {
+		logger.Errorf("Error computing CID of uploaded content: %s", err)
+
+		return ServerError
+	}
+
+	return MatchCID(req, cID)
+}
+
+// ContentCID returns the CID of the given uploaded content, i.e. a CIDv1 with the raw codec and a SHA2-256 multihash
+func ContentCID(content []byte) (string, error) {
+	return dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+}
+
+// MatchCID returns CIDMismatchError if the client provided an expected CID in the request and it doesn't match
+// the given CID of the uploaded content (see ContentCID)
+func MatchCID(req *http.Request, cID string) error {
+	expected := req.Header.Get(ExpectedCIDHeader)
+	if expected == "" {
+		return nil
+	}
+
+	if cID != expected {
+		logger.Debugf("CID of uploaded content [%s] does not match expected CID [%s]", cID, expected)
+
+		return CIDMismatchError
+	}
+
+	return nil
+}
+
+// LimitBody returns a reader for the request body that fails with ContentTooLargeError once more than maxSize
+// bytes have been read. (The request body is wrapped with http.MaxBytesReader so that the server closes the
+// connection rather than reading the rest of the body.)
+func LimitBody(rw http.ResponseWriter, req *http.Request, maxSize int64) io.Reader {
+	return &limitedBody{
+		reader:  http.MaxBytesReader(rw, req.Body, maxSize),
+		maxSize: maxSize,
+	}
+}
+
+type limitedBody struct {
+	reader  io.Reader
+	maxSize int64
+	size    int64
+}
+
+func (r *limitedBody) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+
+	if err != nil && err != io.EOF && r.size >= r.maxSize {
+		logger.Debugf("Request body exceeds the maximum size of %d bytes", r.maxSize)
+
+		return n, ContentTooLargeError
+	}
+
+	return n, err
+}
+
+func nextFilePart(req *http.Request) (*multipart.Part, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		logger.Debugf("Invalid multipart request: %s", err)
+
+		return nil, BadRequestError
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			logger.Debugf("No file found in multipart request")
+
+			return nil, EmptyContentError
+		}
+
+		if err != nil {
+			logger.Debugf("Error reading multipart request: %s", err)
+
+			return nil, BadRequestError
+		}
+
+		if part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
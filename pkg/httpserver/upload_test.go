@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+)
+
+func TestReadUpload(t *testing.T) {
+	t.Run("Raw body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("some content"))
+		req.Header.Set(ContentTypeHeader, "text/plain; charset=utf-8")
+
+		uploaded, err := ReadUpload(req, 0)
+		require.NoError(t, err)
+		require.Equal(t, "text/plain; charset=utf-8", uploaded.ContentType)
+		require.Equal(t, "some content", string(uploaded.Content))
+	})
+
+	t.Run("Empty body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader(""))
+
+		_, err := ReadUpload(req, 0)
+		require.Equal(t, EmptyContentError, err)
+	})
+
+	t.Run("Max size", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("some content"))
+
+		uploaded, err := ReadUpload(req, 12)
+		require.NoError(t, err)
+		require.Equal(t, "some content", string(uploaded.Content))
+
+		req = httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("some content"))
+
+		_, err = ReadUpload(req, 11)
+		require.Equal(t, ContentTooLargeError, err)
+	})
+
+	t.Run("Multipart form", func(t *testing.T) {
+		req := newMultipartRequest(t, "image/png", []byte{1, 2, 3})
+
+		uploaded, err := ReadUpload(req, 0)
+		require.NoError(t, err)
+		require.Equal(t, "image/png", uploaded.ContentType)
+		require.Equal(t, []byte{1, 2, 3}, uploaded.Content)
+
+		_, err = ReadUpload(newMultipartRequest(t, "image/png", []byte{1, 2, 3}), 2)
+		require.Equal(t, ContentTooLargeError, err)
+	})
+
+	t.Run("Multipart form with no file", func(t *testing.T) {
+		body := bytes.NewBuffer(nil)
+		w := multipart.NewWriter(body)
+		require.NoError(t, w.WriteField("name", "value"))
+		require.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/files", body)
+		req.Header.Set(ContentTypeHeader, w.FormDataContentType())
+
+		_, err := ReadUpload(req, 0)
+		require.Equal(t, EmptyContentError, err)
+	})
+
+	t.Run("Invalid multipart form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("xxx"))
+		req.Header.Set(ContentTypeHeader, ContentTypeMultipartForm)
+
+		_, err := ReadUpload(req, 0)
+		require.Equal(t, BadRequestError, err)
+
+		req = httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("xxx"))
+		req.Header.Set(ContentTypeHeader, ContentTypeMultipartForm+"; boundary=1234")
+
+		_, err = ReadUpload(req, 0)
+		require.Equal(t, BadRequestError, err)
+	})
+}
+
+func TestMediaType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/files", nil)
+	require.Empty(t, MediaType(req))
+
+	req.Header.Set(ContentTypeHeader, "application/json; charset=utf-8")
+	require.Equal(t, ContentTypeJSON, MediaType(req))
+
+	req.Header.Set(ContentTypeHeader, ";;")
+	require.Empty(t, MediaType(req))
+}
+
+func TestVerifyCID(t *testing.T) {
+	content := []byte("some content")
+
+	cID, err := dcas.GetCID(content, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/files", nil)
+	require.NoError(t, VerifyCID(req, content))
+
+	req.Header.Set(ExpectedCIDHeader, cID)
+	require.NoError(t, VerifyCID(req, content))
+	require.Equal(t, CIDMismatchError, VerifyCID(req, []byte("other content")))
+
+	contentCID, err := ContentCID(content)
+	require.NoError(t, err)
+	require.Equal(t, cID, contentCID)
+	require.NoError(t, MatchCID(req, contentCID))
+	require.Equal(t, CIDMismatchError, MatchCID(req, "other-cid"))
+}
+
+func TestLimitBody(t *testing.T) {
+	t.Run("Within limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("some content"))
+
+		content, err := ioutil.ReadAll(LimitBody(httptest.NewRecorder(), req, 12))
+		require.NoError(t, err)
+		require.Equal(t, "some content", string(content))
+	})
+
+	t.Run("Limit exceeded", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/files", strings.NewReader("some content"))
+
+		_, err := ioutil.ReadAll(LimitBody(httptest.NewRecorder(), req, 11))
+		require.Equal(t, ContentTooLargeError, err)
+	})
+}
+
+func newMultipartRequest(t *testing.T, contentType string, content []byte) *http.Request {
+	body := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(body)
+
+	require.NoError(t, w.WriteField("description", "some file"))
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="file1"`)
+	header.Set(ContentTypeHeader, contentType)
+
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/files", body)
+	req.Header.Set(ContentTypeHeader, w.FormDataContentType())
+
+	return req
+}
@@ -125,6 +125,7 @@ func (m *MockDCASClient) Get(cid string, w io.Writer) error {
 }
 
 // GetNode retrieves the CAS Node for the given content ID. A node contains data and/or links to other nodes.
+// Data that was added without a node is returned as a node without links.
 func (m *MockDCASClient) GetNode(cid string) (*dcasclient.Node, error) {
 	if m.errGetNode != nil {
 		return nil, m.errGetNode
@@ -134,5 +135,13 @@ func (m *MockDCASClient) GetNode(cid string) (*dcasclient.Node, error) {
 		panic(m.panicMessage)
 	}
 
-	return m.nodes[cid], nil
+	if node, ok := m.nodes[cid]; ok {
+		return node, nil
+	}
+
+	if value, ok := m.data[cid]; ok {
+		return &dcasclient.Node{Data: value}, nil
+	}
+
+	return nil, nil
 }
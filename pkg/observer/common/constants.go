@@ -9,6 +9,9 @@ package common
 const (
 	// AnchorPrefix is the prefix that is that is used to persist anchors
 	AnchorPrefix = "sidetreeanchor_"
+
+	// AnchorEventName is the name of the chaincode event that is emitted when anchors are written
+	AnchorEventName = "sidetreeanchor"
 )
 
 // TxnInfo contains info that gets recorded on blockchain as part of Sidetree transaction
@@ -18,3 +21,9 @@ type TxnInfo struct {
 	// ProtocolGenesisTime is the genesis time of the protocol that was used to create the anchor
 	ProtocolGenesisTime uint64 `json:"protocolGenesisTime"`
 }
+
+// AnchorEvent is the payload of the chaincode event that is emitted when anchors are written. Since only one
+// chaincode event may be emitted per transaction, the event contains all of the anchors written in the transaction.
+type AnchorEvent struct {
+	Anchors []TxnInfo `json:"anchors"`
+}
@@ -46,6 +46,19 @@ type BlockchainClient struct {
 		result1 *cb.Block
 		result2 error
 	}
+	GetBlockByTxIDStub        func(txID string) (*cb.Block, error)
+	getBlockByTxIDMutex       sync.RWMutex
+	getBlockByTxIDArgsForCall []struct {
+		txID string
+	}
+	getBlockByTxIDReturns struct {
+		result1 *cb.Block
+		result2 error
+	}
+	getBlockByTxIDReturnsOnCall map[int]struct {
+		result1 *cb.Block
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -200,6 +213,57 @@ func (fake *BlockchainClient) GetBlockByHashReturnsOnCall(i int, result1 *cb.Blo
 	}{result1, result2}
 }
 
+func (fake *BlockchainClient) GetBlockByTxID(txID string) (*cb.Block, error) {
+	fake.getBlockByTxIDMutex.Lock()
+	ret, specificReturn := fake.getBlockByTxIDReturnsOnCall[len(fake.getBlockByTxIDArgsForCall)]
+	fake.getBlockByTxIDArgsForCall = append(fake.getBlockByTxIDArgsForCall, struct {
+		txID string
+	}{txID})
+	fake.recordInvocation("GetBlockByTxID", []interface{}{txID})
+	fake.getBlockByTxIDMutex.Unlock()
+	if fake.GetBlockByTxIDStub != nil {
+		return fake.GetBlockByTxIDStub(txID)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getBlockByTxIDReturns.result1, fake.getBlockByTxIDReturns.result2
+}
+
+func (fake *BlockchainClient) GetBlockByTxIDCallCount() int {
+	fake.getBlockByTxIDMutex.RLock()
+	defer fake.getBlockByTxIDMutex.RUnlock()
+	return len(fake.getBlockByTxIDArgsForCall)
+}
+
+func (fake *BlockchainClient) GetBlockByTxIDArgsForCall(i int) string {
+	fake.getBlockByTxIDMutex.RLock()
+	defer fake.getBlockByTxIDMutex.RUnlock()
+	return fake.getBlockByTxIDArgsForCall[i].txID
+}
+
+func (fake *BlockchainClient) GetBlockByTxIDReturns(result1 *cb.Block, result2 error) {
+	fake.GetBlockByTxIDStub = nil
+	fake.getBlockByTxIDReturns = struct {
+		result1 *cb.Block
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *BlockchainClient) GetBlockByTxIDReturnsOnCall(i int, result1 *cb.Block, result2 error) {
+	fake.GetBlockByTxIDStub = nil
+	if fake.getBlockByTxIDReturnsOnCall == nil {
+		fake.getBlockByTxIDReturnsOnCall = make(map[int]struct {
+			result1 *cb.Block
+			result2 error
+		})
+	}
+	fake.getBlockByTxIDReturnsOnCall[i] = struct {
+		result1 *cb.Block
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *BlockchainClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -209,6 +273,8 @@ func (fake *BlockchainClient) Invocations() map[string][][]interface{} {
 	defer fake.getBlockByNumberMutex.RUnlock()
 	fake.getBlockByHashMutex.RLock()
 	defer fake.getBlockByHashMutex.RUnlock()
+	fake.getBlockByTxIDMutex.RLock()
+	defer fake.getBlockByTxIDMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
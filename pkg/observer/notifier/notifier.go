@@ -8,8 +8,10 @@ package notifier
 
 import (
 	"strings"
+	"sync/atomic"
 
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric/common/flogging"
 	gossipapi "github.com/hyperledger/fabric/extensions/gossip/api"
 
@@ -22,17 +24,22 @@ var logger = flogging.MustGetLogger("sidetree_observer")
 type blockPublisher interface {
 	// AddWriteHandler adds a handler for KV writes
 	AddWriteHandler(handler gossipapi.WriteHandler)
+	// AddCCEventHandler adds a handler for chaincode events
+	AddCCEventHandler(handler gossipapi.ChaincodeEventHandler)
 }
 
 type blockPublisherProvider interface {
 	ForChannel(channelID string) gossipapi.BlockPublisher
 }
 
-// Notifier receives anchor string 'write' events and sends them to a Go channel.
+// Notifier receives anchor string 'write' events and sends them to a Go channel. By default, anchor writes are
+// detected by inspecting KV writes. If chaincode events are enabled then anchor writes are detected from the
+// chaincode events emitted by the txn chaincode.
 type Notifier struct {
 	channelID string
 	publisher blockPublisher
 	txnChan   chan<- gossipapi.TxMetadata
+	txnCCName atomic.Value
 }
 
 // New return new instance of Notifier
@@ -44,19 +51,72 @@ func New(channelID string, bpProvider blockPublisherProvider, txnChan chan<- gos
 	}
 
 	n.publisher.AddWriteHandler(n.handleWrite)
+	n.publisher.AddCCEventHandler(n.handleCCEvent)
 
 	logger.Infof("[%s] Started notifier", channelID)
 
 	return n
 }
 
+// UseChaincodeEvents enables or disables the use of chaincode events for detecting anchor writes. When enabled,
+// only the events emitted by the given txn chaincode are considered.
+func (n *Notifier) UseChaincodeEvents(enabled bool, txnCCName string) {
+	if !enabled {
+		txnCCName = ""
+	}
+
+	logger.Debugf("[%s] Setting use chaincode events to %t - txn chaincode [%s]", n.channelID, enabled, txnCCName)
+
+	n.txnCCName.Store(txnCCName)
+}
+
+// chaincodeEventsFrom returns the name of the txn chaincode whose events are used to detect anchor writes
+// or an empty string if chaincode events are disabled
+func (n *Notifier) chaincodeEventsFrom() string {
+	name, ok := n.txnCCName.Load().(string)
+	if !ok {
+		return ""
+	}
+
+	return name
+}
+
+func (n *Notifier) usingCCEvents() bool {
+	return n.chaincodeEventsFrom() != ""
+}
+
 func (n *Notifier) handleWrite(txMetadata gossipapi.TxMetadata, namespace string, kvWrite *kvrwset.KVWrite) error {
-	if kvWrite.IsDelete || !strings.HasPrefix(kvWrite.Key, common.AnchorPrefix) {
+	if n.usingCCEvents() || kvWrite.IsDelete || !strings.HasPrefix(kvWrite.Key, common.AnchorPrefix) {
 		return nil
 	}
 
 	logger.Debugf("[%s] Found anchor string key[%s], value [%s] in transaction - Block %d, TxnNum %d", n.channelID, kvWrite.Key, string(kvWrite.Value), txMetadata.BlockNum, txMetadata.TxNum)
 
+	n.notify(txMetadata)
+
+	return nil
+}
+
+func (n *Notifier) handleCCEvent(txMetadata gossipapi.TxMetadata, event *pb.ChaincodeEvent) error {
+	txnCCName := n.chaincodeEventsFrom()
+	if txnCCName == "" || event.EventName != common.AnchorEventName {
+		return nil
+	}
+
+	if event.ChaincodeId != txnCCName {
+		logger.Debugf("[%s] Ignoring anchor event from chaincode [%s] since it isn't the txn chaincode [%s] - Block %d, TxnNum %d", n.channelID, event.ChaincodeId, txnCCName, txMetadata.BlockNum, txMetadata.TxNum)
+
+		return nil
+	}
+
+	logger.Debugf("[%s] Found anchor event from chaincode [%s] in transaction - Block %d, TxnNum %d", n.channelID, event.ChaincodeId, txMetadata.BlockNum, txMetadata.TxNum)
+
+	n.notify(txMetadata)
+
+	return nil
+}
+
+func (n *Notifier) notify(txMetadata gossipapi.TxMetadata) {
 	// If the channel buffer gets full, reject the event since blocking the BlockPublisher can have serious consequences.
 	// No worries, since the block will be processed at a later time.
 
@@ -66,6 +126,4 @@ func (n *Notifier) handleWrite(txMetadata gossipapi.TxMetadata, namespace string
 	default:
 		logger.Infof("[%s] Unable to submit notification about anchor write in transaction - Block %d, TxnNum %d", n.channelID, txMetadata.BlockNum, txMetadata.TxNum)
 	}
-
-	return nil
 }
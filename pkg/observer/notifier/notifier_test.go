@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	gossipapi "github.com/hyperledger/fabric/extensions/gossip/api"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/fabric-peer-ext/pkg/mocks"
@@ -72,4 +73,48 @@ func TestRegisterForAnchorFileAddress(t *testing.T) {
 		result := <-done
 		require.Equal(t, uint64(1), result.BlockNum)
 	})
+
+	t.Run("test chaincode event ignored when chaincode events disabled", func(t *testing.T) {
+		notifier := New(testChannel, provider, sideTreeTxnCh)
+		require.NotNil(t, notifier)
+
+		require.NoError(t, p.HandleCCEvent(gossipapi.TxMetadata{BlockNum: 1, ChannelID: testChannel, TxID: "tx1"}, &pb.ChaincodeEvent{ChaincodeId: sideTreeTxnCCName, EventName: common.AnchorEventName}))
+		require.Empty(t, receive(sideTreeTxnCh))
+	})
+
+	t.Run("test chaincode events enabled", func(t *testing.T) {
+		notifier := New(testChannel, provider, sideTreeTxnCh)
+		require.NotNil(t, notifier)
+
+		notifier.UseChaincodeEvents(true, sideTreeTxnCCName)
+
+		// KV writes should be ignored
+		require.NoError(t, p.HandleWrite(gossipapi.TxMetadata{BlockNum: 1, ChannelID: testChannel, TxID: "tx1"}, sideTreeTxnCCName, &kvrwset.KVWrite{Key: common.AnchorPrefix + k1, Value: []byte(v1)}))
+		require.Empty(t, receive(sideTreeTxnCh))
+
+		// Other chaincode events should be ignored
+		require.NoError(t, p.HandleCCEvent(gossipapi.TxMetadata{BlockNum: 2, ChannelID: testChannel, TxID: "tx2"}, &pb.ChaincodeEvent{ChaincodeId: sideTreeTxnCCName, EventName: "other"}))
+		require.Empty(t, receive(sideTreeTxnCh))
+
+		// Anchor events from other chaincodes should be ignored
+		require.NoError(t, p.HandleCCEvent(gossipapi.TxMetadata{BlockNum: 2, ChannelID: testChannel, TxID: "tx2"}, &pb.ChaincodeEvent{ChaincodeId: "othercc", EventName: common.AnchorEventName}))
+		require.Empty(t, receive(sideTreeTxnCh))
+
+		require.NoError(t, p.HandleCCEvent(gossipapi.TxMetadata{BlockNum: 3, ChannelID: testChannel, TxID: "tx3"}, &pb.ChaincodeEvent{ChaincodeId: sideTreeTxnCCName, EventName: common.AnchorEventName}))
+		require.Equal(t, uint64(3), receive(sideTreeTxnCh).BlockNum)
+
+		notifier.UseChaincodeEvents(false, sideTreeTxnCCName)
+
+		require.NoError(t, p.HandleWrite(gossipapi.TxMetadata{BlockNum: 4, ChannelID: testChannel, TxID: "tx4"}, sideTreeTxnCCName, &kvrwset.KVWrite{Key: common.AnchorPrefix + k1, Value: []byte(v1)}))
+		require.Equal(t, uint64(4), receive(sideTreeTxnCh).BlockNum)
+	})
+}
+
+func receive(txnCh <-chan gossipapi.TxMetadata) gossipapi.TxMetadata {
+	select {
+	case txn := <-txnCh:
+		return txn
+	case <-time.After(500 * time.Millisecond):
+		return gossipapi.TxMetadata{}
+	}
 }
@@ -137,6 +137,59 @@ func TestObserver(t *testing.T) {
 		require.Equal(t, uint64(1002), meta.LastBlockProcessed)
 	})
 
+	t.Run("Multiple anchors in one transaction", func(t *testing.T) {
+		restore := setRoles(true, false)
+		defer restore()
+
+		const ns2 = "did:other"
+
+		clients := newMockClients(t)
+
+		require.NoError(t, clients.offLedger.Put(metaDataCCName, MetaDataColName, peer1, metaBytes))
+
+		txn1 := common.TxnInfo{AnchorString: "anchor1", Namespace: namespace}
+		txn1Bytes, err := json.Marshal(txn1)
+		require.NoError(t, err)
+
+		txn2 := common.TxnInfo{AnchorString: "anchor2", Namespace: ns2}
+		txn2Bytes, err := json.Marshal(txn2)
+		require.NoError(t, err)
+
+		// The anchor aggregator writes the anchors of multiple namespaces in a single transaction
+		b := peerextmocks.NewBlockBuilder(channel1, 1002)
+		b.Transaction(txID1, pb.TxValidationCode_VALID).ChaincodeAction(sideTreeTxnCCName).
+			Write(common.AnchorPrefix+txn1.AnchorString, txn1Bytes).
+			Write(common.AnchorPrefix+txn2.AnchorString, txn2Bytes)
+
+		clients.blockchain.GetBlockchainInfoReturns(&cb.BlockchainInfo{Height: 1003}, nil)
+		clients.blockchain.GetBlockByNumberReturns(b.Build(), nil)
+
+		cfg := config.Observer{
+			Period:                monitorPeriod,
+			MetaDataChaincodeName: metaDataCCName,
+		}
+
+		m := newObserverWithMocks(t, channel1, cfg, clients, make(chan gossipapi.TxMetadata, 1))
+
+		require.NoError(t, m.Start())
+		time.Sleep(sleepTime)
+		m.Stop()
+
+		// Each anchor is processed separately with the same transaction time and number
+		require.Equal(t, 2, clients.txnProcessor.ProcessCallCount())
+
+		st1 := clients.txnProcessor.ProcessArgsForCall(0)
+		require.Equal(t, "anchor1", st1.AnchorString)
+		require.Equal(t, namespace, st1.Namespace)
+
+		st2 := clients.txnProcessor.ProcessArgsForCall(1)
+		require.Equal(t, "anchor2", st2.AnchorString)
+		require.Equal(t, ns2, st2.Namespace)
+
+		require.Equal(t, st1.TransactionTime, st2.TransactionTime)
+		require.Equal(t, st1.TransactionNumber, st2.TransactionNumber)
+	})
+
 	t.Run("Clustered - replacing lease owner", func(t *testing.T) {
 		clients := newMockClients(t)
 
@@ -37,6 +37,10 @@ func (v *authTokenValidator) Validate(cfg authhandler.Config, kv *config.KeyValu
 		logger.Warnf("field 'WriteTokens' is not set for %s. No authorization will take place for writes to this endpoint.", kv.Key)
 	}
 
+	if err := v.validate(cfg.AdminTokens, kv); err != nil {
+		return err
+	}
+
 	return nil
 }
 
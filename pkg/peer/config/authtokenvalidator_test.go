@@ -86,4 +86,24 @@ func TestAuthTokenValidator_Validate(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "token name [write] is not defined")
 	})
+
+	t.Run("Admin token not defined in config -> error", func(t *testing.T) {
+		tokenProvider := &peermocks.RestConfig{}
+		tokenProvider.SidetreeAPITokenReturnsOnCall(0, "read-token")
+		tokenProvider.SidetreeAPITokenReturnsOnCall(1, "write-token")
+		tokenProvider.SidetreeAPITokenReturnsOnCall(2, "")
+
+		v := newAuthTokenValidator(tokenProvider)
+		require.NotNil(t, v)
+
+		cfg := authhandler.Config{
+			ReadTokens:  []string{r},
+			WriteTokens: []string{w},
+			AdminTokens: []string{"admin"},
+		}
+
+		err := v.Validate(cfg, kv)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "token name [admin] is not defined")
+	})
 }
@@ -83,6 +83,10 @@ func (v *blockchainHandlerValidator) validateBlockchainHandler(cfg blockchainhan
 		logger.Warnf("field 'MaxBlocksInResponse' is not set for %s. Will use default value.", kv.Key)
 	}
 
+	if cfg.ReceiptCollection != "" && cfg.ReceiptChaincodeName == "" {
+		return errors.Errorf("field 'ReceiptChaincodeName' is required when 'ReceiptCollection' is set for %s", kv.Key)
+	}
+
 	if err := v.authTokenValidator.Validate(cfg.Authorization, kv); err != nil {
 		return err
 	}
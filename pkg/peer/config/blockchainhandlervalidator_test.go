@@ -19,6 +19,7 @@ const (
 	blockchainHandlerCfg_NoBasePath        = `{"MaxTransactionsInResponse":50,"MaxBlocksInResponse":20}`
 	blockchainHandlerCfg_InvalidBasePath   = `{"BasePath":"blockchain","MaxTransactionsInResponse":50,"MaxBlocksInResponse":20}`
 	blockchainHandlerCfg_NoMaxTransactions = `{"BasePath":"/blockchain"}`
+	blockchainHandlerCfg_NoReceiptCC       = `{"BasePath":"/blockchain","ReceiptCollection":"receipts"}`
 )
 
 func TestBlockchainHandlerValidator_Validate(t *testing.T) {
@@ -46,6 +47,12 @@ func TestBlockchainHandlerValidator_Validate(t *testing.T) {
 		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, blockchainHandlerCfg_NoMaxTransactions, config.FormatJSON))))
 	})
 
+	t.Run("No ReceiptChaincodeName -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, blockchainHandlerCfg_NoReceiptCC, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'ReceiptChaincodeName' is required")
+	})
+
 	t.Run("Irrelevant config -> success", func(t *testing.T) {
 		k1 := config.NewPeerKey(mspID, peerID, "app1", "v1")
 		require.NoError(t, v.Validate(config.NewKeyValue(k1, config.NewValue(txID, `{}`, config.FormatJSON))))
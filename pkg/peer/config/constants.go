@@ -63,4 +63,10 @@ const (
 
 	// DCASAppVersion is the version of the DCAS config application
 	DCASAppVersion = "1"
+
+	// DocumentIndexesAppName is the name of the document index config application
+	DocumentIndexesAppName = "sidetree-document-indexes"
+
+	// DocumentIndexesAppVersion is the version of the document index config application
+	DocumentIndexesAppVersion = "1"
 )
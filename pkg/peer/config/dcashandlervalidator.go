@@ -83,9 +83,54 @@ func (v *dcasHandlerValidator) validateDCASHandler(cfg dcashandler.Config, kv *c
 		return errors.Errorf("field 'Collection' is required")
 	}
 
+	if cfg.MaxUploadSize < 0 {
+		return errors.Errorf("field 'MaxUploadSize' must not be negative")
+	}
+
+	if cfg.IPFSMaxSize < 0 {
+		return errors.Errorf("field 'IPFSMaxSize' must not be negative")
+	}
+
 	if err := v.authTokenValidator.Validate(cfg.Authorization, kv); err != nil {
 		return err
 	}
 
+	return validateQuotas(cfg, kv)
+}
+
+func validateQuotas(cfg dcashandler.Config, kv *config.KeyValue) error {
+	if len(cfg.Quotas) == 0 {
+		return nil
+	}
+
+	if cfg.UsageCollection == "" {
+		return errors.Errorf("field 'UsageCollection' is required if quotas are specified for %s", kv.Key)
+	}
+
+	// The type of the usage collection (which must be off-ledger) is checked when the handler is loaded
+	if cfg.UsageCollection == cfg.Collection {
+		return errors.Errorf("field 'UsageCollection' must not be the same as the DCAS collection for %s", kv.Key)
+	}
+
+	for tokenName, quota := range cfg.Quotas {
+		if !contains(cfg.Authorization.WriteTokens, tokenName) {
+			return errors.Errorf("quota specified for token [%s] which is not a write token for %s", tokenName, kv.Key)
+		}
+
+		if quota.MaxBytes < 0 || quota.MaxObjectsPerHour < 0 || quota.MaxObjectSize < 0 {
+			return errors.Errorf("quota for token [%s] must not be negative for %s", tokenName, kv.Key)
+		}
+	}
+
 	return nil
 }
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
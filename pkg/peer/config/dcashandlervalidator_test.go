@@ -21,6 +21,13 @@ const (
 	dcasHandlerCfg_InvalidBasePath = `{"BasePath":"cas","ChaincodeName":"dcascc","Collection":"dcas"}`
 	dcasHandlerCfg_NoChaincodeName = `{"BasePath":"/cas","Collection":"dcas"}`
 	dcasHandlerCfg_NoCollection    = `{"BasePath":"/cas","ChaincodeName":"dcascc"}`
+	dcasHandlerCfg_InvalidMaxSize  = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","MaxUploadSize":-1}`
+	dcasHandlerCfg_InvalidIPFSSize = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","IPFSEnabled":true,"IPFSMaxSize":-1}`
+	dcasHandlerCfg_Quotas          = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","UsageCollection":"usage","Authorization":{"WriteTokens": ["cas_w"]},"Quotas":{"cas_w":{"MaxBytes":1000000,"MaxObjectsPerHour":100,"MaxObjectSize":10000}}}`
+	dcasHandlerCfg_NoUsageColl     = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","Authorization":{"WriteTokens": ["cas_w"]},"Quotas":{"cas_w":{"MaxBytes":1000000}}}`
+	dcasHandlerCfg_UsageIsDCAS     = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","UsageCollection":"dcas","Authorization":{"WriteTokens": ["cas_w"]},"Quotas":{"cas_w":{"MaxBytes":1000000}}}`
+	dcasHandlerCfg_QuotaNotWrite   = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","UsageCollection":"usage","Authorization":{"WriteTokens": ["cas_w"]},"Quotas":{"cas_r":{"MaxBytes":1000000}}}`
+	dcasHandlerCfg_NegativeQuota   = `{"BasePath":"/cas","ChaincodeName":"dcascc","Collection":"dcas","UsageCollection":"usage","Authorization":{"WriteTokens": ["cas_w"]},"Quotas":{"cas_w":{"MaxObjectsPerHour":-1}}}`
 )
 
 func TestDcasHandlerValidator_Validate(t *testing.T) {
@@ -119,4 +126,44 @@ func TestDcasHandlerValidator_Validate(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "field 'Collection' is required")
 	})
+
+	t.Run("Invalid MaxUploadSize -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_InvalidMaxSize, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'MaxUploadSize' must not be negative")
+	})
+
+	t.Run("Invalid IPFSMaxSize -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_InvalidIPFSSize, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'IPFSMaxSize' must not be negative")
+	})
+
+	t.Run("Quotas -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_Quotas, config.FormatJSON))))
+	})
+
+	t.Run("Quotas with no UsageCollection -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_NoUsageColl, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'UsageCollection' is required if quotas are specified")
+	})
+
+	t.Run("Quotas with DCAS collection as UsageCollection -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_UsageIsDCAS, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'UsageCollection' must not be the same as the DCAS collection")
+	})
+
+	t.Run("Quota for token that's not a write token -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_QuotaNotWrite, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "quota specified for token [cas_r] which is not a write token")
+	})
+
+	t.Run("Negative quota -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, dcasHandlerCfg_NegativeQuota, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "quota for token [cas_w] must not be negative")
+	})
 }
@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
+
+	sidetreecfg "github.com/trustbloc/sidetree-fabric/pkg/config"
+)
+
+const couchDBIndexType = "json"
+
+// couchDBIndex contains the fields of a CouchDB JSON index definition that are validated
+type couchDBIndex struct {
+	Index *struct {
+		Fields []interface{} `json:"fields"`
+	} `json:"index"`
+	DDoc string `json:"ddoc"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// documentIndexValidator validates the CouchDB index configuration for the document collections
+type documentIndexValidator struct {
+}
+
+func (v *documentIndexValidator) Validate(kv *config.KeyValue) error {
+	if kv.AppName != DocumentIndexesAppName {
+		return nil
+	}
+
+	logger.Debugf("Validating config %s", kv)
+
+	if kv.MspID != GlobalMSPID {
+		return errors.Errorf("expecting MspID to be set to [%s] for document index config %s", GlobalMSPID, kv.Key)
+	}
+
+	if kv.AppVersion != DocumentIndexesAppVersion {
+		return errors.Errorf("unsupported document index config version %s for %s", kv.AppVersion, kv.Key)
+	}
+
+	var indexCfg sidetreecfg.DocumentIndexes
+	if err := unmarshal(kv.Value, &indexCfg); err != nil {
+		return errors.WithMessagef(err, "invalid config %s", kv.Key)
+	}
+
+	for _, collCfg := range indexCfg.Collections {
+		if err := validateCollectionIndexes(collCfg); err != nil {
+			return errors.WithMessagef(err, "invalid config %s", kv.Key)
+		}
+	}
+
+	return nil
+}
+
+func validateCollectionIndexes(cfg sidetreecfg.CollectionIndexes) error {
+	if cfg.Collection == "" {
+		return errors.New("field 'Collection' is required")
+	}
+
+	if len(cfg.Indexes) == 0 {
+		return errors.Errorf("at least one index is required for collection [%s]", cfg.Collection)
+	}
+
+	names := make(map[string]struct{})
+
+	for _, indexDef := range cfg.Indexes {
+		name, err := validateIndex(indexDef)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid index for collection [%s]", cfg.Collection)
+		}
+
+		if _, ok := names[name]; ok {
+			return errors.Errorf("duplicate index name [%s] for collection [%s]", name, cfg.Collection)
+		}
+
+		names[name] = struct{}{}
+	}
+
+	return nil
+}
+
+func validateIndex(indexDef string) (string, error) {
+	index := &couchDBIndex{}
+	if err := json.Unmarshal([]byte(indexDef), index); err != nil {
+		return "", errors.WithMessagef(err, "index is not a valid JSON index definition [%s]", indexDef)
+	}
+
+	if index.Index == nil || len(index.Index.Fields) == 0 {
+		return "", errors.Errorf("field 'index.fields' is required [%s]", indexDef)
+	}
+
+	if index.Name == "" {
+		return "", errors.Errorf("field 'name' is required [%s]", indexDef)
+	}
+
+	if index.Type != "" && index.Type != couchDBIndexType {
+		return "", errors.Errorf("unsupported index type [%s] - only [%s] is supported", index.Type, couchDBIndexType)
+	}
+
+	return index.Name, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
+)
+
+func TestDocumentIndexValidator_Validate(t *testing.T) {
+	v := &documentIndexValidator{}
+
+	k := config.NewAppKey(GlobalMSPID, DocumentIndexesAppName, DocumentIndexesAppVersion)
+
+	t.Run("Irrelevant config -> success", func(t *testing.T) {
+		k := config.NewAppKey(mspID, "app1", "v1")
+		require.NoError(t, v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{}`, config.FormatJSON))))
+	})
+
+	t.Run("Invalid MSP ID -> error", func(t *testing.T) {
+		k := config.NewAppKey(mspID, DocumentIndexesAppName, DocumentIndexesAppVersion)
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expecting MspID to be set to [general] for document index config")
+	})
+
+	t.Run("Unsupported version -> error", func(t *testing.T) {
+		k := config.NewAppKey(GlobalMSPID, DocumentIndexesAppName, "22")
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported document index config version")
+	})
+
+	t.Run("Invalid config -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected end of JSON input")
+	})
+
+	t.Run("Missing Collection -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Indexes":["{}"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'Collection' is required")
+	})
+
+	t.Run("Missing indexes -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs"}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one index is required for collection [docs]")
+	})
+
+	t.Run("Invalid index JSON -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs","Indexes":["{"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "index is not a valid JSON index definition")
+	})
+
+	t.Run("Missing index fields -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs","Indexes":["{\"index\":{\"fields\":[]},\"name\":\"idx\"}"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'index.fields' is required")
+	})
+
+	t.Run("Missing index name -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs","Indexes":["{\"index\":{\"fields\":[\"type\"]}}"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'name' is required")
+	})
+
+	t.Run("Unsupported index type -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs","Indexes":["{\"index\":{\"fields\":[\"type\"]},\"name\":\"idx\",\"type\":\"text\"}"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported index type [text]")
+	})
+
+	t.Run("Duplicate index name -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(k, config.NewValue(txID, `{"Collections":[{"Collection":"docs","Indexes":["{\"index\":{\"fields\":[\"type\"]},\"name\":\"idx\"}","{\"index\":{\"fields\":[\"transactionTime\"]},\"name\":\"idx\"}"]}]}`, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "duplicate index name [idx] for collection [docs]")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		cfg := `{"Collections":[{"Collection":"docs","Indexes":[` +
+			`"{\"index\":{\"fields\":[\"transactionTime\"]},\"ddoc\":\"indexTransactionTimeDoc\",\"name\":\"indexTransactionTime\",\"type\":\"json\"}",` +
+			`"{\"index\":{\"fields\":[\"uniqueSuffix\",{\"transactionTime\":\"desc\"}]},\"ddoc\":\"indexSuffixTimeDoc\",\"name\":\"indexSuffixTime\"}"` +
+			`]}]}`
+		require.NoError(t, v.Validate(config.NewKeyValue(k, config.NewValue(txID, cfg, config.FormatJSON))))
+	})
+}
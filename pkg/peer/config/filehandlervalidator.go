@@ -91,9 +91,62 @@ func (v *fileHandlerValidator) validateFileHandler(cfg filehandler.Config, kv *c
 		return errors.Errorf("field 'IndexDocID' must begin with '%s'", cfg.IndexNamespace)
 	}
 
+	if cfg.ChunkSize < 0 {
+		return errors.Errorf("field 'ChunkSize' must not be negative")
+	}
+
+	if cfg.MaxUploadSize < 0 {
+		return errors.Errorf("field 'MaxUploadSize' must not be negative")
+	}
+
+	if cfg.ListingEnabled && len(cfg.Authorization.ReadTokens) == 0 {
+		return errors.Errorf("field 'Authorization.ReadTokens' is required when 'ListingEnabled' is set")
+	}
+
+	if err := validateWebsite(cfg.Website); err != nil {
+		return err
+	}
+
 	if err := v.authTokenValidator.Validate(cfg.Authorization, kv); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func validateWebsite(cfg filehandler.WebsiteConfig) error {
+	documents := []struct {
+		field string
+		name  string
+	}{
+		{"IndexDocument", cfg.IndexDocument},
+		{"NotFoundDocument", cfg.NotFoundDocument},
+		{"FallbackDocument", cfg.FallbackDocument},
+	}
+
+	for _, doc := range documents {
+		if doc.name == "" {
+			continue
+		}
+
+		if err := filehandler.ValidateResourceName(doc.name); err != nil {
+			return errors.WithMessagef(err, "invalid value for field 'Website.%s'", doc.field)
+		}
+	}
+
+	if cfg.IndexDocument != "" && strings.Contains(cfg.IndexDocument, "/") {
+		return errors.Errorf("field 'Website.IndexDocument' must not contain a path")
+	}
+
+	for ext, contentType := range cfg.ContentTypes {
+		if ext == "" || strings.ContainsAny(ext, "./") {
+			return errors.Errorf("invalid file extension [%s] in field 'Website.ContentTypes' - expecting a value such as 'html'", ext)
+		}
+
+		if contentType == "" {
+			return errors.Errorf("content type for file extension [%s] in field 'Website.ContentTypes' is empty", ext)
+		}
+	}
+
+	return nil
+}
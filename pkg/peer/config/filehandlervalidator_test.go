@@ -24,6 +24,15 @@ const (
 	fileHandlerCfg_NoIndexNamespace  = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexDocID":"file:idx:1234"}`
 	fileHandlerCfg_NoIndexDocID      = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx"}`
 	fileHandlerCfg_InvalidIndexDocID = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"did:bloc:1234"}`
+	fileHandlerCfg_InvalidChunkSize  = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","ChunkSize":-1}`
+	fileHandlerCfg_InvalidMaxUpload  = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","MaxUploadSize":-1}`
+	fileHandlerCfg_ListingNoTokens   = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","ListingEnabled":true}`
+	fileHandlerCfg_Listing           = `{"BasePath":"/schema","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","ListingEnabled":true,"Authorization":{"ReadTokens":["read"]}}`
+	fileHandlerCfg_Website           = `{"BasePath":"/site","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","Website":{"IndexDocument":"index.html","NotFoundDocument":"errors/404.html","FallbackDocument":"index.html","ContentTypes":{"html":"text/html"}}}`
+	fileHandlerCfg_InvalidIndexDoc   = `{"BasePath":"/site","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","Website":{"IndexDocument":"docs/index.html"}}`
+	fileHandlerCfg_InvalidNotFound   = `{"BasePath":"/site","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","Website":{"NotFoundDocument":"../404.html"}}`
+	fileHandlerCfg_InvalidExtension  = `{"BasePath":"/site","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","Website":{"ContentTypes":{"a/b":"text/html"}}}`
+	fileHandlerCfg_EmptyContentType  = `{"BasePath":"/site","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID":"file:idx:1234","Website":{"ContentTypes":{"html":""}}}`
 )
 
 func TestFileHandlerValidator_Validate(t *testing.T) {
@@ -131,4 +140,46 @@ func TestFileHandlerValidator_Validate(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "field 'IndexDocID' must begin with 'file:idx'")
 	})
+
+	t.Run("Invalid ChunkSize -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, fileHandlerCfg_InvalidChunkSize, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'ChunkSize' must not be negative")
+	})
+
+	t.Run("Invalid MaxUploadSize -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, fileHandlerCfg_InvalidMaxUpload, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'MaxUploadSize' must not be negative")
+	})
+
+	t.Run("Listing without read tokens -> error", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, fileHandlerCfg_Listing, config.FormatJSON))))
+
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, fileHandlerCfg_ListingNoTokens, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'Authorization.ReadTokens' is required when 'ListingEnabled' is set")
+	})
+
+	t.Run("Website", func(t *testing.T) {
+		siteKey := config.NewPeerComponentKey(mspID, peerID, FileHandlerAppName, FileHandlerAppVersion, "/site", "1")
+
+		require.NoError(t, v.Validate(config.NewKeyValue(siteKey, config.NewValue(txID, fileHandlerCfg_Website, config.FormatJSON))))
+
+		err := v.Validate(config.NewKeyValue(siteKey, config.NewValue(txID, fileHandlerCfg_InvalidIndexDoc, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'Website.IndexDocument' must not contain a path")
+
+		err = v.Validate(config.NewKeyValue(siteKey, config.NewValue(txID, fileHandlerCfg_InvalidNotFound, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for field 'Website.NotFoundDocument'")
+
+		err = v.Validate(config.NewKeyValue(siteKey, config.NewValue(txID, fileHandlerCfg_InvalidExtension, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file extension [a/b] in field 'Website.ContentTypes'")
+
+		err = v.Validate(config.NewKeyValue(siteKey, config.NewValue(txID, fileHandlerCfg_EmptyContentType, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "content type for file extension [html] in field 'Website.ContentTypes' is empty")
+	})
 }
@@ -55,6 +55,7 @@ func NewSidetreeProvider(configProvider configServiceProvider, registry validato
 	registry.Register(newSidetreePeerValidator(tokenProvider))
 	registry.Register(newFileHandlerValidator(tokenProvider))
 	registry.Register(&dcasValidator{})
+	registry.Register(&documentIndexValidator{})
 	registry.Register(newDCASHandlerValidator(tokenProvider))
 	registry.Register(newBlockchainHandlerValidator(tokenProvider))
 	registry.Register(newDiscoveryHandlerValidator(tokenProvider))
@@ -164,7 +165,8 @@ func (c *sidetreeService) LoadSidetreeHandlers(mspID, peerID string) ([]sidetree
 	return handlers, nil
 }
 
-// LoadFileHandlers loads the file handler configuration
+// LoadFileHandlers loads the file handler configuration. If peerID is empty then the file handlers of all of
+// the peers in the given MSP are loaded.
 func (c *sidetreeService) LoadFileHandlers(mspID, peerID string) ([]filehandler.Config, error) {
 	criteria := &ledgerconfig.Criteria{
 		MspID:      mspID,
@@ -302,6 +304,33 @@ func (c *sidetreeService) LoadDCAS() (config.DCAS, error) {
 	return dcasCfg, nil
 }
 
+// LoadDocumentIndexes loads the CouchDB indexes for the document collections. If no indexes are configured
+// then empty indexes are returned.
+func (c *sidetreeService) LoadDocumentIndexes() (config.DocumentIndexes, error) {
+	criteria := &ledgerconfig.Criteria{
+		MspID:      GlobalMSPID,
+		AppName:    DocumentIndexesAppName,
+		AppVersion: DocumentIndexesAppVersion,
+	}
+
+	results, err := c.service.Query(criteria)
+	if err != nil {
+		return config.DocumentIndexes{}, errors.WithMessagef(err, "error loading document index config for criteria %s", criteria)
+	}
+
+	var indexes config.DocumentIndexes
+	for _, kv := range results {
+		cfg := config.DocumentIndexes{}
+		if err := unmarshal(kv.Value, &cfg); err != nil {
+			return config.DocumentIndexes{}, err
+		}
+
+		indexes.Collections = append(indexes.Collections, cfg.Collections...)
+	}
+
+	return indexes, nil
+}
+
 func (c *sidetreeService) load(key *ledgerconfig.Key, v interface{}) error {
 	cfg, err := c.service.Get(key)
 	if err != nil {
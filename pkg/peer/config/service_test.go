@@ -60,6 +60,7 @@ const (
 	dcasCfgJson                      = `{"ChaincodeName":"cc1","Collection":"dcas"}`
 	dcasCfgMissingCCJson             = `{"Collection":"dcas"}`
 	dcasCfgMissingCollJson           = `{"ChaincodeName":"cc1"}`
+	documentIndexesCfgJson           = `{"Collections":[{"Collection":"docs","Indexes":["{\"index\":{\"fields\":[\"transactionTime\"]},\"name\":\"indexTransactionTime\",\"type\":\"json\"}"]}]}`
 )
 
 func TestNewSidetreeProvider(t *testing.T) {
@@ -591,4 +592,61 @@ func TestNewSidetreeProvider(t *testing.T) {
 			require.Equal(t, config.DCAS{}, cfg)
 		})
 	})
+
+	t.Run("LoadDocumentIndexes", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			queryResults := []*ledgercfg.KeyValue{
+				{
+					Value: &ledgercfg.Value{
+						TxID:   "tx1",
+						Format: "json",
+						Config: documentIndexesCfgJson,
+					},
+				},
+			}
+
+			configService.QueryReturns(queryResults, nil)
+
+			cfg, err := s.LoadDocumentIndexes()
+			require.NoError(t, err)
+			require.Len(t, cfg.Collections, 1)
+			require.Equal(t, "docs", cfg.Collections[0].Collection)
+			require.Equal(t, []string{`{"index":{"fields":["transactionTime"]},"name":"indexTransactionTime","type":"json"}`}, cfg.Collections[0].Indexes)
+		})
+
+		t.Run("Not configured", func(t *testing.T) {
+			configService.QueryReturns(nil, nil)
+
+			cfg, err := s.LoadDocumentIndexes()
+			require.NoError(t, err)
+			require.Empty(t, cfg.Collections)
+		})
+
+		t.Run("Query error", func(t *testing.T) {
+			errExpected := errors.New("injected query error")
+			configService.QueryReturns(nil, errExpected)
+
+			_, err := s.LoadDocumentIndexes()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), errExpected.Error())
+		})
+
+		t.Run("Unmarshal error", func(t *testing.T) {
+			queryResults := []*ledgercfg.KeyValue{
+				{
+					Value: &ledgercfg.Value{
+						TxID:   "tx1",
+						Format: "json",
+						Config: `{`,
+					},
+				},
+			}
+
+			configService.QueryReturns(queryResults, nil)
+
+			_, err := s.LoadDocumentIndexes()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "error reading config")
+		})
+	})
 }
@@ -9,6 +9,7 @@ package config
 import (
 	"github.com/pkg/errors"
 
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
 	"github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
 
 	sidetreecfg "github.com/trustbloc/sidetree-fabric/pkg/config"
@@ -55,7 +56,19 @@ func (v *sidetreePeerValidator) validateConfig(kv *config.KeyValue) error {
 		return errors.WithMessagef(err, "invalid config %s", kv.Key)
 	}
 
-	return v.validateObserver(kv, sidetreeCfg.Observer)
+	if err := v.validateObserver(kv, sidetreeCfg.Observer); err != nil {
+		return err
+	}
+
+	if err := v.validateAnchorAggregator(kv, sidetreeCfg.AnchorAggregator); err != nil {
+		return err
+	}
+
+	if err := v.validateDCASGarbageCollector(kv, sidetreeCfg.DCASGarbageCollector); err != nil {
+		return err
+	}
+
+	return v.validateDCASIntegrityScan(kv, sidetreeCfg.DCASIntegrityScan)
 }
 
 func (v *sidetreePeerValidator) validateHandlerConfig(kv *config.KeyValue) error {
@@ -106,3 +119,50 @@ func (v *sidetreePeerValidator) validateObserver(kv *config.KeyValue, cfg sidetr
 
 	return nil
 }
+
+func (v *sidetreePeerValidator) validateAnchorAggregator(kv *config.KeyValue, cfg sidetreecfg.AnchorAggregator) error {
+	if cfg.Window < 0 {
+		return errors.Errorf("field 'AnchorAggregator.Window' must not be negative for %s", kv.Key)
+	}
+
+	if cfg.MaxAnchors < 0 {
+		return errors.Errorf("field 'AnchorAggregator.MaxAnchors' must not be negative for %s", kv.Key)
+	}
+
+	if cfg.Window > 0 && cfg.MaxAnchors == 0 {
+		logger.Infof("Sidetree anchor aggregator MaxAnchors is set to 0 and therefore the default value will be used for [%s].", kv.PeerID)
+	}
+
+	return nil
+}
+
+func (v *sidetreePeerValidator) validateDCASGarbageCollector(kv *config.KeyValue, cfg sidetreecfg.DCASGarbageCollector) error {
+	if cfg.Period < 0 {
+		return errors.Errorf("field 'DCASGarbageCollector.Period' must not be negative for %s", kv.Key)
+	}
+
+	if cfg.GracePeriod < 0 {
+		return errors.Errorf("field 'DCASGarbageCollector.GracePeriod' must not be negative for %s", kv.Key)
+	}
+
+	// Content that has been uploaded but not yet anchored or added to a file index would otherwise be deleted
+	if cfg.Delete && cfg.GracePeriod == 0 {
+		return errors.Errorf("field 'DCASGarbageCollector.GracePeriod' must be greater than 0 when 'Delete' is set for %s", kv.Key)
+	}
+
+	for _, cID := range cfg.PinnedCIDs {
+		if err := dcas.ValidateCID(cID); err != nil {
+			return errors.Errorf("invalid CID [%s] in field 'DCASGarbageCollector.PinnedCIDs' for %s: %s", cID, kv.Key, err)
+		}
+	}
+
+	return nil
+}
+
+func (v *sidetreePeerValidator) validateDCASIntegrityScan(kv *config.KeyValue, cfg sidetreecfg.DCASIntegrityScan) error {
+	if cfg.Period < 0 {
+		return errors.Errorf("field 'DCASIntegrityScan.Period' must not be negative for %s", kv.Key)
+	}
+
+	return nil
+}
@@ -23,6 +23,16 @@ const (
 	org1Peer1Cfg                               = `{"Observer":{"MetaDataChaincodeName":"document","Period":"3s"}}`
 	org1Peer1NoPeriodCfg                       = `{"Observer":{"MetaDataChaincodeName":"document"}}`
 	org1Peer1CfgNoMetaDataCC                   = `{"Observer":{"Period":"3s"}}`
+	org1Peer1AggregatorCfg                     = `{"Observer":{"MetaDataChaincodeName":"document"},"AnchorAggregator":{"Window":"500ms","MaxAnchors":10}}`
+	org1Peer1AggregatorInvalidWindowCfg        = `{"Observer":{"MetaDataChaincodeName":"document"},"AnchorAggregator":{"Window":"-1s"}}`
+	org1Peer1AggregatorInvalidMaxAnchorsCfg    = `{"Observer":{"MetaDataChaincodeName":"document"},"AnchorAggregator":{"Window":"1s","MaxAnchors":-1}}`
+	org1Peer1GCCfg                             = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASGarbageCollector":{"Period":"1h","GracePeriod":"24h","PinnedCIDs":["bafkreiarkubvukdidicmqynkyls3iqawdqvthi7e6mbky2amuw3inxsi3y"]}}`
+	org1Peer1GCInvalidPeriodCfg                = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASGarbageCollector":{"Period":"-1h"}}`
+	org1Peer1GCInvalidGracePeriodCfg           = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASGarbageCollector":{"Period":"1h","GracePeriod":"-1h"}}`
+	org1Peer1GCDeleteNoGracePeriodCfg          = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASGarbageCollector":{"Period":"1h","Delete":true}}`
+	org1Peer1GCInvalidPinnedCIDCfg             = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASGarbageCollector":{"Period":"1h","PinnedCIDs":["xxx"]}}`
+	org1Peer1IntegrityScanCfg                  = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASIntegrityScan":{"Period":"24h"}}`
+	org1Peer1IntegrityScanInvalidPeriodCfg     = `{"Observer":{"MetaDataChaincodeName":"document"},"DCASIntegrityScan":{"Period":"-1h"}}`
 	org1Peer1SidetreeHandlerCfg                = `{"BasePath":"/sidetree/v1","Namespace":"did:sidetree","Authorization":{"ReadTokens":["did_r","did_w"],"WriteTokens": ["did_w"]}}`
 	org1Peer1SidetreeHandlerNoNamespaceCfg     = `{"BasePath":"/sidetree/v1"}`
 	org1Peer1SidetreeHandlerNoBasePathCfg      = `{"Namespace":"did:sidetree"}`
@@ -67,6 +77,60 @@ func TestSidetreePeerValidator_Validate(t *testing.T) {
 		require.Contains(t, err.Error(), "field 'MetaDataChaincodeName' is required")
 	})
 
+	t.Run("Anchor aggregator -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1AggregatorCfg, config.FormatJSON))))
+	})
+
+	t.Run("Anchor aggregator invalid window -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1AggregatorInvalidWindowCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorAggregator.Window' must not be negative")
+	})
+
+	t.Run("Anchor aggregator invalid max anchors -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1AggregatorInvalidMaxAnchorsCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorAggregator.MaxAnchors' must not be negative")
+	})
+
+	t.Run("DCAS garbage collector -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1GCCfg, config.FormatJSON))))
+	})
+
+	t.Run("DCAS garbage collector invalid period -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1GCInvalidPeriodCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'DCASGarbageCollector.Period' must not be negative")
+	})
+
+	t.Run("DCAS garbage collector invalid grace period -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1GCInvalidGracePeriodCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'DCASGarbageCollector.GracePeriod' must not be negative")
+	})
+
+	t.Run("DCAS garbage collector delete with no grace period -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1GCDeleteNoGracePeriodCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'DCASGarbageCollector.GracePeriod' must be greater than 0 when 'Delete' is set")
+	})
+
+	t.Run("DCAS garbage collector invalid pinned CID -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1GCInvalidPinnedCIDCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CID [xxx] in field 'DCASGarbageCollector.PinnedCIDs'")
+	})
+
+	t.Run("DCAS integrity scan -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1IntegrityScanCfg, config.FormatJSON))))
+	})
+
+	t.Run("DCAS integrity scan invalid period -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(key, config.NewValue(txID, org1Peer1IntegrityScanInvalidPeriodCfg, config.FormatJSON)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'DCASIntegrityScan.Period' must not be negative")
+	})
+
 	t.Run("No peer ID -> error", func(t *testing.T) {
 		k1 := config.NewPeerKey(mspID, "", SidetreePeerAppName, SidetreePeerAppVersion)
 		err := v.Validate(config.NewKeyValue(k1, config.NewValue(txID, `{}`, config.FormatJSON)))
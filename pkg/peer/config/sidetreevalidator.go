@@ -74,6 +74,57 @@ func (v *sidetreeValidator) validateConfig(kv *config.KeyValue) error {
 		return errors.Errorf("field 'Collection' must not use reserved name [%s] for %s", sidetreeCfg.Collection, kv.Key)
 	}
 
+	if sidetreeCfg.AnchorReceiptCollection != "" &&
+		(sidetreeCfg.AnchorReceiptCollection == sidetreeCfg.Collection || sidetreeCfg.AnchorReceiptCollection == observer.MetaDataColName) {
+		return errors.Errorf("field 'AnchorReceiptCollection' must not be the same as the document collection or use reserved name [%s] for %s", observer.MetaDataColName, kv.Key)
+	}
+
+	if err := v.validateAnchorEndorsement(kv, sidetreeCfg.AnchorEndorsement); err != nil {
+		return err
+	}
+
+	if err := v.validateAuthorization(kv, "AnchorAuthorization", sidetreeCfg.AnchorAuthorization); err != nil {
+		return err
+	}
+
+	return v.validateAuthorization(kv, "QueryAuthorization", sidetreeCfg.QueryAuthorization)
+}
+
+func (v *sidetreeValidator) validateAnchorEndorsement(kv *config.KeyValue, cfg sidetreecfg.AnchorEndorsement) error {
+	if cfg.MinEndorsements < 0 {
+		return errors.Errorf("field 'AnchorEndorsement.MinEndorsements' must not be negative for %s", kv.Key)
+	}
+
+	if len(cfg.Peers) > 0 && cfg.MinEndorsements > len(cfg.Peers) {
+		return errors.Errorf("field 'AnchorEndorsement.MinEndorsements' must not be greater than the number of peers for %s", kv.Key)
+	}
+
+	if cfg.CommitTimeout < 0 {
+		return errors.Errorf("field 'AnchorEndorsement.CommitTimeout' must not be negative for %s", kv.Key)
+	}
+
+	return nil
+}
+
+func (v *sidetreeValidator) validateAuthorization(kv *config.KeyValue, field string, cfg sidetreecfg.AnchorAuthorization) error {
+	for _, mspID := range cfg.MSPIDs {
+		if mspID == "" {
+			return errors.Errorf("field '%s.MSPIDs' must not contain empty values for %s", field, kv.Key)
+		}
+	}
+
+	for _, ou := range cfg.OUs {
+		if ou == "" {
+			return errors.Errorf("field '%s.OUs' must not contain empty values for %s", field, kv.Key)
+		}
+	}
+
+	for _, attr := range cfg.Attributes {
+		if attr.Name == "" {
+			return errors.Errorf("field '%s.Attributes' must not contain an empty attribute name for %s", field, kv.Key)
+		}
+	}
+
 	return nil
 }
 
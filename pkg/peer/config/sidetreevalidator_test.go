@@ -60,6 +60,85 @@ collection: docs
 	appCfgNoCollection = `
 batchWriterTimeout: 1s
 chaincodeName: document
+`
+	appCfgInvalidReceiptCollection = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorReceiptCollection: docs
+`
+	appCfgAnchorEndorsement = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorEndorsement:
+  orgs: [Org1MSP, Org2MSP]
+  peers: [peer0.org1.example.com:7051, peer0.org2.example.com:7051]
+  minEndorsements: 2
+  commitTimeout: 10s
+`
+	appCfgInvalidMinEndorsements = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorEndorsement:
+  minEndorsements: -1
+`
+	appCfgTooManyMinEndorsements = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorEndorsement:
+  peers: [peer0.org1.example.com:7051]
+  minEndorsements: 2
+`
+	appCfgAnchorAuthorization = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorAuthorization:
+  mspIDs: [Org1MSP, Org2MSP]
+  ous: [sidetree]
+  attributes:
+    - name: hf.Type
+      value: client
+    - name: sidetree.writer
+`
+	appCfgEmptyAuthorizedMSPID = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorAuthorization:
+  mspIDs: [Org1MSP, ""]
+`
+	appCfgEmptyAuthorizedOU = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorAuthorization:
+  ous: [""]
+`
+	appCfgEmptyAuthorizedAttribute = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorAuthorization:
+  attributes:
+    - value: client
+`
+	appCfgEmptyQueryMSPID = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+queryAuthorization:
+  mspIDs: [""]
+`
+	appCfgInvalidCommitTimeout = `
+batchWriterTimeout: 1s
+chaincodeName: document
+collection: docs
+anchorEndorsement:
+  commitTimeout: -1s
 `
 )
 
@@ -99,6 +178,52 @@ func TestSidetreeValidator_Validate(t *testing.T) {
 		require.Contains(t, err.Error(), "field 'Collection' is required")
 	})
 
+	t.Run("Invalid anchor receipt collection -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgInvalidReceiptCollection, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorReceiptCollection' must not be the same as the document collection")
+	})
+
+	t.Run("Anchor endorsement -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgAnchorEndorsement, config.FormatYAML, sidetreeTag))))
+	})
+
+	t.Run("Invalid anchor endorsement -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgInvalidMinEndorsements, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorEndorsement.MinEndorsements' must not be negative")
+
+		err = v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgTooManyMinEndorsements, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorEndorsement.MinEndorsements' must not be greater than the number of peers")
+
+		err = v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgInvalidCommitTimeout, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorEndorsement.CommitTimeout' must not be negative")
+	})
+
+	t.Run("Anchor authorization -> success", func(t *testing.T) {
+		require.NoError(t, v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgAnchorAuthorization, config.FormatYAML, sidetreeTag))))
+	})
+
+	t.Run("Invalid anchor authorization -> error", func(t *testing.T) {
+		err := v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgEmptyAuthorizedMSPID, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorAuthorization.MSPIDs' must not contain empty values")
+
+		err = v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgEmptyAuthorizedOU, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorAuthorization.OUs' must not contain empty values")
+
+		err = v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgEmptyAuthorizedAttribute, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'AnchorAuthorization.Attributes' must not contain an empty attribute name")
+
+		err = v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfgEmptyQueryMSPID, config.FormatYAML, sidetreeTag)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field 'QueryAuthorization.MSPIDs' must not contain empty values")
+	})
+
 	t.Run("App config -> success", func(t *testing.T) {
 		require.NoError(t, v.Validate(config.NewKeyValue(appKey, config.NewValue(txID, appCfg, config.FormatYAML, sidetreeTag))))
 	})
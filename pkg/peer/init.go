@@ -46,9 +46,9 @@ func Initialize() {
 	resource.Register(doccache.New)
 
 	// Register chaincode
-	ucc.Register(func() ccapi.UserCC { return doc.New("document") })
-	ucc.Register(func(pccp txn.ProtocolClientChannelProvider, dcasf txn.DCASStubWrapperFactory) ccapi.UserCC {
-		return txn.New("sidetreetxn", pccp, dcasf)
+	ucc.Register(func(scp doc.SidetreeConfigProvider) ccapi.UserCC { return doc.New("document", scp) })
+	ucc.Register(func(pccp txn.ProtocolClientChannelProvider, dcasf txn.DCASStubWrapperFactory, scp txn.SidetreeConfigProvider) ccapi.UserCC {
+		return txn.New("sidetreetxn", pccp, dcasf, scp)
 	})
 	ucc.Register(func() ccapi.UserCC { return file.New("file") })
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	gossipapi "github.com/hyperledger/fabric/extensions/gossip/api"
 	"github.com/pkg/errors"
 	ledgerconfig "github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
@@ -21,8 +22,13 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
 	"github.com/trustbloc/sidetree-fabric/pkg/config"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/blockchain"
 	ctxcommon "github.com/trustbloc/sidetree-fabric/pkg/context/common"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
 	"github.com/trustbloc/sidetree-fabric/pkg/context/store"
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasgc"
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasintegrity"
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
 	"github.com/trustbloc/sidetree-fabric/pkg/observer/notifier"
 	peerconfig "github.com/trustbloc/sidetree-fabric/pkg/peer/config"
 	"github.com/trustbloc/sidetree-fabric/pkg/peer/discovery"
@@ -39,11 +45,17 @@ const (
 	apiVersion = "0.0.1"
 
 	versionPath      = "/version"
+	batchPath        = "/batch"
+	ipfsGatewayPath  = "/ipfs"
+	ipfsCatPath      = "/api/v0/cat"
+	integrityPath    = "/integrity"
+	usagePath        = "/usage"
 	timePath         = "/time"
 	transactionsPath = "/transactions"
 	firstValidPath   = "/first-valid"
 	blocksPath       = "/blocks"
 	configBlockPath  = "/config-block"
+	receiptsPath     = "/receipts"
 )
 
 type restServiceController interface {
@@ -55,14 +67,17 @@ type channelController struct {
 	restServiceController
 	sidetreeCfgService config.SidetreeService
 
-	mutex     sync.RWMutex
-	channelID string
-	notifier  *notifier.Notifier
-	observer  *observerController
-	contexts  map[string]*context
-	services  []*service
-	cfgTxID   string
-	txnChan   chan gossipapi.TxMetadata
+	mutex      sync.RWMutex
+	channelID  string
+	notifier   *notifier.Notifier
+	observer   *observerController
+	aggregator *blockchain.Aggregator
+	gc         *dcasgc.Collector
+	scanner    *dcasintegrity.Scanner
+	contexts   map[string]*context
+	services   []*service
+	cfgTxID    string
+	txnChan    chan gossipapi.TxMetadata
 }
 
 func newChannelController(channelID string, providers *providers, configService config.SidetreeService, listener restServiceController) *channelController {
@@ -102,6 +117,21 @@ func (c *channelController) Close() {
 
 	c.contexts = make(map[string]*context)
 
+	if c.aggregator != nil {
+		c.aggregator.Stop()
+		c.aggregator = nil
+	}
+
+	if c.gc != nil {
+		c.gc.Stop()
+		c.gc = nil
+	}
+
+	if c.scanner != nil {
+		c.scanner.Stop()
+		c.scanner = nil
+	}
+
 	if c.txnChan != nil {
 		close(c.txnChan)
 	}
@@ -150,18 +180,32 @@ func (c *channelController) load() error {
 
 	storeProvider := store.NewProvider(c.channelID, c.sidetreeCfgService, c.OffLedgerProvider)
 
-	if err := c.loadContexts(restHandlerCfg.sidetree, dcasCfg, storeProvider); err != nil {
+	aggregator := c.newAnchorAggregator(cfg.AnchorAggregator, dcasCfg)
+
+	if err := c.loadContexts(restHandlerCfg.sidetree, dcasCfg, storeProvider, aggregator); err != nil {
+		if aggregator != nil {
+			aggregator.Stop()
+		}
+
 		return err
 	}
 
+	// The old contexts have been stopped so the old aggregator may now be stopped
+	c.setAnchorAggregator(aggregator)
+
+	// The integrity scanner is restarted before the REST services are loaded since it's shared with the DCAS integrity handler
+	c.restartDCASIntegrityScanner(cfg.DCASIntegrityScan, dcasCfg)
+
 	if err := c.loadRESTServices(restHandlerCfg); err != nil {
 		return err
 	}
 
-	if err := c.restartObserver(cfg.Observer); err != nil {
+	if err := c.restartObserver(cfg.Observer, dcasCfg); err != nil {
 		return err
 	}
 
+	c.restartDCASGarbageCollector(cfg.DCASGarbageCollector, dcasCfg)
+
 	c.restServiceController.RestartRESTService()
 
 	c.DiscoveryProvider.UpdateLocalServicesForChannel(c.channelID, c.localServices())
@@ -176,8 +220,8 @@ type contextPair struct {
 	oldCtx *context
 }
 
-func (c *channelController) loadContexts(handlers []sidetreehandler.Config, dcasCfg config.DCAS, storeProvider ctxcommon.OperationStoreProvider) error {
-	loadedContexts, err := c.loadNewContexts(handlers, dcasCfg, storeProvider)
+func (c *channelController) loadContexts(handlers []sidetreehandler.Config, dcasCfg config.DCAS, storeProvider ctxcommon.OperationStoreProvider, aggregator *blockchain.Aggregator) error {
+	loadedContexts, err := c.loadNewContexts(handlers, dcasCfg, storeProvider, aggregator)
 	if err != nil {
 		return err
 	}
@@ -212,13 +256,13 @@ func (c *channelController) loadContexts(handlers []sidetreehandler.Config, dcas
 	return nil
 }
 
-func (c *channelController) loadNewContexts(handlers []sidetreehandler.Config, dcasCfg config.DCAS, storeProvider ctxcommon.OperationStoreProvider) ([]*context, error) {
+func (c *channelController) loadNewContexts(handlers []sidetreehandler.Config, dcasCfg config.DCAS, storeProvider ctxcommon.OperationStoreProvider, aggregator *blockchain.Aggregator) ([]*context, error) {
 	var contexts []*context
 
 	for _, handlerCfg := range handlers {
 		ctx, err := newContext(
 			c.channelID, handlerCfg, dcasCfg, c.sidetreeCfgService, c.ContextProviders,
-			storeProvider, c.RESTConfig, c.OperationProcessorProvider)
+			storeProvider, c.RESTConfig, c.OperationProcessorProvider, aggregator)
 		if err != nil {
 			return nil, err
 		}
@@ -349,7 +393,17 @@ func (c *channelController) loadDCASConfig() (config.DCAS, error) {
 	return c.sidetreeCfgService.LoadDCAS()
 }
 
+// ForNamespace returns the protocol client for the given namespace. It's invoked by the DCAS garbage collector
+// concurrently with config updates, so the contexts are read under the mutex.
 func (c *channelController) ForNamespace(ns string) (protocol.Client, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.protocolClient(ns)
+}
+
+// protocolClient returns the protocol client for the given namespace. The caller must hold the mutex.
+func (c *channelController) protocolClient(ns string) (protocol.Client, error) {
 	ctx, ok := c.contexts[ns]
 	if !ok {
 		return nil, errors.Errorf("protocol: context not found for namespace [%s]", ns)
@@ -358,16 +412,142 @@ func (c *channelController) ForNamespace(ns string) (protocol.Client, error) {
 	return ctx.Protocol(), nil
 }
 
-func (c *channelController) restartObserver(observerCfg config.Observer) error {
+func (c *channelController) restartObserver(observerCfg config.Observer, dcasCfg config.DCAS) error {
 	if c.observer != nil {
 		c.observer.Stop()
 	}
 
+	if c.notifier != nil {
+		c.notifier.UseChaincodeEvents(observerCfg.UseChaincodeEvents, dcasCfg.ChaincodeName)
+	}
+
 	c.observer = newObserverController(c.channelID, c.PeerConfig, observerCfg, c.ObserverProviders, c.txnChan, c)
 
 	return c.observer.Start()
 }
 
+// restartDCASGarbageCollector stops the current DCAS garbage collector (if any) and, if garbage collection is
+// enabled, starts a new one
+func (c *channelController) restartDCASGarbageCollector(cfg config.DCASGarbageCollector, dcasCfg config.DCAS) {
+	if c.gc != nil {
+		c.gc.Stop()
+		c.gc = nil
+	}
+
+	if cfg.Period == 0 {
+		logger.Debugf("[%s] DCAS garbage collection is disabled", c.channelID)
+
+		return
+	}
+
+	c.gc = dcasgc.New(c.channelID, dcasCfg, cfg,
+		&dcasgc.Providers{
+			Ledger:            c.LedgerProvider.GetLedger(c.channelID),
+			DCASProvider:      c.DCASProvider,
+			OffLedger:         c.OffLedgerProvider,
+			ProtocolClients:   c,
+			FileIndexProvider: c,
+		},
+	)
+
+	c.gc.Start()
+}
+
+// GetFileIndexes returns the index documents of the file handlers that store files in the given DCAS collection.
+// The file handlers of all of the peers in this peer's org are included (not only the file handlers that are
+// configured on this peer) since the files of any of these handlers may be stored in the collection. If the index
+// document of a file handler can't be resolved then an error is returned since its files would otherwise be collected.
+func (c *channelController) GetFileIndexes(chaincodeName, collection string) ([]dcasgc.FileIndex, error) {
+	fileHandlers, err := c.sidetreeCfgService.LoadFileHandlers(c.PeerConfig.MSPID(), "")
+	if err != nil {
+		if errors.Cause(err) != cfgservice.ErrConfigNotFound {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var fileIndexes []dcasgc.FileIndex
+
+	docIDs := make(map[string]struct{})
+
+	for _, fh := range fileHandlers {
+		if fh.ChaincodeName != chaincodeName || fh.Collection != collection || fh.IndexDocID == "" {
+			continue
+		}
+
+		if _, ok := docIDs[fh.IndexDocID]; ok {
+			continue
+		}
+
+		docIDs[fh.IndexDocID] = struct{}{}
+
+		resolver, err := c.getIndexVersionResolver(fh.IndexNamespace)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "unable to get resolver for index document [%s]", fh.IndexDocID)
+		}
+
+		fileIndexes = append(fileIndexes, dcasgc.FileIndex{DocID: fh.IndexDocID, Resolver: resolver})
+	}
+
+	return fileIndexes, nil
+}
+
+// restartDCASIntegrityScanner stops the current DCAS integrity scanner (if any) and, if the background scan is
+// enabled, starts a new one
+func (c *channelController) restartDCASIntegrityScanner(cfg config.DCASIntegrityScan, dcasCfg config.DCAS) {
+	if c.scanner != nil {
+		c.scanner.Stop()
+		c.scanner = nil
+	}
+
+	if cfg.Period == 0 {
+		logger.Debugf("[%s] DCAS integrity scan is disabled", c.channelID)
+
+		return
+	}
+
+	c.scanner = dcasintegrity.New(c.channelID, dcasCfg.ChaincodeName, dcasCfg.Collection, c.OffLedgerProvider)
+	c.scanner.Start(cfg.Period)
+}
+
+// getIntegrityScanner returns the background integrity scanner if it scans the given DCAS collection so that a
+// scan that's requested by the integrity handler doesn't run at the same time as the background scan. Otherwise
+// a new scanner is returned.
+func (c *channelController) getIntegrityScanner(chaincodeName, collection string) *dcasintegrity.Scanner {
+	if c.scanner != nil && c.scanner.Namespace() == chaincodeName && c.scanner.Collection() == collection {
+		return c.scanner
+	}
+
+	return dcasintegrity.New(c.channelID, chaincodeName, collection, c.OffLedgerProvider)
+}
+
+// newAnchorAggregator returns a started anchor aggregator if this peer is a batch writer and
+// anchor aggregation is enabled, otherwise nil is returned
+func (c *channelController) newAnchorAggregator(cfg config.AnchorAggregator, dcasCfg config.DCAS) *blockchain.Aggregator {
+	if !role.IsBatchWriter() || cfg.Window == 0 {
+		return nil
+	}
+
+	logger.Debugf("[%s] Creating anchor aggregator", c.channelID)
+
+	aggregator := blockchain.NewAggregator(c.channelID, dcasCfg.ChaincodeName, cfg, c.ContextProviders.TxnProvider)
+	aggregator.Start()
+
+	return aggregator
+}
+
+func (c *channelController) setAnchorAggregator(aggregator *blockchain.Aggregator) {
+	if c.aggregator != nil {
+		c.aggregator.Stop()
+	}
+
+	c.aggregator = aggregator
+}
+
 func (c *channelController) createContextMap(newContexts []*context) map[string]*contextPair {
 	contextMap := make(map[string]*contextPair)
 	for _, ctx := range newContexts {
@@ -453,7 +633,9 @@ func (c *channelController) loadRESTServices(cfg *restHandlerConfig) error {
 		return err
 	}
 
-	c.loadDCASServices(cfg.dcas)
+	if err := c.loadDCASServices(cfg.dcas); err != nil {
+		return err
+	}
 
 	c.loadBlockchainServices(cfg.blockchain)
 
@@ -487,6 +669,11 @@ func (c *channelController) loadFileService(cfg filehandler.Config) (*service, e
 		return nil, errors.WithMessagef(err, "unable to get document handler for index document [%s]", cfg.IndexDocID)
 	}
 
+	versionResolver, err := c.getIndexVersionResolver(cfg.IndexNamespace)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "unable to get version resolver for index document [%s]", cfg.IndexDocID)
+	}
+
 	s := newService(cfg.BasePath[1:], "", cfg.BasePath)
 
 	if role.IsResolver() && cfg.IndexDocID != "" {
@@ -494,8 +681,17 @@ func (c *channelController) loadFileService(cfg filehandler.Config) (*service, e
 		logger.Debugf("[%s] Authorization tokens for file read handler: %s", c.channelID, cfg.Authorization.ReadTokens)
 
 		s.endpoints = append(s.endpoints,
-			newEndpoint("/identifiers", c.authHandler(cfg.Authorization.ReadTokens, filehandler.NewRetrieveHandler(c.channelID, cfg, docHandler, c.DCASProvider))),
+			newEndpoint("/identifiers", c.authHandler(cfg.Authorization.ReadTokens, filehandler.NewRetrieveHandler(c.channelID, cfg, docHandler, versionResolver, c.DCASProvider))),
 		)
+
+		// The listing exposes the names of all files so it's only served to clients with a read token
+		if cfg.ListingEnabled && len(cfg.Authorization.ReadTokens) > 0 {
+			logger.Debugf("[%s] Adding file list handler for base path [%s]", c.channelID, cfg.BasePath)
+
+			s.endpoints = append(s.endpoints,
+				newEndpoint("", c.authHandler(cfg.Authorization.ReadTokens, filehandler.NewListHandler(c.channelID, cfg, docHandler, versionResolver, c.DCASProvider))),
+			)
+		}
 	}
 
 	if role.IsBatchWriter() {
@@ -505,30 +701,107 @@ func (c *channelController) loadFileService(cfg filehandler.Config) (*service, e
 		s.endpoints = append(s.endpoints,
 			newEndpoint("/operations", c.authHandler(cfg.Authorization.WriteTokens, filehandler.NewUploadHandler(c.channelID, cfg, c.DCASProvider))),
 		)
+
+		if cfg.IndexDocID != "" {
+			pc, err := c.protocolClient(cfg.IndexNamespace)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "unable to get protocol client for index document [%s]", cfg.IndexDocID)
+			}
+
+			logger.Debugf("[%s] Adding file publish handler for base path [%s]", c.channelID, cfg.BasePath)
+
+			s.endpoints = append(s.endpoints,
+				newEndpoint("/publish", c.authHandler(cfg.Authorization.WriteTokens, filehandler.NewPublishHandler(c.channelID, cfg, c.DCASProvider, docHandler, pc))),
+			)
+		}
 	}
 
 	return s, nil
 }
 
-func (c *channelController) loadDCASServices(handlerCfg []dcashandler.Config) {
+func (c *channelController) loadDCASServices(handlerCfg []dcashandler.Config) error {
 	for _, cfg := range handlerCfg {
-		c.services = append(c.services, c.loadDCASService(cfg))
+		s, err := c.loadDCASService(cfg)
+		if err != nil {
+			return err
+		}
+
+		c.services = append(c.services, s)
 	}
+
+	return nil
 }
 
-func (c *channelController) loadDCASService(cfg dcashandler.Config) *service {
+func (c *channelController) loadDCASService(cfg dcashandler.Config) (*service, error) {
 	logger.Debugf("[%s] Adding DCAS services for base path [%s]", c.channelID, cfg.BasePath)
 	logger.Debugf("[%s] Authorization tokens for DCAS services - read: %s, write: %s", c.channelID, cfg.Authorization.ReadTokens, cfg.Authorization.WriteTokens)
 
+	if len(cfg.Quotas) > 0 {
+		if err := c.validateUsageCollection(cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	s := newService("cas", apiVersion, cfg.BasePath)
 
+	quotas := dcasquota.New(c.channelID, cfg.ChaincodeName, cfg.Collection, cfg.UsageCollection, cfg.Quotas, c.OffLedgerProvider)
+
 	s.endpoints = append(s.endpoints,
 		newEndpoint(versionPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewVersionHandler(c.channelID, cfg))),
+		newEndpoint(batchPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewBatchHandler(c.channelID, cfg, c.DCASProvider))),
+		newEndpoint(batchPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewBatchPostHandler(c.channelID, cfg, c.DCASProvider))),
+	)
+
+	if cfg.IPFSEnabled {
+		logger.Debugf("[%s] Adding IPFS read handlers for base path [%s]", c.channelID, cfg.BasePath)
+
+		s.endpoints = append(s.endpoints,
+			newEndpoint(ipfsGatewayPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewIPFSGatewayHandler(c.channelID, cfg, c.DCASProvider))),
+			newEndpoint(ipfsCatPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewIPFSCatHandler(c.channelID, cfg, c.DCASProvider))),
+			newEndpoint(ipfsCatPath, c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewIPFSCatGetHandler(c.channelID, cfg, c.DCASProvider))),
+		)
+	}
+
+	if len(cfg.Quotas) > 0 {
+		logger.Debugf("[%s] Adding DCAS usage handler for base path [%s]", c.channelID, cfg.BasePath)
+
+		s.endpoints = append(s.endpoints,
+			newEndpoint(usagePath, c.authHandler(cfg.Authorization.WriteTokens, dcashandler.NewUsageHandler(c.channelID, cfg, quotas))),
+		)
+	}
+
+	s.endpoints = append(s.endpoints,
 		newEndpoint("", c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewRetrieveHandler(c.channelID, cfg, c.DCASProvider))),
-		newEndpoint("", c.authHandler(cfg.Authorization.WriteTokens, dcashandler.NewUploadHandler(c.channelID, cfg, c.DCASProvider))),
+		newEndpoint("", c.authHandler(cfg.Authorization.ReadTokens, dcashandler.NewHeadHandler(c.channelID, cfg, c.DCASProvider))),
+		newEndpoint("", c.authHandler(cfg.Authorization.WriteTokens, dcashandler.NewUploadHandler(c.channelID, cfg, c.DCASProvider, quotas))),
 	)
 
-	return s
+	if len(cfg.Authorization.AdminTokens) > 0 {
+		logger.Debugf("[%s] Adding DCAS integrity handler for base path [%s]", c.channelID, cfg.BasePath)
+
+		s.endpoints = append(s.endpoints,
+			newEndpoint(integrityPath, c.authHandler(cfg.Authorization.AdminTokens,
+				dcashandler.NewIntegrityHandler(c.channelID, cfg, c.getIntegrityScanner(cfg.ChaincodeName, cfg.Collection)))),
+		)
+	}
+
+	return s, nil
+}
+
+// validateUsageCollection ensures that the collection in which the quota usage is stored is an off-ledger collection
+// of the DCAS handler's chaincode. (The config validator can't check this since it doesn't have access to the
+// collection configuration of the chaincode.) Note that a DCAS collection may not be used since its keys must be CIDs.
+func (c *channelController) validateUsageCollection(cfg dcashandler.Config) error {
+	collCfg, err := c.CollConfigProvider.ForChannel(c.channelID).Config(cfg.ChaincodeName, cfg.UsageCollection)
+	if err != nil {
+		return errors.WithMessagef(err, "unable to get config of usage collection [%s:%s] for DCAS handler [%s]", cfg.ChaincodeName, cfg.UsageCollection, cfg.BasePath)
+	}
+
+	if collCfg.Type != pb.CollectionType_COL_OFFLEDGER {
+		return errors.Errorf("usage collection [%s:%s] for DCAS handler [%s] must be an off-ledger collection but is of type [%s]", cfg.ChaincodeName, cfg.UsageCollection, cfg.BasePath, collCfg.Type)
+	}
+
+	return nil
 }
 
 func (c *channelController) loadBlockchainServices(handlerCfg []blockchainhandler.Config) {
@@ -549,7 +822,7 @@ func (c *channelController) loadBlockchainService(cfg blockchainhandler.Config)
 
 	readTokens := cfg.Authorization.ReadTokens
 
-	return newService("blockchain", apiVersion, cfg.BasePath,
+	s := newService("blockchain", apiVersion, cfg.BasePath,
 		newEndpoint(versionPath, c.authHandler(readTokens, blockchainhandler.NewVersionHandler(c.channelID, cfg))),
 		newEndpoint(timePath, c.authHandler(readTokens, blockchainhandler.NewTimeHandler(c.channelID, cfg, c.BlockchainProvider))),
 		newEndpoint(timePath, c.authHandler(readTokens, blockchainhandler.NewTimeByHashHandler(c.channelID, cfg, c.BlockchainProvider))),
@@ -565,6 +838,18 @@ func (c *channelController) loadBlockchainService(cfg blockchainhandler.Config)
 		newEndpoint(configBlockPath, c.authHandler(readTokens, blockchainhandler.NewConfigBlockByHashHandlerWithEncoding(c.channelID, cfg, c.BlockchainProvider))),
 		newEndpoint(configBlockPath, c.authHandler(readTokens, blockchainhandler.NewConfigBlockByHashHandler(c.channelID, cfg, c.BlockchainProvider))),
 	)
+
+	if cfg.ReceiptCollection != "" {
+		logger.Debugf("[%s] Adding anchor receipt service for base path [%s] - chaincode [%s], collection [%s]", c.channelID, cfg.BasePath, cfg.ReceiptChaincodeName, cfg.ReceiptCollection)
+
+		receiptStore := receipt.NewStore(c.channelID, cfg.ReceiptChaincodeName, cfg.ReceiptCollection, c.OffLedgerProvider)
+
+		s.endpoints = append(s.endpoints,
+			newEndpoint(receiptsPath, c.authHandler(readTokens, blockchainhandler.NewReceiptHandler(c.channelID, cfg, receiptStore, c.BlockchainProvider))),
+		)
+	}
+
+	return s
 }
 
 func (c *channelController) loadDiscoveryService(cfg discoveryhandler.Config) *service {
@@ -589,6 +874,19 @@ func (c *channelController) getDocHandler(ns string) (*dochandler.DocumentHandle
 	return ctx.rest.docHandler, nil
 }
 
+func (c *channelController) getIndexVersionResolver(ns string) (*filehandler.IndexVersionResolver, error) {
+	ctx, ok := c.contexts[ns]
+	if !ok {
+		return nil, errors.Errorf("context not found for namespace [%s]", ns)
+	}
+
+	if ctx.rest == nil || ctx.rest.opStore == nil {
+		return nil, errors.Errorf("no operation store for namespace [%s]", ns)
+	}
+
+	return filehandler.NewIndexVersionResolver(ns, ctx.rest.opStore, ctx.Protocol()), nil
+}
+
 // compareAndSetTxID sets the value of the transaction ID if it's not already set and returns true.
 // If the transaction ID is already set then false is returned.
 func (c *channelController) compareAndSetTxID(txID string) bool {
@@ -610,7 +908,7 @@ func (c *channelController) authHandler(tokenNames []string, handler common.HTTP
 		tokens[i] = c.RESTConfig.SidetreeAPIToken(name)
 	}
 
-	return authhandler.New(c.channelID, tokens, handler)
+	return authhandler.NewWithTokenNames(c.channelID, tokenNames, tokens, handler)
 }
 
 func (c *channelController) localServices() []discovery.Service {
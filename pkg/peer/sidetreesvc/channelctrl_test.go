@@ -12,6 +12,7 @@ import (
 	"time"
 
 	cb "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
 	olmocks "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/mocks"
 	ledgerconfig "github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
@@ -27,6 +28,7 @@ import (
 	cfgmocks "github.com/trustbloc/sidetree-fabric/pkg/config/mocks"
 	sidetreectx "github.com/trustbloc/sidetree-fabric/pkg/context"
 	ctxmocks "github.com/trustbloc/sidetree-fabric/pkg/context/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
 	"github.com/trustbloc/sidetree-fabric/pkg/observer"
 	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
@@ -105,6 +107,7 @@ func TestChannelController_Update(t *testing.T) {
 			Collection:     "schemas",
 			IndexNamespace: "file:idx",
 			IndexDocID:     "file:idx:1234",
+			ListingEnabled: true,
 			Authorization: authhandler.Config{
 				ReadTokens:  []string{"content_r"},
 				WriteTokens: []string{"content_r", "content_w"},
@@ -223,7 +226,7 @@ func TestChannelController_Update(t *testing.T) {
 
 	time.Sleep(20 * time.Millisecond)
 	require.Len(t, ctrl.Invocations()[eventMethod], count+1)
-	require.Len(t, m.RESTHandlers(), 11)
+	require.Len(t, m.RESTHandlers(), 16)
 
 	localServices := m.localServices()
 	require.Len(t, localServices, 4)
@@ -266,6 +269,114 @@ func TestChannelController_Update(t *testing.T) {
 		require.Len(t, ctrl.Invocations()[eventMethod], count+1)
 	})
 
+	t.Run("Update peer config with anchor aggregator -> success", func(t *testing.T) {
+		stConfigService.LoadSidetreePeerReturns(config.SidetreePeer{AnchorAggregator: config.AnchorAggregator{Window: time.Second}}, nil)
+		defer stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.aggregator)
+
+		aggregator := m.aggregator
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.aggregator)
+		require.False(t, aggregator == m.aggregator)
+
+		stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+		require.NoError(t, m.load())
+		require.Nil(t, m.aggregator)
+	})
+
+	t.Run("Update peer config with DCAS garbage collector -> success", func(t *testing.T) {
+		stConfigService.LoadSidetreePeerReturns(config.SidetreePeer{DCASGarbageCollector: config.DCASGarbageCollector{Period: time.Minute}}, nil)
+		defer stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.gc)
+
+		gc := m.gc
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.gc)
+		require.False(t, gc == m.gc)
+
+		stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+		require.NoError(t, m.load())
+		require.Nil(t, m.gc)
+	})
+
+	t.Run("Get file indexes", func(t *testing.T) {
+		require.NoError(t, m.load())
+
+		fileIndexes, err := m.GetFileIndexes("files", "schemas")
+		require.NoError(t, err)
+		require.Len(t, fileIndexes, 1)
+		require.Equal(t, "file:idx:1234", fileIndexes[0].DocID)
+		require.NotNil(t, fileIndexes[0].Resolver)
+
+		// The file handlers of all of the peers in the org are loaded
+		mspID, peerID := stConfigService.LoadFileHandlersArgsForCall(stConfigService.LoadFileHandlersCallCount() - 1)
+		require.Equal(t, msp1, mspID)
+		require.Empty(t, peerID)
+
+		fileIndexes, err = m.GetFileIndexes("files", "other")
+		require.NoError(t, err)
+		require.Empty(t, fileIndexes)
+
+		defer stConfigService.LoadFileHandlersReturns(fileHandlers, nil)
+
+		// The same index document from the file handlers of two peers
+		stConfigService.LoadFileHandlersReturns([]filehandler.Config{fileHandlers[0], fileHandlers[0]}, nil)
+
+		fileIndexes, err = m.GetFileIndexes("files", "schemas")
+		require.NoError(t, err)
+		require.Len(t, fileIndexes, 1)
+
+		stConfigService.LoadFileHandlersReturns([]filehandler.Config{
+			{ChaincodeName: "files", Collection: "schemas", IndexNamespace: "file:other", IndexDocID: "file:other:1234"},
+		}, nil)
+
+		_, err = m.GetFileIndexes("files", "schemas")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "context not found for namespace [file:other]")
+
+		stConfigService.LoadFileHandlersReturns(nil, cfgservice.ErrConfigNotFound)
+
+		fileIndexes, err = m.GetFileIndexes("files", "schemas")
+		require.NoError(t, err)
+		require.Empty(t, fileIndexes)
+
+		errExpected := errors.New("injected config error")
+		stConfigService.LoadFileHandlersReturns(nil, errExpected)
+
+		_, err = m.GetFileIndexes("files", "schemas")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Update peer config with DCAS integrity scan -> success", func(t *testing.T) {
+		stConfigService.LoadSidetreePeerReturns(config.SidetreePeer{DCASIntegrityScan: config.DCASIntegrityScan{Period: time.Minute}}, nil)
+		defer stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.scanner)
+
+		scanner := m.scanner
+
+		require.NoError(t, m.load())
+		require.NotNil(t, m.scanner)
+		require.False(t, scanner == m.scanner)
+
+		// The background scanner is shared with the integrity handler for the same DCAS collection
+		require.True(t, m.scanner == m.getIntegrityScanner(m.scanner.Namespace(), m.scanner.Collection()))
+		require.False(t, m.scanner == m.getIntegrityScanner("cascc", "cas"))
+
+		stConfigService.LoadSidetreePeerReturns(sidetreePeerCfg, nil)
+		require.NoError(t, m.load())
+		require.Nil(t, m.scanner)
+		require.NotNil(t, m.getIntegrityScanner("cascc", "cas"))
+	})
+
 	t.Run("Update consortium config -> success", func(t *testing.T) {
 		count := len(ctrl.Invocations()[eventMethod])
 		m.handleUpdate(&ledgerconfig.KeyValue{
@@ -391,6 +502,13 @@ func TestChannelController_LoadDCASHandlers(t *testing.T) {
 
 	cacheProvider := &ctxmocks.CachingOpProcessorProvider{}
 
+	collConfigRetriever := extmocks.NewCollectionConfigRetriever().
+		WithCollectionConfig(&pb.StaticCollectionConfig{Name: "usage", Type: pb.CollectionType_COL_OFFLEDGER}).
+		WithCollectionConfig(&pb.StaticCollectionConfig{Name: "cas", Type: pb.CollectionType_COL_DCAS})
+
+	collConfigProvider := &extmocks.CollectionConfigProvider{}
+	collConfigProvider.ForChannelReturns(collConfigRetriever)
+
 	providers := &providers{
 		ContextProviders: &ContextProviders{
 			Providers: &sidetreectx.Providers{
@@ -401,11 +519,12 @@ func TestChannelController_LoadDCASHandlers(t *testing.T) {
 				OperationProcessorProvider: cacheProvider,
 			},
 		},
-		PeerConfig:        peerConfig,
-		ConfigProvider:    configProvider,
-		BlockPublisher:    extmocks.NewBlockPublisherProvider(),
-		RESTConfig:        restCfg,
-		DiscoveryProvider: discoveryProvider,
+		PeerConfig:         peerConfig,
+		ConfigProvider:     configProvider,
+		BlockPublisher:     extmocks.NewBlockPublisherProvider(),
+		RESTConfig:         restCfg,
+		DiscoveryProvider:  discoveryProvider,
+		CollConfigProvider: collConfigProvider,
 	}
 
 	stConfigService := &cfgmocks.SidetreeConfigService{}
@@ -433,7 +552,39 @@ func TestChannelController_LoadDCASHandlers(t *testing.T) {
 
 	stConfigService.LoadDCASHandlersReturns(dcasHandlers, nil)
 	require.NoError(t, c.load())
-	require.Len(t, c.RESTHandlers(), 3)
+	require.Len(t, c.RESTHandlers(), 6)
+
+	// DCAS handlers with IPFS read handlers
+	dcasHandlers[0].IPFSEnabled = true
+
+	require.NoError(t, c.load())
+	require.Len(t, c.RESTHandlers(), 9)
+
+	// DCAS handlers with the integrity handler
+	dcasHandlers[0].Authorization.AdminTokens = []string{"cas_admin"}
+
+	require.NoError(t, c.load())
+	require.Len(t, c.RESTHandlers(), 10)
+
+	// DCAS handlers with the usage handler
+	dcasHandlers[0].UsageCollection = "usage"
+	dcasHandlers[0].Quotas = map[string]dcasquota.Quota{"cas_w": {MaxBytes: 1000}}
+
+	require.NoError(t, c.load())
+	require.Len(t, c.RESTHandlers(), 11)
+
+	// The usage collection must be an off-ledger collection of the chaincode
+	dcasHandlers[0].UsageCollection = "cas"
+
+	err := c.load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "usage collection [cascc:cas] for DCAS handler [/cas] must be an off-ledger collection")
+
+	dcasHandlers[0].UsageCollection = "other"
+
+	err = c.load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to get config of usage collection [cascc:other]")
 }
 
 func TestChannelController_LoadBlockchainHandlers(t *testing.T) {
@@ -512,6 +663,14 @@ func TestChannelController_LoadBlockchainHandlers(t *testing.T) {
 	stConfigService.LoadBlockchainHandlersReturns(blockchainHandlers, nil)
 	require.NoError(t, c.load())
 	require.Len(t, c.RESTHandlers(), 14)
+
+	// Blockchain handlers with anchor receipts
+	blockchainHandlers[0].ReceiptChaincodeName = "document"
+	blockchainHandlers[0].ReceiptCollection = "receipts"
+
+	stConfigService.LoadBlockchainHandlersReturns(blockchainHandlers, nil)
+	require.NoError(t, c.load())
+	require.Len(t, c.RESTHandlers(), 15)
 }
 
 func TestChannelController_LoadDiscoveryHandlers(t *testing.T) {
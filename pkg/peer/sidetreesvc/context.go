@@ -16,6 +16,7 @@ import (
 	"github.com/trustbloc/sidetree-fabric/pkg/common"
 	"github.com/trustbloc/sidetree-fabric/pkg/config"
 	sidetreectx "github.com/trustbloc/sidetree-fabric/pkg/context"
+	"github.com/trustbloc/sidetree-fabric/pkg/context/blockchain"
 	"github.com/trustbloc/sidetree-fabric/pkg/context/cas"
 	ctxcommon "github.com/trustbloc/sidetree-fabric/pkg/context/common"
 	"github.com/trustbloc/sidetree-fabric/pkg/rest/sidetreehandler"
@@ -72,7 +73,7 @@ type ContextProviders struct {
 
 func newContext(channelID string, handlerCfg sidetreehandler.Config, dcasCfg config.DCAS, cfg config.SidetreeService,
 	providers *ContextProviders, opStoreProvider ctxcommon.OperationStoreProvider, tokenProvider tokenProvider,
-	opp cachingOpProcessorProvider) (*context, error) {
+	opp cachingOpProcessorProvider, aggregator *blockchain.Aggregator) (*context, error) {
 	logger.Debugf("[%s] Creating Sidetree context for [%s]", channelID, handlerCfg.Namespace)
 
 	dcasClient, err := providers.DCASProvider.GetDCASClient(channelID, dcasCfg.ChaincodeName, dcasCfg.Collection)
@@ -80,7 +81,7 @@ func newContext(channelID string, handlerCfg sidetreehandler.Config, dcasCfg con
 		return nil, err
 	}
 
-	ctx, err := newSidetreeContext(channelID, handlerCfg.Namespace, cfg, handlerCfg.DocType, dcasCfg, opStoreProvider, cas.New(dcasClient), providers)
+	ctx, err := newSidetreeContext(channelID, handlerCfg.Namespace, cfg, handlerCfg.DocType, dcasCfg, opStoreProvider, cas.New(dcasClient), aggregator, providers)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +113,7 @@ func newContext(channelID string, handlerCfg sidetreehandler.Config, dcasCfg con
 	}, nil
 }
 
-func newSidetreeContext(channelID, namespace string, cfg config.SidetreeService, docType common.DocumentType, dcasCfg config.DCAS, opStoreProvider ctxcommon.OperationStoreProvider, casClient casApi.Client, providers *ContextProviders) (*sidetreectx.SidetreeContext, error) {
+func newSidetreeContext(channelID, namespace string, cfg config.SidetreeService, docType common.DocumentType, dcasCfg config.DCAS, opStoreProvider ctxcommon.OperationStoreProvider, casClient casApi.Client, aggregator *blockchain.Aggregator, providers *ContextProviders) (*sidetreectx.SidetreeContext, error) {
 	protocols, err := cfg.LoadProtocols(namespace)
 	if err != nil {
 		return nil, err
@@ -146,5 +147,5 @@ func newSidetreeContext(channelID, namespace string, cfg config.SidetreeService,
 		protocolVersions = append(protocolVersions, pv)
 	}
 
-	return sidetreectx.New(channelID, namespace, dcasCfg, casClient, protocolVersions, providers.Providers)
+	return sidetreectx.New(channelID, namespace, dcasCfg, sidetreeCfg, casClient, protocolVersions, aggregator, providers.Providers)
 }
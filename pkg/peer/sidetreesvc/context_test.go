@@ -65,7 +65,7 @@ func TestContext(t *testing.T) {
 		stConfigService := &cfgmocks.SidetreeConfigService{}
 		stConfigService.LoadProtocolsReturns(protocolVersions, nil)
 
-		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider)
+		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider, nil)
 		require.NoError(t, err)
 		require.NotNil(t, ctx)
 
@@ -96,7 +96,7 @@ func TestContext(t *testing.T) {
 		opStoreProvider := &mocks.OperationStoreProvider{}
 		opStoreProvider.ForNamespaceReturns(nil, errExpected)
 
-		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, opStoreProvider, restCfg, cacheProvider)
+		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, opStoreProvider, restCfg, cacheProvider, nil)
 		require.EqualError(t, err, errExpected.Error())
 		require.Nil(t, ctx)
 	})
@@ -117,7 +117,7 @@ func TestContext(t *testing.T) {
 		stConfigService.LoadProtocolsReturns(protocolVersions, nil)
 		stConfigService.LoadSidetreeReturns(config.Sidetree{}, errExpected)
 
-		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider)
+		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider, nil)
 		require.EqualError(t, err, errExpected.Error())
 		require.Nil(t, ctx)
 	})
@@ -125,7 +125,7 @@ func TestContext(t *testing.T) {
 	t.Run("No protocols -> error", func(t *testing.T) {
 		stConfigService := &cfgmocks.SidetreeConfigService{}
 
-		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider)
+		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider, nil)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "no protocols defined")
 		require.Nil(t, ctx)
@@ -136,7 +136,7 @@ func TestContext(t *testing.T) {
 		stConfigService := &cfgmocks.SidetreeConfigService{}
 		stConfigService.LoadProtocolsReturns(nil, errExpected)
 
-		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider)
+		ctx, err := newContext(channel1, nsCfg, dcasCfg, stConfigService, ctxProviders, &mocks.OperationStoreProvider{}, restCfg, cacheProvider, nil)
 		require.EqualError(t, err, errExpected.Error())
 		require.Nil(t, ctx)
 	})
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/extensions/collections/api/support"
 	ledgerconfig "github.com/trustbloc/fabric-peer-ext/pkg/config/ledgerconfig/config"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
@@ -49,15 +50,20 @@ type discoveryProvider interface {
 	ServicesForChannel(channelID string) []discovery.Service
 }
 
+type collectionConfigProvider interface {
+	ForChannel(channelID string) support.CollectionConfigRetriever
+}
+
 type providers struct {
 	*ContextProviders
 
-	PeerConfig        peerConfig
-	RESTConfig        restConfig
-	ConfigProvider    configServiceProvider
-	ObserverProviders *observer.ClientProviders
-	BlockPublisher    ctxcommon.BlockPublisherProvider
-	DiscoveryProvider discoveryProvider
+	PeerConfig         peerConfig
+	RESTConfig         restConfig
+	ConfigProvider     configServiceProvider
+	ObserverProviders  *observer.ClientProviders
+	BlockPublisher     ctxcommon.BlockPublisherProvider
+	DiscoveryProvider  discoveryProvider
+	CollConfigProvider collectionConfigProvider
 }
 
 // Provider implements a Sidetree services provider which is responsible for managing Sidetree
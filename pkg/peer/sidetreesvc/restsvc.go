@@ -81,6 +81,7 @@ type restHandlers struct {
 	namespace  string
 	service    *service
 	docHandler *dochandler.DocumentHandler
+	opStore    processor.OperationStoreClient
 }
 
 type tokenProvider interface {
@@ -151,6 +152,7 @@ func newRESTHandlers(
 		namespace:  cfg.Namespace,
 		service:    service,
 		docHandler: docHandler,
+		opStore:    opStore,
 	}, nil
 }
 
@@ -61,6 +61,9 @@ func (v *FileIdxValidator) IsValidOriginalDocument(payload []byte) error {
 		if name == "" {
 			return errors.New("missing file name in mapping")
 		}
+		if err := filehandler.ValidateResourceName(name); err != nil {
+			return errors.WithMessage(err, "invalid file name in mapping")
+		}
 		if id == "" {
 			return errors.Errorf("missing ID for file name [%s]", name)
 		}
@@ -134,6 +137,26 @@ func validatePatch(p patch.Patch) error {
 		if !strings.HasPrefix(path, jsonPatchBasePath) {
 			return errors.New("only the mappings section of a file index document may be modified")
 		}
+
+		if err := validateMappingName(strings.TrimPrefix(path, jsonPatchBasePath)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateMappingName validates the file name in the given JSON pointer token. Since the file name may
+// contain multiple path segments, the slashes in the name must be escaped as "~1" (RFC 6901).
+func validateMappingName(token string) error {
+	if strings.Contains(token, "/") {
+		return errors.New("file name in path must be a single JSON pointer token - slashes must be escaped as ~1")
+	}
+
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+	if err := filehandler.ValidateResourceName(name); err != nil {
+		return errors.WithMessage(err, "invalid file name in path")
 	}
 
 	return nil
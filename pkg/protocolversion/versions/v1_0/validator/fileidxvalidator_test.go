@@ -81,13 +81,50 @@ func TestDocumentValidator_IsValidOriginalDocument(t *testing.T) {
 		require.EqualError(t, v.IsValidOriginalDocument(docBytes), "missing ID for file name [schema1.json]")
 	})
 
+	t.Run("Invalid file name", func(t *testing.T) {
+		doc := &filehandler.FileIndexDoc{
+			UniqueSuffix: "1234",
+			FileIndex: filehandler.FileIndex{
+				BasePath: "/schema",
+				Mappings: map[string]string{
+					"../schema1.json": "1234567",
+				},
+			},
+		}
+		docBytes, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		err = v.IsValidOriginalDocument(docBytes)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file name in mapping")
+	})
+
+	t.Run("File name not normalized", func(t *testing.T) {
+		doc := &filehandler.FileIndexDoc{
+			UniqueSuffix: "1234",
+			FileIndex: filehandler.FileIndex{
+				BasePath: "/schema",
+				Mappings: map[string]string{
+					"/v1//schema1.json": "1234567",
+				},
+			},
+		}
+		docBytes, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		err = v.IsValidOriginalDocument(docBytes)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not normalized")
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		doc := &filehandler.FileIndexDoc{
 			UniqueSuffix: "1234",
 			FileIndex: filehandler.FileIndex{
 				BasePath: "/schema",
 				Mappings: map[string]string{
-					"schema1.json": "1234567",
+					"schema1.json":    "1234567",
+					"v1/schema2.json": "7654321",
 				},
 			},
 		}
@@ -144,10 +181,33 @@ func TestDocumentValidator_IsValidPayload(t *testing.T) {
 		require.EqualError(t, v.IsValidPayload(req), "invalid JSON patch: only the mappings section of a file index document may be modified")
 	})
 
+	t.Run("Unescaped slash in file name", func(t *testing.T) {
+		req, err := getUpdateRequest(`[{"op": "add", "path": "/fileIndex/mappings/v1/schema1.json", "value": "ew3e23w3"}]`)
+		require.NoError(t, err)
+
+		err = v.IsValidPayload(req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "slashes must be escaped as ~1")
+	})
+
+	t.Run("Path traversal in file name", func(t *testing.T) {
+		req, err := getUpdateRequest(`[{"op": "add", "path": "/fileIndex/mappings/v1~1..~1..~1schema1.json", "value": "ew3e23w3"}]`)
+		require.NoError(t, err)
+
+		err = v.IsValidPayload(req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file name in path")
+		require.Contains(t, err.Error(), "relative path segment not allowed")
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		req, err := getUpdateRequest(`[{"op": "add", "path": "/fileIndex/mappings/schema1.json", "value": "ew3e23w3"}]`)
 		require.NoError(t, err)
 		require.NoError(t, v.IsValidPayload(req))
+
+		req, err = getUpdateRequest(`[{"op": "add", "path": "/fileIndex/mappings/schemas~1v1~1person.json", "value": "ew3e23w3"}]`)
+		require.NoError(t, err)
+		require.NoError(t, v.IsValidPayload(req))
 	})
 }
 
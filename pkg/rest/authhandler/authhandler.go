@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package authhandler
 
 import (
+	"context"
 	"crypto/subtle"
 	"net/http"
 
@@ -21,15 +22,24 @@ const (
 	tokenPrefix = "Bearer "
 )
 
+type tokenNameKey struct{}
+
 // Handler authorizes the request before delegating to the target handler
 type Handler struct {
 	common.HTTPHandler
-	channelID string
-	tokens    []string
+	channelID  string
+	tokens     []string
+	tokenNames []string
 }
 
 // New returns a new auth handler
 func New(channelID string, tokens []string, handler common.HTTPHandler) common.HTTPHandler {
+	return NewWithTokenNames(channelID, nil, tokens, handler)
+}
+
+// NewWithTokenNames returns a new auth handler. The token names correspond (by index) to the given tokens. The name of
+// the token that authorized the request is added to the request context and may be retrieved using TokenName.
+func NewWithTokenNames(channelID string, tokenNames, tokens []string, handler common.HTTPHandler) common.HTTPHandler {
 	if len(tokens) == 0 {
 		logger.Debugf("[%s] No authorization token(s) specified. Authorization will NOT be performed for %s on path [%s]", channelID, handler.Method(), handler.Path())
 		return handler
@@ -41,9 +51,21 @@ func New(channelID string, tokens []string, handler common.HTTPHandler) common.H
 		HTTPHandler: handler,
 		channelID:   channelID,
 		tokens:      tokens,
+		tokenNames:  tokenNames,
 	}
 }
 
+// TokenName returns the name of the token that authorized the given request. An empty string is returned if
+// the request was not authorized with a named token.
+func TokenName(req *http.Request) string {
+	name, ok := req.Context().Value(tokenNameKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return name
+}
+
 // Handler returns the HTTP request handler
 func (h *Handler) Handler() common.HTTPRequestHandler {
 	return h.handle
@@ -60,7 +82,8 @@ func (h *Handler) Params() map[string]string {
 }
 
 func (h *Handler) handle(w http.ResponseWriter, r *http.Request) {
-	if !h.isAuthorized(r) {
+	i, ok := h.authorizedToken(r)
+	if !ok {
 		logger.Debugf("[%s] Caller is not authorized for %s on path [%s]", h.channelID, h.Method(), h.Path())
 
 		w.WriteHeader(http.StatusUnauthorized)
@@ -74,20 +97,25 @@ func (h *Handler) handle(w http.ResponseWriter, r *http.Request) {
 
 	logger.Debugf("[%s] Caller is authorized for %s on path [%s]", h.channelID, h.Method(), h.Path())
 
+	if i < len(h.tokenNames) {
+		r = r.WithContext(context.WithValue(r.Context(), tokenNameKey{}, h.tokenNames[i]))
+	}
+
 	h.HTTPHandler.Handler()(w, r)
 }
 
-func (h *Handler) isAuthorized(r *http.Request) bool {
+// authorizedToken returns the index of the token that authorizes the request and true if the request is authorized.
+func (h *Handler) authorizedToken(r *http.Request) (int, bool) {
 	actHdr := r.Header.Get(authHeader)
 
 	// Compare the header against all tokens. If any match then we allow the request.
-	for _, token := range h.tokens {
+	for i, token := range h.tokens {
 		if subtle.ConstantTimeCompare([]byte(actHdr), []byte(tokenPrefix+token)) == 1 {
-			return true
+			return i, true
 		}
 	}
 
-	return false
+	return -1, false
 }
 
 type paramHolder interface {
@@ -101,6 +101,38 @@ func TestHandler_Handle(t *testing.T) {
 		require.NotNil(t, ph)
 		require.Empty(t, ph.Params())
 	})
+
+	t.Run("Token name", func(t *testing.T) {
+		var tokenName string
+
+		th := &mocks.HTTPHandler{}
+		th.HandlerReturns(func(_ http.ResponseWriter, req *http.Request) {
+			tokenName = TokenName(req)
+		})
+
+		h := NewWithTokenNames(channel1, []string{"name1", "name2"}, []string{"t1", "t2"}, th)
+		require.NotNil(t, h)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/blockchain/info", nil)
+		req.Header.Add(authHeader, tokenPrefix+"t2")
+
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "name2", tokenName)
+
+		tokenName = ""
+
+		h = New(channel1, []string{"t1", "t2"}, th)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/blockchain/info", nil)
+		req.Header.Add(authHeader, tokenPrefix+"t1")
+
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Empty(t, tokenName)
+	})
 }
 
 type handlerWithParams struct {
@@ -12,4 +12,7 @@ type Config struct {
 	ReadTokens []string
 	// WriteTokens contains a set of names of tokens for authorizing write requests
 	WriteTokens []string
+	// AdminTokens contains a set of names of tokens for authorizing administrative requests. Administrative
+	// endpoints are only served if admin tokens are specified.
+	AdminTokens []string
 }
@@ -18,4 +18,9 @@ type Config struct {
 	MaxTransactionsInResponse int
 	// MaxBlocksInResponse is the maximum number of blocks to return for the /blockchain/blocks request
 	MaxBlocksInResponse int
+	// ReceiptChaincodeName is the name of the chaincode that contains the anchor receipt collection
+	ReceiptChaincodeName string
+	// ReceiptCollection is the off-ledger collection that holds anchor receipts. If empty then
+	// the /blockchain/receipts endpoint is not available.
+	ReceiptCollection string
 }
@@ -9,6 +9,7 @@ package blockchainhandler
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"strings"
 
 	"github.com/hyperledger/fabric/protoutil"
@@ -168,3 +169,12 @@ func (v *txnValidator) handleError(err error, ctx *blockvisitor.Context) error {
 
 	return nil
 }
+
+func getAnchorString(value []byte) (string, error) {
+	var txnInfo common.TxnInfo
+	if err := json.Unmarshal(value, &txnInfo); err != nil {
+		return "", err
+	}
+
+	return txnInfo.AnchorString, nil
+}
@@ -18,6 +18,8 @@ const (
 	InvalidMaxBlocks ResultCode = "invalid_max_blocks"
 	// InvalidTimeHash indicates that the time hash parameter is missing or invalid
 	InvalidTimeHash ResultCode = "invalid_time_hash"
+	// InvalidAnchorString indicates that the anchor string parameter is missing or invalid
+	InvalidAnchorString ResultCode = "invalid_anchor_string"
 )
 
 const (
@@ -73,6 +75,19 @@ type Transaction struct {
 	AnchorString        string `json:"anchorString"`
 }
 
+// ReceiptResponse contains the ledger details of a committed anchor
+type ReceiptResponse struct {
+	AnchorString   string `json:"anchorString"`
+	Namespace      string `json:"namespace"`
+	OperationCount int    `json:"operationCount"`
+	TransactionID  string `json:"transactionId"`
+	// BlockNumber is the number of the block in which the anchor was committed (i.e. the Sidetree transaction time)
+	BlockNumber uint64 `json:"blockNumber"`
+	// ValidationCode is the validation code of the committed transaction (e.g. VALID)
+	ValidationCode      string `json:"validationCode"`
+	ProtocolGenesisTime uint64 `json:"protocolGenesisTime"`
+}
+
 // ErrorResponse contains the error code for a failed response
 type ErrorResponse struct {
 	Code string `json:"code"`
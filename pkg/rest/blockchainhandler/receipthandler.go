@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchainhandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+const (
+	anchorParam = "anchor"
+)
+
+type receiptStore interface {
+	Get(anchorString string) (*receipt.Receipt, error)
+}
+
+// Receipt retrieves the receipt of an anchor write
+type Receipt struct {
+	*handler
+	store receiptStore
+}
+
+// NewReceiptHandler returns a new anchor receipt handler
+func NewReceiptHandler(channelID string, cfg Config, store receiptStore, blockchainProvider blockchainClientProvider) *Receipt {
+	return &Receipt{
+		handler: newHandler(
+			channelID, cfg,
+			fmt.Sprintf("%s/receipts/{%s}", cfg.BasePath, anchorParam),
+			http.MethodGet,
+			blockchainProvider,
+		),
+		store: store,
+	}
+}
+
+// Handler returns the request handler
+func (h *Receipt) Handler() common.HTTPRequestHandler {
+	return h.receipt
+}
+
+func (h *Receipt) receipt(w http.ResponseWriter, req *http.Request) {
+	rw := newBlockchainWriter(w)
+
+	resp, err := h.getReceipt(getAnchor(req))
+	if err != nil {
+		rw.WriteError(err)
+		return
+	}
+
+	receiptBytes, err := h.jsonMarshal(resp)
+	if err != nil {
+		logger.Errorf("[%s] Unable to marshal anchor receipt: %s", h.channelID, err)
+
+		rw.WriteError(httpserver.ServerError)
+		return
+	}
+
+	logger.Debugf("[%s] ... returning anchor receipt: %s", h.channelID, receiptBytes)
+
+	rw.Write(receiptBytes)
+}
+
+func (h *Receipt) getReceipt(anchorString string) (*ReceiptResponse, error) {
+	if anchorString == "" {
+		return nil, newBadRequestError(InvalidAnchorString)
+	}
+
+	r, err := h.store.Get(anchorString)
+	if err != nil {
+		if err == receipt.ErrNotFound {
+			return nil, httpserver.NotFoundError
+		}
+
+		logger.Errorf("[%s] Failed to get receipt for anchor [%s]: %s", h.channelID, anchorString, err)
+
+		return nil, httpserver.ServerError
+	}
+
+	blockNum := r.BlockNumber
+	if blockNum == 0 {
+		// The block number wasn't known when the receipt was stored. Resolve it from the ledger.
+		blockNum, err = h.getBlockNumForTxn(r.TxnID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ReceiptResponse{
+		AnchorString:        r.AnchorString,
+		Namespace:           r.Namespace,
+		OperationCount:      r.OperationCount,
+		TransactionID:       r.TxnID,
+		BlockNumber:         blockNum,
+		ValidationCode:      r.ValidationCode,
+		ProtocolGenesisTime: r.ProtocolGenesisTime,
+	}, nil
+}
+
+func (h *Receipt) getBlockNumForTxn(txnID string) (uint64, error) {
+	bcClient, err := h.blockchainClient()
+	if err != nil {
+		return 0, err
+	}
+
+	block, err := bcClient.GetBlockByTxID(txnID)
+	if err != nil {
+		logger.Errorf("[%s] Failed to get block for transaction [%s]: %s", h.channelID, txnID, err)
+
+		return 0, httpserver.ServerError
+	}
+
+	return block.Header.Number, nil
+}
+
+var getAnchor = func(req *http.Request) string {
+	return mux.Vars(req)[anchorParam]
+}
@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchainhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/context/receipt"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+)
+
+func TestNewReceiptHandler(t *testing.T) {
+	h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{}, &mocks.BlockchainClientProvider{})
+	require.NotNil(t, h)
+
+	require.Equal(t, "/blockchain/receipts/{anchor}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+}
+
+func TestReceipt_Handler(t *testing.T) {
+	const anchor = "1.QmWiYUSPYoMhUSPERhMUv4jk5qc5uFf7pP3QjTMLvXKzEC"
+
+	r := &receipt.Receipt{
+		AnchorString:   anchor,
+		Namespace:      "did:sidetree",
+		OperationCount: 1,
+		TxnID:          "txn1",
+		BlockNumber:    1001,
+		ValidationCode: "VALID",
+	}
+
+	bcClient := &mocks.BlockchainClient{}
+	bcProvider := &mocks.BlockchainClientProvider{}
+	bcProvider.ForChannelReturns(bcClient, nil)
+
+	restoreAnchor := getAnchor
+	defer func() { getAnchor = restoreAnchor }()
+
+	getAnchor = func(req *http.Request) string { return anchor }
+
+	t.Run("Success", func(t *testing.T) {
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{receipt: r}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
+
+		resp := &ReceiptResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, anchor, resp.AnchorString)
+		require.Equal(t, r.Namespace, resp.Namespace)
+		require.Equal(t, r.OperationCount, resp.OperationCount)
+		require.Equal(t, r.TxnID, resp.TransactionID)
+		require.Equal(t, r.BlockNumber, resp.BlockNumber)
+		require.Equal(t, r.ValidationCode, resp.ValidationCode)
+	})
+
+	t.Run("Block number resolved from ledger", func(t *testing.T) {
+		r2 := *r
+		r2.BlockNumber = 0
+
+		bcClient.GetBlockByTxIDReturns(&common.Block{Header: &common.BlockHeader{Number: 1002}}, nil)
+
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{receipt: &r2}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		resp := &ReceiptResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, uint64(1002), resp.BlockNumber)
+	})
+
+	t.Run("Block number ledger error", func(t *testing.T) {
+		r2 := *r
+		r2.BlockNumber = 0
+
+		bcClient.GetBlockByTxIDReturns(nil, errors.New("injected ledger error"))
+
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{receipt: &r2}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{err: receipt.ErrNotFound}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{err: errors.New("injected store error")}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{receipt: r}, bcProvider)
+		h.jsonMarshal = func(v interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/"+anchor, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+	})
+
+	t.Run("No anchor string", func(t *testing.T) {
+		getAnchor = func(req *http.Request) string { return "" }
+		defer func() { getAnchor = func(req *http.Request) string { return anchor } }()
+
+		h := NewReceiptHandler(channel1, handlerCfg, &mockReceiptStore{receipt: r}, bcProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/blockchain/receipts/", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+
+		resp := &ErrorResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, InvalidAnchorString, resp.Code)
+	})
+}
+
+type mockReceiptStore struct {
+	receipt *receipt.Receipt
+	err     error
+}
+
+func (m *mockReceiptStore) Get(string) (*receipt.Receipt, error) {
+	return m.receipt, m.err
+}
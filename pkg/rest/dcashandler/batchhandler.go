@@ -0,0 +1,247 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+const (
+	batchPath = "/batch"
+
+	// maxBatchSize is the maximum number of hashes that may be retrieved in a single batch
+	maxBatchSize = 100
+
+	contentTypeMultipartMixed = "multipart/mixed"
+
+	// HashHeader is the name of the header that contains the hash of the content in each part of a multipart batch response
+	HashHeader = "X-Content-Hash"
+	// StatusHeader is the name of the header that contains the status of each part of a multipart batch response
+	StatusHeader = "X-Result-Status"
+)
+
+// maxBatchContentSize is the maximum total size (in bytes) of the content that's returned in a single batch
+var maxBatchContentSize = 10 * 1024 * 1024
+
+// Batch retrieves the content for multiple hashes from the DCAS store in a single request
+type Batch struct {
+	*Retrieve
+	method string
+}
+
+// NewBatchHandler returns a new batch handler in which the hashes are provided in the query
+// (e.g. /cas/batch?hash=xxx&hash=yyy&max-size=1024)
+func NewBatchHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *Batch {
+	return &Batch{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+		method:   http.MethodGet,
+	}
+}
+
+// NewBatchPostHandler returns a new batch handler in which the hashes are provided in a JSON BatchRequest body
+func NewBatchPostHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *Batch {
+	return &Batch{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+		method:   http.MethodPost,
+	}
+}
+
+// Path returns the context path
+func (h *Batch) Path() string {
+	return h.BasePath + batchPath
+}
+
+// Method returns the HTTP method
+func (h *Batch) Method() string {
+	return h.method
+}
+
+// Handler returns the request handler
+func (h *Batch) Handler() common.HTTPRequestHandler {
+	return h.batch
+}
+
+// batch retrieves the content for each of the requested hashes. The max-size query parameter applies to
+// each item. A failure to retrieve one item doesn't fail the batch; instead the result for that item contains
+// the status and result code that would have been returned had the item been retrieved individually.
+//
+// The total size of the content in the batch is limited to maxBatchContentSize so that the response isn't
+// arbitrarily large. The result for an item whose content would exceed the limit contains a 413 status and
+// the batch_content_exceeds_maximum_allowed_size result code, in which case the item may be retrieved individually.
+//
+// The response is a JSON BatchResponse unless the client accepts multipart/mixed, in which case each result is
+// returned in its own part (in the same order as the request) with the X-Content-Hash and X-Result-Status headers.
+func (h *Batch) batch(rw http.ResponseWriter, req *http.Request) {
+	rrw := newRetrieveWriter(rw)
+
+	hashes, err := h.getHashes(req)
+	if err != nil {
+		rrw.WriteError(err)
+		return
+	}
+
+	maxSize := getMaxSize(req)
+	if maxSize == 0 {
+		rrw.WriteError(newRetrieveError(http.StatusBadRequest, CodeMaxSizeNotSpecified))
+		return
+	}
+
+	logger.Debugf("[%s:%s:%s] Retrieving batch of %d hashes with max-size %d", h.channelID, h.ChaincodeName, h.Collection, len(hashes), maxSize)
+
+	remaining := maxBatchContentSize
+
+	results := make([]*BatchResult, len(hashes))
+	for i, hash := range hashes {
+		results[i] = h.retrieveResult(hash, maxSize, remaining)
+		remaining -= len(results[i].Content)
+	}
+
+	if strings.Contains(req.Header.Get("Accept"), contentTypeMultipartMixed) {
+		writeMultipart(rw, results)
+		return
+	}
+
+	respBytes, err := json.Marshal(&BatchResponse{Results: results})
+	if err != nil {
+		logger.Errorf("Unable to marshal batch response: %s", err)
+
+		rrw.WriteError(httpserver.ServerError)
+		return
+	}
+
+	rrw.Write(http.StatusOK, respBytes, httpserver.ContentTypeJSON)
+}
+
+func (h *Batch) getHashes(req *http.Request) ([]string, error) {
+	var hashes []string
+
+	if h.method == http.MethodGet {
+		hashes = getParams(req)[hashParam]
+	} else {
+		request := &BatchRequest{}
+		if err := json.NewDecoder(req.Body).Decode(request); err != nil {
+			logger.Debugf("[%s:%s:%s] Invalid batch request: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+			return nil, httpserver.BadRequestError
+		}
+
+		hashes = request.Hashes
+	}
+
+	if len(hashes) == 0 {
+		return nil, newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+	}
+
+	if len(hashes) > maxBatchSize {
+		logger.Debugf("[%s:%s:%s] Batch of %d hashes exceeds the maximum of %d", h.channelID, h.ChaincodeName, h.Collection, len(hashes), maxBatchSize)
+
+		return nil, newRetrieveError(http.StatusBadRequest, CodeBatchSizeExceeded)
+	}
+
+	return hashes, nil
+}
+
+// retrieveResult retrieves the content for the given hash. The content is limited to max-size and to the
+// given number of bytes remaining in the batch.
+func (h *Batch) retrieveResult(hash string, maxSize, remaining int) *BatchResult {
+	if err := validateRequest(hash, maxSize); err != nil {
+		return newErrorResult(hash, err)
+	}
+
+	if remaining <= 0 {
+		return newErrorResult(hash, newRetrieveError(http.StatusRequestEntityTooLarge, CodeBatchContentSizeExceeded))
+	}
+
+	limit := maxSize
+	if remaining < limit {
+		limit = remaining
+	}
+
+	content, err := h.doRetrieve(hash, limit)
+	if err != nil {
+		if limit < maxSize && isMaxSizeExceeded(err) {
+			logger.Debugf("[%s:%s:%s] Content for hash [%s] exceeds the remaining %d bytes of the batch", h.channelID, h.ChaincodeName, h.Collection, hash, remaining)
+
+			return newErrorResult(hash, newRetrieveError(http.StatusRequestEntityTooLarge, CodeBatchContentSizeExceeded))
+		}
+
+		return newErrorResult(hash, err)
+	}
+
+	return &BatchResult{
+		Hash:    hash,
+		Status:  http.StatusOK,
+		Content: content,
+	}
+}
+
+func isMaxSizeExceeded(err error) bool {
+	readErr, ok := err.(*retrieveError)
+
+	return ok && readErr.ResultCode() == CodeMaxSizeExceeded
+}
+
+func newErrorResult(hash string, err error) *BatchResult {
+	if readErr, ok := err.(*retrieveError); ok {
+		return &BatchResult{
+			Hash:   hash,
+			Status: readErr.Status(),
+			Code:   readErr.ResultCode(),
+		}
+	}
+
+	return &BatchResult{
+		Hash:   hash,
+		Status: http.StatusInternalServerError,
+		Code:   CodeCasNotReachable,
+	}
+}
+
+func writeMultipart(rw http.ResponseWriter, results []*BatchResult) {
+	mw := multipart.NewWriter(rw)
+
+	rw.Header().Set(httpserver.ContentTypeHeader, contentTypeMultipartMixed+"; boundary="+mw.Boundary())
+	rw.WriteHeader(http.StatusOK)
+
+	for _, result := range results {
+		header := make(textproto.MIMEHeader)
+		header.Set(HashHeader, result.Hash)
+		header.Set(StatusHeader, strconv.Itoa(result.Status))
+
+		content := result.Content
+		if result.Code != "" {
+			header.Set(httpserver.ContentTypeHeader, httpserver.ContentTypeText)
+			content = []byte(result.Code)
+		} else {
+			header.Set(httpserver.ContentTypeHeader, httpserver.ContentTypeBinary)
+		}
+
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			logger.Warnf("Error creating part for hash [%s]: %s", result.Hash, err)
+			return
+		}
+
+		if _, err := part.Write(content); err != nil {
+			logger.Warnf("Error writing part for hash [%s]: %s", result.Hash, err)
+			return
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		logger.Warnf("Error closing multipart response: %s", err)
+	}
+}
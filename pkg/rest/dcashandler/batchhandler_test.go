@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+const hash2 = "7890"
+
+func TestNewBatchHandler(t *testing.T) {
+	h := NewBatchHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+	require.Equal(t, "/cas/batch", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+
+	h = NewBatchPostHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+	require.Equal(t, "/cas/batch", h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+}
+
+func TestBatch_Handler(t *testing.T) {
+	content := []byte("some content")
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+	t.Run("GET -> JSON", func(t *testing.T) {
+		h := NewBatchHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/batch?hash="+hash+"&hash="+hash2+"&max-size=1024", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
+
+		resp := &BatchResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 2)
+
+		require.Equal(t, hash, resp.Results[0].Hash)
+		require.Equal(t, http.StatusOK, resp.Results[0].Status)
+		require.Empty(t, resp.Results[0].Code)
+		require.Equal(t, content, resp.Results[0].Content)
+
+		require.Equal(t, hash2, resp.Results[1].Hash)
+		require.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+		require.Equal(t, CodeNotFound, resp.Results[1].Code)
+		require.Empty(t, resp.Results[1].Content)
+	})
+
+	t.Run("POST -> JSON", func(t *testing.T) {
+		h := NewBatchPostHandler(channel1, handlerCfg, dcasProvider)
+
+		reqBytes, err := json.Marshal(&BatchRequest{Hashes: []string{hash, ""}})
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/batch?max-size=5", strings.NewReader(string(reqBytes))))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resp := &BatchResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 2)
+		require.Equal(t, http.StatusBadRequest, resp.Results[0].Status)
+		require.Equal(t, CodeMaxSizeExceeded, resp.Results[0].Code)
+		require.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+		require.Equal(t, CodeInvalidHash, resp.Results[1].Code)
+	})
+
+	t.Run("GET -> multipart", func(t *testing.T) {
+		h := NewBatchHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/batch?hash="+hash+"&hash="+hash2+"&max-size=1024", nil)
+		req.Header.Set("Accept", contentTypeMultipartMixed)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		mediaType, params, err := mime.ParseMediaType(rw.Header().Get(httpserver.ContentTypeHeader))
+		require.NoError(t, err)
+		require.Equal(t, contentTypeMultipartMixed, mediaType)
+
+		mr := multipart.NewReader(rw.Body, params["boundary"])
+
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, hash, part.Header.Get(HashHeader))
+		require.Equal(t, "200", part.Header.Get(StatusHeader))
+		require.Equal(t, httpserver.ContentTypeBinary, part.Header.Get(httpserver.ContentTypeHeader))
+
+		partContent, err := ioutil.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, content, partContent)
+
+		part, err = mr.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, hash2, part.Header.Get(HashHeader))
+		require.Equal(t, "404", part.Header.Get(StatusHeader))
+
+		partContent, err = ioutil.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, CodeNotFound, string(partContent))
+	})
+
+	t.Run("No hashes -> Bad Request", func(t *testing.T) {
+		h := NewBatchHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/batch?max-size=1024", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, CodeInvalidHash, rw.Body.String())
+	})
+
+	t.Run("Too many hashes -> Bad Request", func(t *testing.T) {
+		h := NewBatchPostHandler(channel1, handlerCfg, dcasProvider)
+
+		hashes := make([]string, maxBatchSize+1)
+		for i := range hashes {
+			hashes[i] = hash
+		}
+
+		reqBytes, err := json.Marshal(&BatchRequest{Hashes: hashes})
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/batch?max-size=1024", strings.NewReader(string(reqBytes))))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, CodeBatchSizeExceeded, rw.Body.String())
+	})
+
+	t.Run("Invalid request -> Bad Request", func(t *testing.T) {
+		h := NewBatchPostHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/batch?max-size=1024", strings.NewReader("{")))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("Total content size exceeded", func(t *testing.T) {
+		restore := maxBatchContentSize
+		maxBatchContentSize = 2*len(content) + 1
+		defer func() { maxBatchContentSize = restore }()
+
+		h := NewBatchHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/batch?hash="+hash+"&hash="+hash+"&hash="+hash+"&hash="+hash2+"&max-size=1024", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resp := &BatchResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 4)
+
+		require.Equal(t, http.StatusOK, resp.Results[0].Status)
+		require.Equal(t, content, resp.Results[0].Content)
+		require.Equal(t, http.StatusOK, resp.Results[1].Status)
+		require.Equal(t, content, resp.Results[1].Content)
+
+		// Only one byte remains in the batch
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.Results[2].Status)
+		require.Equal(t, CodeBatchContentSizeExceeded, resp.Results[2].Code)
+		require.Empty(t, resp.Results[2].Content)
+
+		require.Equal(t, http.StatusNotFound, resp.Results[3].Status)
+		require.Equal(t, CodeNotFound, resp.Results[3].Code)
+	})
+
+	t.Run("No max-size -> Bad Request", func(t *testing.T) {
+		h := NewBatchHandler(channel1, handlerCfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/batch?hash="+hash, nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, CodeMaxSizeNotSpecified, rw.Body.String())
+	})
+}
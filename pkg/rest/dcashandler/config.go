@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package dcashandler
 
 import (
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
 	"github.com/trustbloc/sidetree-fabric/pkg/rest/authhandler"
 )
 
@@ -22,4 +23,19 @@ type Config struct {
 	ChaincodeName string
 	// Collection is the name of the DCAS collection that stores the content
 	Collection string
+	// MaxUploadSize is the maximum size (in bytes) of uploaded content. If 0 then the size is not limited.
+	MaxUploadSize int64
+	// IPFSEnabled indicates whether or not the content is also served (read-only) using IPFS gateway (/ipfs/{cid})
+	// and IPFS HTTP API (/api/v0/cat?arg={cid}) semantics so that IPFS clients may retrieve the content
+	IPFSEnabled bool
+	// IPFSMaxSize is the maximum size (in bytes) of the content returned by the IPFS handlers. IPFS clients don't
+	// specify max-size, so this limit applies instead (a smaller max-size may still be requested). If 0 then a
+	// default of 10MB is used.
+	IPFSMaxSize int64
+	// Quotas contains the upload quotas keyed by the name of the write token. Uploads that are authorized with
+	// a token that has no quota are not limited.
+	Quotas map[string]dcasquota.Quota
+	// UsageCollection is the name of the off-ledger collection (of the chaincode that stores the content) in which
+	// the usage of each token with a quota is stored. It's required if quotas are specified.
+	UsageCollection string
 }
@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipfs/go-unixfs"
+	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+)
+
+// hasContentSize returns true if the size of the content that's stored under the given key may be determined
+// from its DCAS node (see contentSize)
+func hasContentSize(key string) bool {
+	cID, err := cid.Decode(key)
+	if err != nil {
+		return false
+	}
+
+	switch cID.Type() {
+	case cid.Raw, cid.DagProtobuf:
+		return true
+	default:
+		return false
+	}
+}
+
+// contentSize returns the size of the content of the given DCAS node, or false if the size can't be determined
+// from the node alone. The content of a raw node is its data, and the size of the content of a DAG-PB node
+// (i.e. a file that's stored as a UnixFS DAG) is recorded in the data of its root node.
+func contentSize(key string, node *dcas.Node) (int, bool) {
+	cID, err := cid.Decode(key)
+	if err != nil {
+		return 0, false
+	}
+
+	switch cID.Type() {
+	case cid.Raw:
+		return len(node.Data), true
+	case cid.DagProtobuf:
+		pn, err := merkledag.DecodeProtobuf(node.Data)
+		if err != nil {
+			logger.Debugf("Unable to decode DAG-PB node [%s]: %s", key, err)
+
+			return 0, false
+		}
+
+		fsNode, err := unixfs.FSNodeFromBytes(pn.Data())
+		if err != nil {
+			logger.Debugf("Unable to decode UnixFS data of node [%s]: %s", key, err)
+
+			return 0, false
+		}
+
+		return int(fsNode.FileSize()), true
+	default:
+		return 0, false
+	}
+}
+
+// isRawNode returns true if the content that's stored under the given key is a single raw block, in which case
+// the content is the data of the node
+func isRawNode(key string) bool {
+	cID, err := cid.Decode(key)
+	if err != nil {
+		return false
+	}
+
+	return cID.Type() == cid.Raw
+}
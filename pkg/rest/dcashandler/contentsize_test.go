@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipfs/go-unixfs"
+	"github.com/stretchr/testify/require"
+	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestContentSize(t *testing.T) {
+	t.Run("Raw", func(t *testing.T) {
+		cID, err := mocks.NewDCASClient().Put(bytes.NewReader([]byte("some content")))
+		require.NoError(t, err)
+
+		require.True(t, hasContentSize(cID))
+		require.True(t, isRawNode(cID))
+
+		size, ok := contentSize(cID, &dcas.Node{Data: []byte("some content")})
+		require.True(t, ok)
+		require.Equal(t, 12, size)
+	})
+
+	t.Run("DAG-PB", func(t *testing.T) {
+		key, node := newFileNode(t, 1000)
+
+		require.True(t, hasContentSize(key))
+		require.False(t, isRawNode(key))
+
+		size, ok := contentSize(key, node)
+		require.True(t, ok)
+		require.Equal(t, 1000, size)
+
+		_, ok = contentSize(key, &dcas.Node{Data: []byte("invalid")})
+		require.False(t, ok)
+	})
+
+	t.Run("Invalid CID", func(t *testing.T) {
+		require.False(t, hasContentSize(hash))
+		require.False(t, isRawNode(hash))
+
+		_, ok := contentSize(hash, &dcas.Node{Data: []byte("some content")})
+		require.False(t, ok)
+	})
+}
+
+// newFileNode returns the key and the root node of a UnixFS file of the given size
+func newFileNode(t *testing.T, size int) (string, *dcas.Node) {
+	pn := merkledag.NodeWithData(unixfs.FilePBData(nil, uint64(size)))
+
+	rawData, err := pn.EncodeProtobuf(false)
+	require.NoError(t, err)
+
+	return pn.Cid().String(), &dcas.Node{Data: rawData}
+}
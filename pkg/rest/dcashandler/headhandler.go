@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+// Head checks for the existence of content in the DCAS store and returns its size without the content
+type Head struct {
+	*Retrieve
+}
+
+// NewHeadHandler returns a new Head handler
+func NewHeadHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *Head {
+	return &Head{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+	}
+}
+
+// Method returns the HTTP method
+func (h *Head) Method() string {
+	return http.MethodHead
+}
+
+// Handler returns the request handler
+func (h *Head) Handler() common.HTTPRequestHandler {
+	return h.head
+}
+
+// head responds with 200 and the size of the content (in the Content-Length and X-Content-Size headers) if content
+// exists for the hash, otherwise 404. The size of raw and UnixFS content is determined from the DCAS node (so only
+// the root node of a file is read). The size of any other content can only be determined by reading the content,
+// which is then discarded.
+func (h *Head) head(rw http.ResponseWriter, req *http.Request) {
+	hash := getHash(req)
+
+	logger.Debugf("[%s:%s:%s] Checking existence of content for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, hash)
+
+	rrw := newRetrieveWriter(rw)
+
+	if hash == "" {
+		rrw.WriteError(newRetrieveError(http.StatusBadRequest, CodeInvalidHash))
+		return
+	}
+
+	if httpserver.IfNoneMatchETag(req, httpserver.ETag(hash)) {
+		rrw.WriteNotModified(hash)
+		return
+	}
+
+	size, err := h.getSize(hash)
+	if err != nil {
+		rrw.WriteError(err)
+		return
+	}
+
+	// If-None-Match: * only matches if the content exists
+	if httpserver.IfNoneMatch(req, httpserver.ETag(hash)) {
+		rrw.WriteNotModified(hash)
+		return
+	}
+
+	rw.Header().Set(httpserver.ContentTypeHeader, httpserver.ContentTypeBinary)
+	rw.Header().Set(httpserver.ETagHeader, httpserver.ETag(hash))
+	rw.Header().Set(httpserver.CacheControlHeader, httpserver.CacheControlImmutable)
+	rw.Header().Set("Content-Length", strconv.Itoa(size))
+	rw.Header().Set(httpserver.ContentSizeHeader, strconv.Itoa(size))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// getSize returns the size of the content for the given hash
+func (h *Head) getSize(hash string) (int, error) {
+	if hasContentSize(hash) {
+		node, err := h.getNode(hash)
+		if err != nil {
+			return 0, err
+		}
+
+		if size, ok := contentSize(hash, node); ok {
+			if size == 0 {
+				// Consistent with retrieval, which returns 404 for empty content
+				return 0, newRetrieveError(http.StatusNotFound, CodeNotFound)
+			}
+
+			return size, nil
+		}
+	}
+
+	w := newLimitWriter(ioutil.Discard, -1)
+
+	if err := h.retrieveContent(hash, w); err != nil {
+		return 0, err
+	}
+
+	return w.Size(), nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestNewHeadHandler(t *testing.T) {
+	h := NewHeadHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+	require.Equal(t, "/cas/{hash}", h.Path())
+	require.Equal(t, http.MethodHead, h.Method())
+}
+
+func TestHead_Handler(t *testing.T) {
+	content := []byte("some content")
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+	h := NewHeadHandler(channel1, handlerCfg, dcasProvider)
+
+	t.Run("Exists", func(t *testing.T) {
+		restoreParams := setParams(hash, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, strconv.Itoa(len(content)), rw.Header().Get("Content-Length"))
+		require.Equal(t, strconv.Itoa(len(content)), rw.Header().Get(httpserver.ContentSizeHeader))
+		require.Equal(t, httpserver.ETag(hash), rw.Header().Get(httpserver.ETagHeader))
+		require.Empty(t, rw.Body.Bytes())
+	})
+
+	t.Run("Size from node", func(t *testing.T) {
+		fileContent := make([]byte, 2*streamBufferSize)
+
+		key, node := newFileNode(t, len(fileContent))
+
+		dcasClient := &mocks.DCASClient{}
+		dcasClient.GetNodeReturns(node, nil)
+
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+		defer dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+		restoreParams := setParams(key, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, strconv.Itoa(len(fileContent)), rw.Header().Get("Content-Length"))
+		require.Equal(t, strconv.Itoa(len(fileContent)), rw.Header().Get(httpserver.ContentSizeHeader))
+		require.Zero(t, dcasClient.GetCallCount())
+	})
+
+	t.Run("Raw content", func(t *testing.T) {
+		dcasClient := mocks.NewDCASClient().WithGetError(errors.New("content should not be retrieved"))
+
+		cID, err := dcasClient.Put(bytes.NewReader(content))
+		require.NoError(t, err)
+
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+		defer dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+		restoreParams := setParams(cID, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, strconv.Itoa(len(content)), rw.Header().Get(httpserver.ContentSizeHeader))
+	})
+
+	t.Run("Raw content not found", func(t *testing.T) {
+		cID, err := mocks.NewDCASClient().Put(bytes.NewReader(content))
+		require.NoError(t, err)
+
+		restoreParams := setParams(cID, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		restoreParams := setParams(hash2, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		restoreParams := setParams(hash, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodHead, "/cas", nil)
+		req.Header.Set("If-None-Match", httpserver.ETag(hash))
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Code)
+	})
+
+	t.Run("If-None-Match * -> Not modified", func(t *testing.T) {
+		restoreParams := setParams(hash, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodHead, "/cas", nil)
+		req.Header.Set("If-None-Match", "*")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Code)
+	})
+
+	t.Run("If-None-Match * with no content -> Not found", func(t *testing.T) {
+		restoreParams := setParams(hash2, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodHead, "/cas", nil)
+		req.Header.Set("If-None-Match", "*")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("No hash -> Bad Request", func(t *testing.T) {
+		restoreParams := setParams("", "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("DCAS error -> Server Error", func(t *testing.T) {
+		restoreParams := setParams(hash, "")
+		defer restoreParams()
+
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithGetError(errors.New("injected DCAS error")), nil)
+		defer dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodHead, "/cas", nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasintegrity"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+const integrityPath = "/integrity"
+
+type integrityScanner interface {
+	Scan() (*dcasintegrity.Report, error)
+}
+
+// Integrity is an administrative handler that scans the content in the DCAS store and verifies that each item
+// of content matches its hash. The response is a JSON report containing the hashes of the content that doesn't
+// match and the entries that couldn't be verified. Only one scan of the DCAS store runs at a time, so a 409 (Conflict)
+// is returned if a scan (including the background scan) is already running.
+type Integrity struct {
+	Config
+	channelID string
+	scanner   integrityScanner
+}
+
+// NewIntegrityHandler returns a new integrity scan handler
+func NewIntegrityHandler(channelID string, cfg Config, scanner integrityScanner) *Integrity {
+	return &Integrity{
+		Config:    cfg,
+		channelID: channelID,
+		scanner:   scanner,
+	}
+}
+
+// Path returns the context path
+func (h *Integrity) Path() string {
+	return h.BasePath + integrityPath
+}
+
+// Method returns the HTTP method
+func (h *Integrity) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the request handler
+func (h *Integrity) Handler() common.HTTPRequestHandler {
+	return h.scan
+}
+
+func (h *Integrity) scan(rw http.ResponseWriter, _ *http.Request) {
+	logger.Debugf("[%s:%s:%s] Scanning DCAS content", h.channelID, h.ChaincodeName, h.Collection)
+
+	hrw := httpserver.NewResponseWriter(rw)
+
+	report, err := h.scanner.Scan()
+	if err != nil {
+		if err == dcasintegrity.ErrScanInProgress {
+			logger.Debugf("[%s:%s:%s] DCAS integrity scan is already running", h.channelID, h.ChaincodeName, h.Collection)
+
+			hrw.Write(http.StatusConflict, []byte(CodeScanInProgress), httpserver.ContentTypeText)
+			return
+		}
+
+		logger.Errorf("[%s:%s:%s] DCAS integrity scan failed: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+		hrw.Write(http.StatusInternalServerError, []byte(CodeCasNotReachable), httpserver.ContentTypeText)
+		return
+	}
+
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		hrw.WriteError(err)
+		return
+	}
+
+	hrw.Write(http.StatusOK, reportBytes, httpserver.ContentTypeJSON)
+}
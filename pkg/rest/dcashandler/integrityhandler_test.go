@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasintegrity"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+func TestNewIntegrityHandler(t *testing.T) {
+	h := NewIntegrityHandler(channel1, handlerCfg, &mockScanner{})
+	require.NotNil(t, h)
+
+	require.Equal(t, "/cas/integrity", h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+}
+
+func TestIntegrity_Handler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		report := &dcasintegrity.Report{
+			Scanned:    3,
+			Verified:   1,
+			Mismatched: []string{"cid1"},
+			Unreadable: []*dcasintegrity.Entry{{Key: "/blocks/key", Error: "no content"}},
+		}
+
+		h := NewIntegrityHandler(channel1, handlerCfg, &mockScanner{report: report})
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/integrity", nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
+
+		resp := &dcasintegrity.Report{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, report, resp)
+	})
+
+	t.Run("Scan error -> Server Error", func(t *testing.T) {
+		h := NewIntegrityHandler(channel1, handlerCfg, &mockScanner{err: errors.New("injected scan error")})
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/integrity", nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, httpserver.ContentTypeText, rw.Header().Get(httpserver.ContentTypeHeader))
+		require.Equal(t, CodeCasNotReachable, rw.Body.String())
+	})
+
+	t.Run("Scan in progress -> Conflict", func(t *testing.T) {
+		h := NewIntegrityHandler(channel1, handlerCfg, &mockScanner{err: dcasintegrity.ErrScanInProgress})
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/integrity", nil))
+
+		require.Equal(t, http.StatusConflict, rw.Result().StatusCode)
+		require.Equal(t, CodeScanInProgress, rw.Body.String())
+	})
+}
+
+type mockScanner struct {
+	report *dcasintegrity.Report
+	err    error
+}
+
+func (m *mockScanner) Scan() (*dcasintegrity.Report, error) {
+	return m.report, m.err
+}
@@ -0,0 +1,293 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+const (
+	ipfsGatewayPath = "/ipfs"
+	ipfsCatPath     = "/api/v0/cat"
+	ipfsPathPrefix  = ipfsGatewayPath + "/"
+	ipfsArgParam    = "arg"
+
+	// defaultIPFSMaxSize is the maximum size of the content returned by the IPFS handlers if IPFSMaxSize isn't configured
+	defaultIPFSMaxSize = 10 * 1024 * 1024
+
+	// IPFSPathHeader is the name of the header that contains the IPFS path of the content returned by the IPFS gateway handler
+	IPFSPathHeader = "X-Ipfs-Path"
+	// streamOutputHeader indicates to IPFS API clients that the content is streamed
+	streamOutputHeader = "X-Stream-Output"
+)
+
+// Error codes returned in IPFSError (as defined by the IPFS HTTP API)
+const (
+	ipfsErrNormal   = 0
+	ipfsErrClient   = 1
+	ipfsErrNotFound = 3
+)
+
+// IPFSGateway retrieves content from the DCAS store using IPFS gateway semantics (GET /ipfs/{cid}). The content
+// may be requested using either a CIDv0 or a CIDv1. The size of the content is limited to the configured IPFSMaxSize
+// (see ipfsMaxSize).
+type IPFSGateway struct {
+	*Retrieve
+}
+
+// NewIPFSGatewayHandler returns a new IPFS gateway handler
+func NewIPFSGatewayHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *IPFSGateway {
+	return &IPFSGateway{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+	}
+}
+
+// Path returns the context path
+func (h *IPFSGateway) Path() string {
+	return fmt.Sprintf("%s%s/{%s}", h.BasePath, ipfsGatewayPath, hashParam)
+}
+
+// Method returns the HTTP method
+func (h *IPFSGateway) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the request handler
+func (h *IPFSGateway) Handler() common.HTTPRequestHandler {
+	return h.get
+}
+
+func (h *IPFSGateway) get(rw http.ResponseWriter, req *http.Request) {
+	cID := getHash(req)
+	maxSize := h.ipfsMaxSize(req)
+
+	logger.Debugf("[%s:%s:%s] Retrieving IPFS content for CID [%s] with max-size %d", h.channelID, h.ChaincodeName, h.Collection, cID, maxSize)
+
+	rrw := newRetrieveWriter(rw)
+
+	if cID != "" && httpserver.IfNoneMatchETag(req, httpserver.ETag(cID)) {
+		rrw.WriteNotModified(cID)
+		return
+	}
+
+	key, node, err := h.resolveCID(cID)
+	if err != nil {
+		rrw.WriteError(err)
+		return
+	}
+
+	// If-None-Match: * only matches if the content exists
+	if httpserver.IfNoneMatch(req, httpserver.ETag(cID)) {
+		rrw.WriteNotModified(cID)
+		return
+	}
+
+	rw.Header().Set(IPFSPathHeader, ipfsPathPrefix+cID)
+
+	if req.Header.Get(rangeHeader) != "" {
+		content, err := h.doRetrieve(key, maxSize)
+		if err != nil {
+			rrw.WriteError(err)
+			return
+		}
+
+		rrw.WriteContent(req, content, cID)
+		return
+	}
+
+	if err := h.streamNode(rw, cID, key, node, maxSize); err != nil {
+		rrw.WriteError(err)
+	}
+}
+
+// IPFSCat retrieves content from the DCAS store using IPFS HTTP API semantics (/api/v0/cat?arg={cid}). The argument
+// may be either a CIDv0 or a CIDv1, optionally prefixed with /ipfs/. The size of the content is limited to the
+// configured IPFSMaxSize (see ipfsMaxSize). Errors are returned as a JSON IPFSError.
+type IPFSCat struct {
+	*Retrieve
+	method string
+}
+
+// NewIPFSCatHandler returns a new IPFS cat handler. (The IPFS HTTP API requires POST.)
+func NewIPFSCatHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *IPFSCat {
+	return &IPFSCat{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+		method:   http.MethodPost,
+	}
+}
+
+// NewIPFSCatGetHandler returns a new IPFS cat handler that accepts GET (for older IPFS clients)
+func NewIPFSCatGetHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *IPFSCat {
+	return &IPFSCat{
+		Retrieve: NewRetrieveHandler(channelID, cfg, dcasProvider),
+		method:   http.MethodGet,
+	}
+}
+
+// Path returns the context path
+func (h *IPFSCat) Path() string {
+	return h.BasePath + ipfsCatPath
+}
+
+// Method returns the HTTP method
+func (h *IPFSCat) Method() string {
+	return h.method
+}
+
+// Handler returns the request handler
+func (h *IPFSCat) Handler() common.HTTPRequestHandler {
+	return h.cat
+}
+
+func (h *IPFSCat) cat(rw http.ResponseWriter, req *http.Request) {
+	cID := strings.TrimPrefix(getIPFSArg(req), ipfsPathPrefix)
+	maxSize := h.ipfsMaxSize(req)
+
+	logger.Debugf("[%s:%s:%s] Retrieving IPFS content for CID [%s] with max-size %d", h.channelID, h.ChaincodeName, h.Collection, cID, maxSize)
+
+	key, node, err := h.resolveCID(cID)
+	if err != nil {
+		writeIPFSError(rw, err)
+		return
+	}
+
+	rw.Header().Set(streamOutputHeader, "1")
+
+	if err := h.streamNode(rw, cID, key, node, maxSize); err != nil {
+		writeIPFSError(rw, err)
+	}
+}
+
+// ipfsMaxSize returns the maximum size of the content returned by an IPFS handler. IPFS clients don't specify
+// max-size, so the configured IPFSMaxSize (or the default) applies unless a smaller max-size is requested.
+func (h *Retrieve) ipfsMaxSize(req *http.Request) int {
+	maxSize := int(h.IPFSMaxSize)
+	if maxSize == 0 {
+		maxSize = defaultIPFSMaxSize
+	}
+
+	if requested := getMaxSize(req); requested > 0 && requested < maxSize {
+		return requested
+	}
+
+	return maxSize
+}
+
+// resolveCID returns the key under which the content for the given CID is stored in DCAS along with the DCAS
+// node. IPFS clients commonly use CIDv0 whereas DCAS stores content under a CIDv1, so if the content isn't stored
+// under the given CID then the equivalent CIDs (i.e. the CIDs with the same multihash) are tried.
+func (h *Retrieve) resolveCID(cIDStr string) (string, *dcas.Node, error) {
+	if cIDStr == "" {
+		return "", nil, newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+	}
+
+	cID, err := cid.Decode(cIDStr)
+	if err != nil {
+		logger.Debugf("[%s:%s:%s] Invalid CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cIDStr, err)
+
+		return "", nil, newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+	}
+
+	dcasClient, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+		return "", nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+	}
+
+	for _, c := range equivalentCIDs(cID) {
+		node, err := dcasClient.GetNode(c.String())
+		if err != nil {
+			logger.Errorf("[%s:%s:%s] Error retrieving DCAS node for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, c, err)
+
+			return "", nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+		}
+
+		if node != nil {
+			logger.Debugf("[%s:%s:%s] Content for CID [%s] is stored under [%s]", h.channelID, h.ChaincodeName, h.Collection, cIDStr, c)
+
+			return c.String(), node, nil
+		}
+	}
+
+	logger.Debugf("[%s:%s:%s] Content not found in DCAS for CID [%s]", h.channelID, h.ChaincodeName, h.Collection, cIDStr)
+
+	return "", nil, newRetrieveError(http.StatusNotFound, CodeNotFound)
+}
+
+// equivalentCIDs returns the given CID followed by the other CIDs with the same multihash: the CIDv1 of a
+// DAG-PB node (which is equivalent to a CIDv0), the CIDv1 of a raw node and, if the hash is SHA2-256, the CIDv0.
+func equivalentCIDs(cID cid.Cid) []cid.Cid {
+	cIDs := []cid.Cid{cID}
+
+	add := func(c cid.Cid) {
+		for _, existing := range cIDs {
+			if existing.Equals(c) {
+				return
+			}
+		}
+
+		cIDs = append(cIDs, c)
+	}
+
+	add(cid.NewCidV1(cid.DagProtobuf, cID.Hash()))
+	add(cid.NewCidV1(cid.Raw, cID.Hash()))
+
+	if prefix := cID.Prefix(); prefix.MhType == mh.SHA2_256 && prefix.MhLength == 32 {
+		add(cid.NewCidV0(cID.Hash()))
+	}
+
+	return cIDs
+}
+
+// writeIPFSError writes the given error as an IPFSError
+func writeIPFSError(rw http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	ipfsErr := &IPFSError{
+		Message: err.Error(),
+		Code:    ipfsErrNormal,
+		Type:    "error",
+	}
+
+	if readErr, ok := err.(*retrieveError); ok {
+		status = readErr.Status()
+
+		switch status {
+		case http.StatusBadRequest:
+			ipfsErr.Code = ipfsErrClient
+		case http.StatusNotFound:
+			ipfsErr.Code = ipfsErrNotFound
+		}
+	}
+
+	errBytes, err := json.Marshal(ipfsErr)
+	if err != nil {
+		httpserver.NewResponseWriter(rw).WriteError(err)
+		return
+	}
+
+	httpserver.NewResponseWriter(rw).Write(status, errBytes, httpserver.ContentTypeJSON)
+}
+
+var getIPFSArg = func(req *http.Request) string {
+	values := getParams(req)[ipfsArgParam]
+	if len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,407 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestNewIPFSGatewayHandler(t *testing.T) {
+	h := NewIPFSGatewayHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+
+	require.Equal(t, "/cas/ipfs/{hash}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+}
+
+func TestIPFSGateway_Handler(t *testing.T) {
+	content := []byte("some content")
+
+	dcasClient, cidV1, cidV0 := newIPFSDCASClient(t, content)
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	h := NewIPFSGatewayHandler(channel1, handlerCfg, dcasProvider)
+	require.NotNil(t, h)
+
+	t.Run("CIDv1 -> OK", func(t *testing.T) {
+		restoreParams := setParams(cidV1, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV1, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+		require.Equal(t, "/ipfs/"+cidV1, rw.Header().Get(IPFSPathHeader))
+		require.Equal(t, httpserver.ETag(cidV1), rw.Header().Get(httpserver.ETagHeader))
+	})
+
+	t.Run("CIDv0 -> OK", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+		require.Equal(t, "/ipfs/"+cidV0, rw.Header().Get(IPFSPathHeader))
+		require.Equal(t, httpserver.ETag(cidV0), rw.Header().Get(httpserver.ETagHeader))
+	})
+
+	t.Run("If-None-Match -> Not Modified", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, httpserver.ETag(cidV0))
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Empty(t, rw.Body.Bytes())
+	})
+
+	t.Run("If-None-Match * -> Not Modified", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, "*")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Empty(t, rw.Body.Bytes())
+	})
+
+	t.Run("If-None-Match * with no content -> Not Found", func(t *testing.T) {
+		missingCID, err := cid.Decode("bafkreihwsnuregceqh263vgdathcprnbvatyat6h6mu7ipjhhodcdbyhoy")
+		require.NoError(t, err)
+
+		restoreParams := setParams(missingCID.String(), "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+missingCID.String(), nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, "*")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+	})
+
+	t.Run("Range -> Partial Content", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil)
+		req.Header.Set(rangeHeader, "bytes=0-3")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusPartialContent, rw.Result().StatusCode)
+		require.Equal(t, content[0:4], rw.Body.Bytes())
+	})
+
+	t.Run("Max-size exceeded -> Bad Request", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "4")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+	})
+
+	t.Run("Configured max size exceeded -> Bad Request", func(t *testing.T) {
+		cfg := handlerCfg
+		cfg.IPFSMaxSize = 4
+
+		restoreParams := setParams(cidV1, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		NewIPFSGatewayHandler(channel1, cfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV1, nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+	})
+
+	t.Run("Node is retrieved once", func(t *testing.T) {
+		countingClient := &mocks.DCASClient{}
+		countingClient.GetNodeStub = dcasClient.GetNode
+		countingClient.GetStub = dcasClient.Get
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(countingClient, nil)
+
+		restoreParams := setParams(cidV1, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		NewIPFSGatewayHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV1, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+		require.Equal(t, 1, countingClient.GetNodeCallCount())
+		require.Equal(t, 0, countingClient.GetCallCount())
+	})
+
+	t.Run("Range with max-size exceeded -> Bad Request", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "4")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil)
+		req.Header.Set(rangeHeader, "bytes=0-3")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+	})
+
+	t.Run("Not found -> Not Found", func(t *testing.T) {
+		_, otherCID, _ := newIPFSDCASClient(t, []byte("other content"))
+
+		restoreParams := setParams(otherCID, "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+otherCID, nil))
+
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+		require.Equal(t, httpserver.ContentTypeText, rw.Header().Get(httpserver.ContentTypeHeader))
+		require.Equal(t, CodeNotFound, rw.Body.String())
+	})
+
+	t.Run("Invalid CID -> Bad Request", func(t *testing.T) {
+		restoreParams := setParams("invalid", "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/invalid", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeInvalidHash, rw.Body.String())
+	})
+
+	t.Run("No CID -> Bad Request", func(t *testing.T) {
+		restoreParams := setParams("", "")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeInvalidHash, rw.Body.String())
+	})
+
+	t.Run("DCAS provider error -> Server Error", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(nil, errors.New("injected DCAS provider error"))
+
+		rw := httptest.NewRecorder()
+		NewIPFSGatewayHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, CodeCasNotReachable, rw.Body.String())
+	})
+
+	t.Run("GetNode error -> Server Error", func(t *testing.T) {
+		restoreParams := setParams(cidV0, "")
+		defer restoreParams()
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithGetNodeError(errors.New("injected GetNode error")), nil)
+
+		rw := httptest.NewRecorder()
+		NewIPFSGatewayHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas/ipfs/"+cidV0, nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, CodeCasNotReachable, rw.Body.String())
+	})
+}
+
+func TestNewIPFSCatHandler(t *testing.T) {
+	h := NewIPFSCatHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+
+	require.Equal(t, "/cas/api/v0/cat", h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+
+	h = NewIPFSCatGetHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+
+	require.Equal(t, "/cas/api/v0/cat", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+}
+
+func TestIPFSCat_Handler(t *testing.T) {
+	content := []byte("some content")
+
+	dcasClient, cidV1, cidV0 := newIPFSDCASClient(t, content)
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	h := NewIPFSCatHandler(channel1, handlerCfg, dcasProvider)
+	require.NotNil(t, h)
+
+	t.Run("CIDv1 -> OK", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg="+cidV1, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+		require.Equal(t, "1", rw.Header().Get(streamOutputHeader))
+	})
+
+	t.Run("CIDv0 -> OK", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg="+cidV0, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("IPFS path -> OK", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg=/ipfs/"+cidV0, nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("No arg -> Bad Request", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat", nil))
+
+		requireIPFSError(t, rw, http.StatusBadRequest, ipfsErrClient, CodeInvalidHash)
+	})
+
+	t.Run("Not found -> Not Found", func(t *testing.T) {
+		_, otherCID, _ := newIPFSDCASClient(t, []byte("other content"))
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg="+otherCID, nil))
+
+		requireIPFSError(t, rw, http.StatusNotFound, ipfsErrNotFound, CodeNotFound)
+	})
+
+	t.Run("Max-size exceeded -> Bad Request", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?max-size=4&arg="+cidV0, nil))
+
+		requireIPFSError(t, rw, http.StatusBadRequest, ipfsErrClient, CodeMaxSizeExceeded)
+	})
+
+	t.Run("Configured max size exceeded -> Bad Request", func(t *testing.T) {
+		cfg := handlerCfg
+		cfg.IPFSMaxSize = 4
+
+		rw := httptest.NewRecorder()
+		NewIPFSCatHandler(channel1, cfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg="+cidV0, nil))
+
+		requireIPFSError(t, rw, http.StatusBadRequest, ipfsErrClient, CodeMaxSizeExceeded)
+	})
+
+	t.Run("GetNode error -> Server Error", func(t *testing.T) {
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithGetNodeError(errors.New("injected GetNode error")), nil)
+
+		rw := httptest.NewRecorder()
+		NewIPFSCatHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodPost, "/cas/api/v0/cat?arg="+cidV0, nil))
+
+		requireIPFSError(t, rw, http.StatusInternalServerError, ipfsErrNormal, CodeCasNotReachable)
+	})
+}
+
+func TestIPFSMaxSize(t *testing.T) {
+	restoreParams := setParams("", "")
+	defer restoreParams()
+
+	req := httptest.NewRequest(http.MethodGet, "/cas/ipfs/xxx", nil)
+
+	require.Equal(t, defaultIPFSMaxSize, NewIPFSGatewayHandler(channel1, handlerCfg, nil).ipfsMaxSize(req))
+
+	cfg := handlerCfg
+	cfg.IPFSMaxSize = 1000
+
+	h := NewIPFSGatewayHandler(channel1, cfg, nil)
+	require.Equal(t, 1000, h.ipfsMaxSize(req))
+
+	getMaxSize = func(req *http.Request) int { return 100 }
+	require.Equal(t, 100, h.ipfsMaxSize(req))
+
+	// A larger max-size than the configured maximum may not be requested
+	getMaxSize = func(req *http.Request) int { return 2000 }
+	require.Equal(t, 1000, h.ipfsMaxSize(req))
+
+	getMaxSize = func(req *http.Request) int { return -1 }
+	require.Equal(t, 1000, h.ipfsMaxSize(req))
+}
+
+func TestEquivalentCIDs(t *testing.T) {
+	_, cidV1Str, cidV0Str := newIPFSDCASClient(t, []byte("some content"))
+
+	cidV1, err := cid.Decode(cidV1Str)
+	require.NoError(t, err)
+
+	cidV0, err := cid.Decode(cidV0Str)
+	require.NoError(t, err)
+
+	dagPBV1 := cid.NewCidV1(cid.DagProtobuf, cidV1.Hash())
+
+	require.Equal(t, []cid.Cid{cidV0, dagPBV1, cidV1}, equivalentCIDs(cidV0))
+	require.Equal(t, []cid.Cid{cidV1, dagPBV1, cidV0}, equivalentCIDs(cidV1))
+	require.Equal(t, []cid.Cid{dagPBV1, cidV1, cidV0}, equivalentCIDs(dagPBV1))
+}
+
+// newIPFSDCASClient returns a mock DCAS client containing the given content along with the CIDv1 under which the
+// content is stored and the equivalent CIDv0
+func newIPFSDCASClient(t *testing.T, content []byte) (*mocks.MockDCASClient, string, string) {
+	dcasClient := mocks.NewDCASClient()
+
+	cidV1, err := dcasClient.Put(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	dcasClient.WithNode(cidV1, &dcasclient.Node{Data: content})
+
+	cID, err := cid.Decode(cidV1)
+	require.NoError(t, err)
+
+	return dcasClient, cidV1, cid.NewCidV0(cID.Hash()).String()
+}
+
+func requireIPFSError(t *testing.T, rw *httptest.ResponseRecorder, status, code int, msg string) {
+	require.Equal(t, status, rw.Result().StatusCode)
+	require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
+
+	ipfsErr := &IPFSError{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), ipfsErr))
+	require.Equal(t, code, ipfsErr.Code)
+	require.Equal(t, msg, ipfsErr.Message)
+	require.Equal(t, "error", ipfsErr.Type)
+}
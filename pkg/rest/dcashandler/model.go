@@ -20,9 +20,49 @@ const (
 	CodeMaxSizeNotSpecified ResultCode = "content_max_size_not_specified"
 	// CodeNotFound indicates that the content for the given hash was not found
 	CodeNotFound ResultCode = "content_not_found"
+	// CodeBatchSizeExceeded indicates that the number of hashes in a batch request exceeds the maximum allowed
+	CodeBatchSizeExceeded ResultCode = "batch_exceeds_maximum_allowed_size"
+	// CodeBatchContentSizeExceeded indicates that the content couldn't be returned in a batch since the total size of
+	// the content in the batch would exceed the maximum allowed. The content may be retrieved individually instead.
+	CodeBatchContentSizeExceeded ResultCode = "batch_content_exceeds_maximum_allowed_size"
+	// CodeIntegrityCheckFailed indicates that the stored content doesn't match its hash
+	CodeIntegrityCheckFailed ResultCode = "content_integrity_check_failed"
+	// CodeVerificationNotSupported indicates that the content for the given hash can't be verified
+	CodeVerificationNotSupported ResultCode = "content_verification_not_supported"
+	// CodeScanInProgress indicates that an integrity scan of the content is already running
+	CodeScanInProgress ResultCode = "integrity_scan_in_progress"
 )
 
 // UploadResponse contains the response from a CAS upload
 type UploadResponse struct {
 	Hash string `json:"hash"`
 }
+
+// BatchRequest contains the hashes of the content to retrieve in a batch
+type BatchRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// BatchResponse contains the results of a batch retrieval in the same order as the requested hashes
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+}
+
+// BatchResult contains the result of retrieving the content for a single hash in a batch
+type BatchResult struct {
+	Hash string `json:"hash"`
+	// Status is the HTTP status code that would have been returned if the content were retrieved individually
+	Status int `json:"status"`
+	// Code is set if the content could not be retrieved
+	Code ResultCode `json:"code,omitempty"`
+	// Content is set if the content was retrieved successfully
+	Content []byte `json:"content,omitempty"`
+}
+
+// IPFSError is the error that's returned by the IPFS API handler. It has the same format as the errors
+// returned by the IPFS HTTP API so that IPFS clients are able to parse it.
+type IPFSError struct {
+	Message string `json:"Message"`
+	Code    int    `json:"Code"`
+	Type    string `json:"Type"`
+}
@@ -15,8 +15,12 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/dcascid"
 	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 )
 
@@ -25,6 +29,8 @@ var logger = flogging.MustGetLogger("sidetree_peer")
 const (
 	hashParam    = "hash"
 	maxSizeParam = "max-size"
+	verifyParam  = "verify"
+	rangeHeader  = "Range"
 
 	encodingErrMsg = "selected encoding not supported"
 )
@@ -66,7 +72,12 @@ func (h *Retrieve) Handler() common.HTTPRequestHandler {
 	return h.retrieve
 }
 
-// version retrieves the content from the DCAS store by hash
+// retrieve retrieves the content from the DCAS store by hash. The content is streamed to the response as it's
+// retrieved, except for Range requests and requests with verify=true, for which the content is buffered. In
+// either case the retrieval is aborted as soon as the content exceeds max-size. If the size of the content
+// can be determined from its DCAS node (i.e. for raw and UnixFS content) then it's returned in the Content-Length
+// and X-Content-Size headers (so that the client may track the progress of the download), otherwise the size
+// is returned in the X-Content-Size trailer once the content has been streamed.
 func (h *Retrieve) retrieve(rw http.ResponseWriter, req *http.Request) {
 	hash := getHash(req)
 
@@ -76,68 +87,251 @@ func (h *Retrieve) retrieve(rw http.ResponseWriter, req *http.Request) {
 
 	rrw := newRetrieveWriter(rw)
 
-	content, err := h.doRetrieve(hash, maxSize)
-	if err != nil {
+	// DCAS content is immutable so, if the client already has the content for the hash, there's no need to retrieve it
+	if hash != "" && httpserver.IfNoneMatchETag(req, httpserver.ETag(hash)) {
+		logger.Debugf("[%s:%s:%s] Content for hash [%s] not modified", h.channelID, h.ChaincodeName, h.Collection, hash)
+
+		rrw.WriteNotModified(hash)
+		return
+	}
+
+	if err := validateRequest(hash, maxSize); err != nil {
 		rrw.WriteError(err)
 		return
 	}
 
-	logger.Debugf("[%s:%s:%s] ... retrieved content for hash [%s]: Content: %s", h.channelID, h.ChaincodeName, h.Collection, hash, content)
+	// If-None-Match: * only matches if the content exists
+	if httpserver.IfNoneMatch(req, httpserver.ETag(hash)) {
+		if _, err := h.getNode(hash); err != nil {
+			rrw.WriteError(err)
+			return
+		}
+
+		rrw.WriteNotModified(hash)
+		return
+	}
+
+	verify := getVerify(req)
+
+	if verify || req.Header.Get(rangeHeader) != "" {
+		// A Range request requires random access to the content and verification requires the entire content
+		// to be hashed before it's returned, so the content is buffered (up to max-size)
+		content, err := h.doRetrieve(hash, maxSize)
+		if err != nil {
+			rrw.WriteError(err)
+			return
+		}
+
+		if verify {
+			if err := h.verify(hash, content); err != nil {
+				rrw.WriteError(err)
+				return
+			}
+		}
+
+		logger.Debugf("[%s:%s:%s] ... retrieved %d bytes for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, len(content), hash)
 
-	rrw.Write(content)
+		rrw.WriteContent(req, content, hash)
+		return
+	}
+
+	if err := h.stream(rw, hash, hash, maxSize); err != nil {
+		rrw.WriteError(err)
+	}
 }
 
-func (h *Retrieve) doRetrieve(hash string, maxSize int) ([]byte, error) {
-	if hash == "" {
-		return nil, newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+// stream streams the content that's stored in DCAS under the given key to the response, using the given hash for
+// the ETag. (The key differs from the hash only if the content was requested using a different version of its CID.)
+// If an error occurs before the response is started then the error is returned so that the caller may write it,
+// otherwise (if the content exceeds max-size or if an error occurs after part of the content has already been
+// sent) the response is aborted.
+func (h *Retrieve) stream(rw http.ResponseWriter, hash, key string, maxSize int) error {
+	var node *dcas.Node
+
+	if hasContentSize(key) {
+		var err error
+		node, err = h.getNode(key)
+		if err != nil {
+			return err
+		}
 	}
 
-	if maxSize == 0 {
-		return nil, newRetrieveError(http.StatusBadRequest, CodeMaxSizeNotSpecified)
+	return h.streamNode(rw, hash, key, node, maxSize)
+}
+
+// streamNode streams the content of the given node (which may be nil if it wasn't retrieved). If the size of
+// the content is known from the node then it's checked against max-size before anything is streamed and it's
+// returned in the headers of the response. The content of a raw node is the data of the node, so it isn't
+// retrieved again.
+func (h *Retrieve) streamNode(rw http.ResponseWriter, hash, key string, node *dcas.Node, maxSize int) error {
+	sw := newStreamWriter(rw, hash)
+	lw := newLimitWriter(sw, maxSize)
+
+	if node != nil {
+		if size, ok := contentSize(key, node); ok {
+			if maxSize > 0 && size > maxSize {
+				logger.Debugf("[%s:%s:%s] Content for hash [%s] of size %d exceeds max-size %d", h.channelID, h.ChaincodeName, h.Collection, hash, size, maxSize)
+
+				return newRetrieveError(http.StatusBadRequest, CodeMaxSizeExceeded)
+			}
+
+			sw.SetSize(size)
+		}
+	}
+
+	if err := h.writeContent(key, node, lw); err != nil {
+		if !sw.Started() {
+			return err
+		}
+
+		logger.Warnf("[%s:%s:%s] Aborting response for hash [%s] after %d bytes: %s", h.channelID, h.ChaincodeName, h.Collection, hash, lw.Size(), err)
+
+		panic(http.ErrAbortHandler)
 	}
 
-	content, err := h.retrieveContent(hash)
+	logger.Debugf("[%s:%s:%s] ... streamed %d bytes for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, lw.Size(), hash)
+
+	sw.Finish(lw.Size())
+
+	return nil
+}
+
+// verify re-hashes the given content according to the multihash of the CID. The content of a DAG-PB node (for
+// example, a large file that's stored in multiple blocks) is reassembled from the blocks when it's retrieved, so
+// it doesn't hash to the CID and therefore can't be verified.
+func (h *Retrieve) verify(hash string, content []byte) error {
+	cID, err := cid.Decode(hash)
 	if err != nil {
-		return nil, err
+		return newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+	}
+
+	if cID.Prefix().Codec == cid.DagProtobuf {
+		logger.Debugf("[%s:%s:%s] Content for DAG-PB hash [%s] can't be verified", h.channelID, h.ChaincodeName, h.Collection, hash)
+
+		return newRetrieveError(http.StatusBadRequest, CodeVerificationNotSupported)
+	}
+
+	if err := dcascid.Verify(hash, content); err != nil {
+		if errors.Cause(err) == dcascid.ErrMismatch {
+			logger.Errorf("[%s:%s:%s] Content integrity check failed for hash [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, hash, err)
+
+			return newRetrieveError(http.StatusInternalServerError, CodeIntegrityCheckFailed)
+		}
+
+		logger.Debugf("[%s:%s:%s] Content for hash [%s] can't be verified: %s", h.channelID, h.ChaincodeName, h.Collection, hash, err)
+
+		return newRetrieveError(http.StatusBadRequest, CodeVerificationNotSupported)
+	}
+
+	return nil
+}
+
+func validateRequest(hash string, maxSize int) error {
+	if hash == "" {
+		return newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
 	}
 
-	if maxSize > 0 && len(content) > maxSize {
-		return nil, newRetrieveError(http.StatusBadRequest, CodeMaxSizeExceeded)
+	if maxSize == 0 {
+		return newRetrieveError(http.StatusBadRequest, CodeMaxSizeNotSpecified)
+	}
+
+	return nil
+}
+
+func (h *Retrieve) doRetrieve(hash string, maxSize int) ([]byte, error) {
+	content := bytes.NewBuffer(nil)
+
+	if err := h.retrieveContent(hash, newLimitWriter(content, maxSize)); err != nil {
+		return nil, err
 	}
 
-	return content, nil
+	return content.Bytes(), nil
 }
 
-func (h *Retrieve) retrieveContent(hash string) ([]byte, error) {
+// retrieveContent writes the content for the given hash to the given writer. The retrieval is aborted as soon as
+// the content exceeds the maximum size of the writer.
+func (h *Retrieve) retrieveContent(hash string, w *limitWriter) error {
 	dcasClient, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
 
-		return nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+		return newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
 	}
 
-	content := bytes.NewBuffer(nil)
-
-	err = dcasClient.Get(hash, content)
+	err = dcasClient.Get(hash, w)
 	if err != nil {
+		if w.SizeExceeded() {
+			logger.Debugf("[%s:%s:%s] Content for hash [%s] exceeds max-size %d", h.channelID, h.ChaincodeName, h.Collection, hash, w.maxSize)
+
+			return newRetrieveError(http.StatusBadRequest, CodeMaxSizeExceeded)
+		}
+
 		if strings.Contains(err.Error(), encodingErrMsg) {
 			logger.Debugf("[%s:%s:%s] Error retrieving DCAS document for hash [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, hash, err)
 
-			return nil, newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
+			return newRetrieveError(http.StatusBadRequest, CodeInvalidHash)
 		}
 
 		logger.Errorf("[%s:%s:%s] Error retrieving DCAS document for hash [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, hash, err)
 
-		return nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+		return newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
 	}
 
-	if len(content.Bytes()) == 0 {
+	if w.Size() == 0 {
 		logger.Debugf("[%s:%s:%s] Content not found in DCAS for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, hash)
 
+		return newRetrieveError(http.StatusNotFound, CodeNotFound)
+	}
+
+	return nil
+}
+
+// writeContent writes the content that's stored under the given key to the given writer. The content of a raw node
+// is written directly, otherwise the content is retrieved from DCAS.
+func (h *Retrieve) writeContent(key string, node *dcas.Node, w *limitWriter) error {
+	if node == nil || !isRawNode(key) {
+		return h.retrieveContent(key, w)
+	}
+
+	if len(node.Data) == 0 {
+		logger.Debugf("[%s:%s:%s] Content not found in DCAS for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, key)
+
+		return newRetrieveError(http.StatusNotFound, CodeNotFound)
+	}
+
+	if _, err := w.Write(node.Data); err != nil {
+		logger.Warnf("[%s:%s:%s] Error writing content for hash [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, key, err)
+
+		return newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+	}
+
+	return nil
+}
+
+// getNode returns the DCAS node that's stored under the given key. A 404 (not found) error is returned if there's
+// no such node.
+func (h *Retrieve) getNode(key string) (*dcas.Node, error) {
+	dcasClient, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+		return nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+	}
+
+	node, err := dcasClient.GetNode(key)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Error retrieving DCAS node for hash [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, key, err)
+
+		return nil, newRetrieveError(http.StatusInternalServerError, CodeCasNotReachable)
+	}
+
+	if node == nil {
+		logger.Debugf("[%s:%s:%s] Content not found in DCAS for hash [%s]", h.channelID, h.ChaincodeName, h.Collection, key)
+
 		return nil, newRetrieveError(http.StatusNotFound, CodeNotFound)
 	}
 
-	return content.Bytes(), nil
+	return node, nil
 }
 
 var getHash = func(req *http.Request) string {
@@ -155,6 +349,22 @@ var getMaxSize = func(req *http.Request) int {
 	return 0
 }
 
+var getVerify = func(req *http.Request) bool {
+	values := getParams(req)[verifyParam]
+	if len(values) == 0 {
+		return false
+	}
+
+	verify, err := strconv.ParseBool(values[0])
+	if err != nil {
+		logger.Debugf("Invalid value for parameter [verify]: %s", err)
+
+		return false
+	}
+
+	return verify
+}
+
 func maxSizeFromString(str string) int {
 	size, err := strconv.Atoi(str)
 	if err != nil {
@@ -176,8 +386,14 @@ func newRetrieveWriter(rw http.ResponseWriter) *retrieveWriter {
 	}
 }
 
-func (rw *retrieveWriter) Write(content []byte) {
-	rw.ResponseWriter.Write(http.StatusOK, content, httpserver.ContentTypeBinary)
+// WriteContent writes the content for the given hash. Since the content is addressed by hash, it never changes
+// and may therefore be cached indefinitely.
+func (rw *retrieveWriter) WriteContent(req *http.Request, content []byte, hash string) {
+	rw.ResponseWriter.WriteContent(req, bytes.NewReader(content), httpserver.ContentTypeBinary, httpserver.ETag(hash), httpserver.CacheControlImmutable)
+}
+
+func (rw *retrieveWriter) WriteNotModified(hash string) {
+	rw.ResponseWriter.WriteNotModified(httpserver.ETag(hash), httpserver.CacheControlImmutable)
 }
 
 func (rw *retrieveWriter) WriteError(err error) {
@@ -7,13 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package dcashandler
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
 
 	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
@@ -165,9 +169,111 @@ func TestRetrieve_Handler(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
 		require.Equal(t, httpserver.ContentTypeBinary, rw.Header().Get(httpserver.ContentTypeHeader))
+		require.Equal(t, `"123456"`, rw.Header().Get(httpserver.ETagHeader))
+		require.Equal(t, httpserver.CacheControlImmutable, rw.Header().Get(httpserver.CacheControlHeader))
 		require.Equal(t, content, rw.Body.Bytes())
 	})
 
+	t.Run("If-None-Match -> Not Modified", func(t *testing.T) {
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasClient := mocks.NewDCASClient().WithGetError(errors.New("content should not be retrieved"))
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, `"123456"`)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Equal(t, `"123456"`, rw.Header().Get(httpserver.ETagHeader))
+		require.Equal(t, httpserver.CacheControlImmutable, rw.Header().Get(httpserver.CacheControlHeader))
+		require.Empty(t, rw.Body.Bytes())
+	})
+
+	t.Run("If-None-Match * -> Not Modified", func(t *testing.T) {
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasClient := mocks.NewDCASClient().WithData("123456", []byte{1, 2, 3, 4}).WithGetError(errors.New("content should not be retrieved"))
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, `*`)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotModified, rw.Result().StatusCode)
+		require.Equal(t, `"123456"`, rw.Header().Get(httpserver.ETagHeader))
+		require.Empty(t, rw.Body.Bytes())
+	})
+
+	t.Run("If-None-Match * with no content -> Not Found", func(t *testing.T) {
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient(), nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, `*`)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+	})
+
+	t.Run("If-None-Match * with GetNode error -> Server Error", func(t *testing.T) {
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithGetNodeError(errors.New("injected GetNode error")), nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, `*`)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+	})
+
+	t.Run("If-None-Match with different hash -> Success", func(t *testing.T) {
+		content := []byte{1, 2, 3, 4}
+
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasClient := mocks.NewDCASClient().WithData("123456", content)
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set(httpserver.IfNoneMatchHeader, `"654321"`)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("Range -> Partial Content", func(t *testing.T) {
+		content := []byte{1, 2, 3, 4}
+
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		dcasClient := mocks.NewDCASClient().WithData("123456", content)
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set("Range", "bytes=1-2")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusPartialContent, rw.Result().StatusCode)
+		require.Equal(t, "bytes 1-2/4", rw.Header().Get("Content-Range"))
+		require.Equal(t, []byte{2, 3}, rw.Body.Bytes())
+	})
+
 	t.Run("max-size exceeded -> error", func(t *testing.T) {
 		content := []byte{1, 2, 3, 4}
 
@@ -187,14 +293,135 @@ func TestRetrieve_Handler(t *testing.T) {
 	})
 }
 
-func TestRetrieveWriter_Write(t *testing.T) {
+func TestRetrieve_Verify(t *testing.T) {
+	content := []byte("some content")
+
+	dcasClient := mocks.NewDCASClient()
+
+	cID, err := dcasClient.Put(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	h := NewRetrieveHandler(channel1, handlerCfg, dcasProvider)
+	require.NotNil(t, h)
+
+	t.Run("Success", func(t *testing.T) {
+		restoreParams := setParams(cID, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("Range -> Partial Content", func(t *testing.T) {
+		restoreParams := setParams(cID, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil)
+		req.Header.Set("Range", "bytes=1-2")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusPartialContent, rw.Result().StatusCode)
+		require.Equal(t, content[1:3], rw.Body.Bytes())
+	})
+
+	t.Run("Mismatch -> Server Error", func(t *testing.T) {
+		restoreParams := setParams(cID, maxSize)
+		defer restoreParams()
+
+		corruptedClient := mocks.NewDCASClient().WithData(cID, []byte("corrupted content"))
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(corruptedClient, nil)
+
+		rw := httptest.NewRecorder()
+		NewRetrieveHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil))
+
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, CodeIntegrityCheckFailed, rw.Body.String())
+
+		// Without verification the content is returned
+		rw = httptest.NewRecorder()
+		NewRetrieveHandler(channel1, handlerCfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=false", nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	})
+
+	t.Run("DAG-PB -> Bad Request", func(t *testing.T) {
+		dagPBCID, err := dcas.GetCID(content, dcas.CIDV1, cid.DagProtobuf, mh.SHA2_256)
+		require.NoError(t, err)
+
+		dcasClient.WithData(dagPBCID, content)
+
+		restoreParams := setParams(dagPBCID, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeVerificationNotSupported, rw.Body.String())
+	})
+
+	t.Run("Unsupported hash -> Bad Request", func(t *testing.T) {
+		hash, err := mh.Encode([]byte("1234"), mh.X11)
+		require.NoError(t, err)
+
+		x11CID := cid.NewCidV1(cid.Raw, hash).String()
+
+		dcasClient.WithData(x11CID, content)
+
+		restoreParams := setParams(x11CID, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeVerificationNotSupported, rw.Body.String())
+	})
+
+	t.Run("Invalid hash -> Bad Request", func(t *testing.T) {
+		dcasClient.WithData(hash, content)
+
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=true", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, CodeInvalidHash, rw.Body.String())
+	})
+
+	t.Run("Invalid verify parameter -> not verified", func(t *testing.T) {
+		dcasClient.WithData(hash, content)
+
+		restoreParams := setParams(hash, maxSize)
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas?verify=xxx", nil))
+
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+}
+
+func TestRetrieveWriter_WriteContent(t *testing.T) {
 	content := []byte{1, 2, 3, 4}
 
 	r := httptest.NewRecorder()
 	rw := newRetrieveWriter(r)
 	require.NotNil(t, rw)
 
-	rw.Write(content)
+	rw.WriteContent(httptest.NewRequest(http.MethodGet, "/cas", nil), content, hash)
 
 	require.Equal(t, http.StatusOK, r.Result().StatusCode)
 	require.Equal(t, httpserver.ContentTypeBinary, r.Header().Get(httpserver.ContentTypeHeader))
@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+)
+
+// streamBufferSize is the number of bytes that are buffered before the response is started
+const streamBufferSize = 64 * 1024
+
+var errMaxSizeExceeded = errors.New("content exceeds max-size")
+
+// limitWriter fails as soon as more than maxSize bytes are written to it so that a retrieval
+// from DCAS is aborted early. A maxSize less than 0 means that the size is unlimited.
+type limitWriter struct {
+	w        io.Writer
+	maxSize  int
+	size     int
+	exceeded bool
+}
+
+func newLimitWriter(w io.Writer, maxSize int) *limitWriter {
+	return &limitWriter{
+		w:       w,
+		maxSize: maxSize,
+	}
+}
+
+// Write writes the given bytes to the underlying writer unless the maximum size would be exceeded
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.maxSize > 0 && w.size+len(p) > w.maxSize {
+		w.exceeded = true
+
+		return 0, errMaxSizeExceeded
+	}
+
+	n, err := w.w.Write(p)
+	w.size += n
+
+	return n, err
+}
+
+// Size returns the number of bytes written
+func (w *limitWriter) Size() int {
+	return w.size
+}
+
+// SizeExceeded returns true if the maximum size was exceeded
+func (w *limitWriter) SizeExceeded() bool {
+	return w.exceeded
+}
+
+// streamWriter writes content to the response as it's retrieved from DCAS. The first streamBufferSize bytes are
+// buffered before the response is started so that small content is written along with its Content-Length, and so
+// that an error which occurs early (such as max-size being exceeded) can still be reported with an error status.
+// If the size of the content is known in advance (see SetSize) then it's reported in the Content-Length and
+// X-Content-Size headers so that the client may track the progress of the download, otherwise, once the response
+// is started, the size of the content is reported in the X-Content-Size trailer.
+type streamWriter struct {
+	rw      http.ResponseWriter
+	hash    string
+	buf     []byte
+	size    int
+	started bool
+}
+
+func newStreamWriter(rw http.ResponseWriter, hash string) *streamWriter {
+	return &streamWriter{
+		rw:   rw,
+		hash: hash,
+		size: -1,
+	}
+}
+
+// SetSize sets the size of the content, which is then returned in the headers of the response
+func (w *streamWriter) SetSize(size int) {
+	w.size = size
+}
+
+// Write writes the given bytes to the response
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.started {
+		return w.write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+
+	if len(w.buf) < streamBufferSize {
+		return len(p), nil
+	}
+
+	w.start(nil)
+
+	buf := w.buf
+	w.buf = nil
+
+	if _, err := w.write(buf); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Started returns true if the response has been started, in which case an error status may no longer be written
+func (w *streamWriter) Started() bool {
+	return w.started
+}
+
+// Finish completes the response. If the response hasn't been started then the buffered content is written
+// along with its size, otherwise the size is written in the trailer.
+func (w *streamWriter) Finish(size int) {
+	if w.started {
+		if w.size < 0 {
+			w.rw.Header().Set(httpserver.ContentSizeHeader, strconv.Itoa(size))
+		}
+
+		return
+	}
+
+	w.start(func(h http.Header) {
+		h.Set("Content-Length", strconv.Itoa(len(w.buf)))
+		h.Set(httpserver.ContentSizeHeader, strconv.Itoa(size))
+	})
+
+	if _, err := w.rw.Write(w.buf); err != nil {
+		logger.Warnf("Error writing content for hash [%s]: %s", w.hash, err)
+	}
+}
+
+func (w *streamWriter) start(setHeaders func(h http.Header)) {
+	h := w.rw.Header()
+	h.Set(httpserver.ContentTypeHeader, httpserver.ContentTypeBinary)
+	h.Set(httpserver.ETagHeader, httpserver.ETag(w.hash))
+	h.Set(httpserver.CacheControlHeader, httpserver.CacheControlImmutable)
+
+	switch {
+	case setHeaders != nil:
+		setHeaders(h)
+	case w.size >= 0:
+		h.Set("Content-Length", strconv.Itoa(w.size))
+		h.Set(httpserver.ContentSizeHeader, strconv.Itoa(w.size))
+	default:
+		h.Set("Trailer", httpserver.ContentSizeHeader)
+	}
+
+	w.rw.WriteHeader(http.StatusOK)
+	w.started = true
+}
+
+func (w *streamWriter) write(p []byte) (int, error) {
+	n, err := w.rw.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if f, ok := w.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return n, nil
+}
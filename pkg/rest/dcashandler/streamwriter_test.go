@@ -0,0 +1,274 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestLimitWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	w := newLimitWriter(buf, 5)
+
+	n, err := w.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, 3, w.Size())
+
+	_, err = w.Write([]byte{4, 5, 6})
+	require.Equal(t, errMaxSizeExceeded, err)
+	require.True(t, w.SizeExceeded())
+	require.Equal(t, []byte{1, 2, 3}, buf.Bytes())
+
+	w = newLimitWriter(bytes.NewBuffer(nil), -1)
+	_, err = w.Write(make([]byte, 1000))
+	require.NoError(t, err)
+	require.False(t, w.SizeExceeded())
+}
+
+func TestStreamWriter(t *testing.T) {
+	t.Run("Small content", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		w := newStreamWriter(rw, hash)
+
+		_, err := w.Write([]byte{1, 2, 3, 4})
+		require.NoError(t, err)
+		require.False(t, w.Started())
+
+		w.Finish(4)
+
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "4", rw.Header().Get("Content-Length"))
+		require.Equal(t, "4", rw.Header().Get(httpserver.ContentSizeHeader))
+		require.Equal(t, []byte{1, 2, 3, 4}, rw.Body.Bytes())
+	})
+
+	t.Run("Large content", func(t *testing.T) {
+		content := make([]byte, 3*streamBufferSize/2)
+
+		rw := httptest.NewRecorder()
+		w := newStreamWriter(rw, hash)
+
+		_, err := w.Write(content[0 : streamBufferSize/2])
+		require.NoError(t, err)
+		require.False(t, w.Started())
+
+		_, err = w.Write(content[streamBufferSize/2 : streamBufferSize])
+		require.NoError(t, err)
+		require.True(t, w.Started())
+		require.Equal(t, streamBufferSize, rw.Body.Len())
+
+		_, err = w.Write(content[streamBufferSize:])
+		require.NoError(t, err)
+
+		w.Finish(len(content))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Empty(t, result.Header.Get("Content-Length"))
+		require.Equal(t, `"`+hash+`"`, result.Header.Get(httpserver.ETagHeader))
+		require.Equal(t, strconv.Itoa(len(content)), result.Trailer.Get(httpserver.ContentSizeHeader))
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("Large content with known size", func(t *testing.T) {
+		content := make([]byte, 3*streamBufferSize/2)
+
+		rw := httptest.NewRecorder()
+		w := newStreamWriter(rw, hash)
+		w.SetSize(len(content))
+
+		_, err := w.Write(content)
+		require.NoError(t, err)
+		require.True(t, w.Started())
+
+		w.Finish(len(content))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, strconv.Itoa(len(content)), result.Header.Get("Content-Length"))
+		require.Equal(t, strconv.Itoa(len(content)), result.Header.Get(httpserver.ContentSizeHeader))
+		require.Empty(t, result.Trailer.Get(httpserver.ContentSizeHeader))
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+}
+
+func TestRetrieve_Stream(t *testing.T) {
+	content := make([]byte, 2*streamBufferSize)
+	for i := range content {
+		content[i] = byte(i)
+	}
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+	h := NewRetrieveHandler(channel1, handlerCfg, dcasProvider)
+
+	t.Run("Success", func(t *testing.T) {
+		restoreParams := setParams(hash, strconv.Itoa(len(content)))
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, strconv.Itoa(len(content)), result.Trailer.Get(httpserver.ContentSizeHeader))
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("max-size exceeded before response started -> error", func(t *testing.T) {
+		restoreParams := setParams(hash, "100")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+	})
+
+	t.Run("max-size exceeded after response started -> abort", func(t *testing.T) {
+		restoreParams := setParams(hash, strconv.Itoa(len(content)-1))
+		defer restoreParams()
+
+		dcasClient := &mocks.DCASClient{}
+		dcasClient.GetStub = func(_ string, w io.Writer) error {
+			// Write the content in blocks, as the DCAS client does
+			for i := 0; i < len(content); i += streamBufferSize / 2 {
+				if _, err := w.Write(content[i : i+streamBufferSize/2]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+		defer dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithData(hash, content), nil)
+
+		rw := httptest.NewRecorder()
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+		})
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, 3*streamBufferSize/2, rw.Body.Len())
+	})
+
+	t.Run("Range with max-size exceeded -> error", func(t *testing.T) {
+		restoreParams := setParams(hash, "100")
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas", nil)
+		req.Header.Set("Range", "bytes=0-9")
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+	})
+}
+
+func TestRetrieve_StreamKnownSize(t *testing.T) {
+	t.Run("Raw", func(t *testing.T) {
+		content := make([]byte, 2*streamBufferSize)
+
+		// The content of a raw node is returned from the node so it must not be retrieved again
+		dcasClient := mocks.NewDCASClient().WithGetError(errors.New("content should not be retrieved"))
+
+		cID, err := dcasClient.Put(bytes.NewReader(content))
+		require.NoError(t, err)
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		h := NewRetrieveHandler(channel1, handlerCfg, dcasProvider)
+
+		restoreParams := setParams(cID, strconv.Itoa(len(content)))
+		defer restoreParams()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, strconv.Itoa(len(content)), result.Header.Get("Content-Length"))
+		require.Equal(t, strconv.Itoa(len(content)), result.Header.Get(httpserver.ContentSizeHeader))
+		require.Equal(t, content, rw.Body.Bytes())
+	})
+
+	t.Run("DAG-PB", func(t *testing.T) {
+		content := make([]byte, 2*streamBufferSize)
+
+		key, node := newFileNode(t, len(content))
+
+		dcasClient := &mocks.DCASClient{}
+		dcasClient.GetNodeReturns(node, nil)
+		dcasClient.GetStub = func(_ string, w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}
+
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		h := NewRetrieveHandler(channel1, handlerCfg, dcasProvider)
+
+		t.Run("Success", func(t *testing.T) {
+			restoreParams := setParams(key, strconv.Itoa(len(content)))
+			defer restoreParams()
+
+			rw := httptest.NewRecorder()
+			h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+			result := rw.Result()
+			require.Equal(t, http.StatusOK, result.StatusCode)
+			require.Equal(t, strconv.Itoa(len(content)), result.Header.Get("Content-Length"))
+			require.Equal(t, strconv.Itoa(len(content)), result.Header.Get(httpserver.ContentSizeHeader))
+			require.Equal(t, content, rw.Body.Bytes())
+		})
+
+		t.Run("max-size exceeded -> error before content is retrieved", func(t *testing.T) {
+			restoreParams := setParams(key, strconv.Itoa(len(content)-1))
+			defer restoreParams()
+
+			getCount := dcasClient.GetCallCount()
+
+			rw := httptest.NewRecorder()
+			h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+			require.Equal(t, http.StatusBadRequest, rw.Code)
+			require.Equal(t, CodeMaxSizeExceeded, rw.Body.String())
+			require.Equal(t, getCount, dcasClient.GetCallCount())
+		})
+
+		t.Run("Not found", func(t *testing.T) {
+			restoreParams := setParams(key, strconv.Itoa(len(content)))
+			defer restoreParams()
+
+			dcasProvider.GetDCASClientReturns(&mocks.DCASClient{}, nil)
+			defer dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+			rw := httptest.NewRecorder()
+			h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/cas", nil))
+
+			require.Equal(t, http.StatusNotFound, rw.Code)
+			require.Equal(t, CodeNotFound, rw.Body.String())
+		})
+	})
+}
@@ -7,28 +7,46 @@ SPDX-License-Identifier: Apache-2.0
 package dcashandler
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
+	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
 	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/authhandler"
 )
 
+const retryAfterHeader = "Retry-After"
+
+type quotaManager interface {
+	Reserve(token string) (*dcasquota.Allowance, error)
+	Record(token string, size int64) error
+	RetryAfter() time.Duration
+}
+
 // Upload manages uploads to a DCAS store
 type Upload struct {
 	Config
 	channelID    string
 	dcasProvider dcasClientProvider
+	quotas       quotaManager
 }
 
-// NewUploadHandler returns a new DCAS upload handler
-func NewUploadHandler(channelID string, cfg Config, dcasProvider dcasClientProvider) *Upload {
+// NewUploadHandler returns a new DCAS upload handler. If the quota manager is nil then uploads are
+// only limited by the maximum upload size.
+func NewUploadHandler(channelID string, cfg Config, dcasProvider dcasClientProvider, quotas quotaManager) *Upload {
 	return &Upload{
 		Config:       cfg,
 		channelID:    channelID,
 		dcasProvider: dcasProvider,
+		quotas:       quotas,
 	}
 }
 
@@ -47,20 +65,91 @@ func (h *Upload) Handler() common.HTTPRequestHandler {
 	return h.upload
 }
 
-// upload uploads the content and responds with the hash of the content.
+// upload uploads the content and responds with the hash of the content. The content is either the request body
+// or the first file in a multipart form. The content is streamed into DCAS as a file, i.e. it's split into blocks
+// of the DCAS maximum block size as it's read, so the content isn't held in memory. Content that fits in a single
+// block is stored as a raw block (whose hash is the CID of the raw content) and larger content is stored as a
+// Merkle DAG (whose hash is the CID of the root node). If a maximum upload size is configured then the upload is
+// aborted as soon as the maximum size is exceeded, so at most the maximum size is read. (The blocks that were
+// stored before the upload was aborted are unreferenced and are removed by the DCAS garbage collector.) The size
+// of the uploaded content is returned in the X-Content-Size header.
+//
+// If the client provided an expected CID (in the X-Expected-CID header) then the content must be verified before
+// it's stored, so it's read into memory (up to the maximum upload size) and stored as a single raw block. If the
+// CID doesn't match the hash of the content then a 400 (cid-mismatch) is returned and the content isn't stored.
+//
+// If a quota is configured for the token that authorized the request then a 429 (rate-limit-exceeded) is returned
+// if the token has reached its maximum number of uploads in the current hour and a 413 (quota-exceeded) is returned
+// if the content would exceed the storage quota of the token.
 func (h *Upload) upload(w http.ResponseWriter, req *http.Request) {
 	rw := newUploadWriter(w)
 
-	hash, err := h.doUpload(req.Body)
+	token := authhandler.TokenName(req)
+
+	allowance, err := h.reserve(token)
+	if err != nil {
+		if err == httpserver.RateLimitExceededError {
+			rw.Header().Set(retryAfterHeader, strconv.Itoa(int(h.quotas.RetryAfter().Seconds())))
+		}
+
+		rw.WriteError(err)
+		return
+	}
+
+	maxSize, quotaLimited := h.maxSize(allowance)
+
+	content, err := httpserver.OpenUpload(req, maxSize)
+	if err != nil {
+		logger.Debugf("[%s:%s:%s] Invalid upload request: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+		rw.WriteError(sizeError(err, quotaLimited))
+		return
+	}
+
+	var body io.Reader = content
+
+	opts := []dcas.Option{dcas.WithNodeType(dcas.FileNodeType)}
+
+	// The content must be verified before it's stored so, if the client provided an expected CID, the content
+	// is read (up to the maximum upload size) in order to compute its CID
+	if req.Header.Get(httpserver.ExpectedCIDHeader) != "" {
+		contentBytes, err := ioutil.ReadAll(content)
+		if err != nil {
+			logger.Debugf("[%s:%s:%s] Error reading uploaded content: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+			rw.WriteError(sizeError(readError(content), quotaLimited))
+			return
+		}
+
+		if err := httpserver.VerifyCID(req, contentBytes); err != nil {
+			rw.WriteError(err)
+			return
+		}
+
+		body = bytes.NewReader(contentBytes)
+		opts = nil
+	}
+
+	hash, err := h.doUpload(body, opts...)
 	if err != nil {
+		if content.SizeExceeded() {
+			logger.Debugf("[%s:%s:%s] Upload aborted since the content exceeds the maximum size of %d bytes", h.channelID, h.ChaincodeName, h.Collection, maxSize)
+
+			rw.WriteError(sizeError(httpserver.ContentTooLargeError, quotaLimited))
+			return
+		}
+
 		rw.WriteError(err)
 		return
 	}
 
+	h.record(token, content.Size())
+
+	rw.Header().Set(httpserver.ContentSizeHeader, strconv.FormatInt(content.Size(), 10))
 	rw.Write(hash)
 }
 
-func (h *Upload) doUpload(content io.Reader) (string, error) {
+func (h *Upload) doUpload(content io.Reader, opts ...dcas.Option) (string, error) {
 	client, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
@@ -68,7 +157,7 @@ func (h *Upload) doUpload(content io.Reader) (string, error) {
 		return "", httpserver.ServerError
 	}
 
-	hash, err := client.Put(content)
+	hash, err := client.Put(content, opts...)
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Error storing content to DCAS: %s", h.channelID, h.ChaincodeName, h.Collection, err)
 
@@ -80,6 +169,76 @@ func (h *Upload) doUpload(content io.Reader) (string, error) {
 	return hash, nil
 }
 
+// reserve checks the quota of the given token and returns the limits that apply to the upload
+func (h *Upload) reserve(token string) (*dcasquota.Allowance, error) {
+	if h.quotas == nil {
+		return &dcasquota.Allowance{}, nil
+	}
+
+	allowance, err := h.quotas.Reserve(token)
+	if err != nil {
+		switch err {
+		case dcasquota.ErrRateLimitExceeded:
+			return nil, httpserver.RateLimitExceededError
+		case dcasquota.ErrQuotaExceeded:
+			return nil, httpserver.QuotaExceededError
+		default:
+			logger.Errorf("[%s:%s:%s] Error checking quota for token [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, token, err)
+
+			return nil, httpserver.ServerError
+		}
+	}
+
+	return allowance, nil
+}
+
+// record adds the upload to the usage of the given token. The content has already been stored so an
+// error is only logged.
+func (h *Upload) record(token string, size int64) {
+	if h.quotas == nil {
+		return
+	}
+
+	if err := h.quotas.Record(token, size); err != nil {
+		logger.Errorf("[%s:%s:%s] Error recording usage of %d bytes for token [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, size, token, err)
+	}
+}
+
+// maxSize returns the maximum size of the upload (0 if unlimited) and true if the maximum size
+// is limited by the remaining storage quota of the token.
+func (h *Upload) maxSize(allowance *dcasquota.Allowance) (int64, bool) {
+	maxSize := h.MaxUploadSize
+
+	if allowance.MaxObjectSize > 0 && (maxSize == 0 || allowance.MaxObjectSize < maxSize) {
+		maxSize = allowance.MaxObjectSize
+	}
+
+	if allowance.RemainingBytes > 0 && (maxSize == 0 || allowance.RemainingBytes < maxSize) {
+		return allowance.RemainingBytes, true
+	}
+
+	return maxSize, false
+}
+
+// readError returns the error for a failed read of the uploaded content
+func readError(content *httpserver.UploadReader) error {
+	if content.SizeExceeded() {
+		return httpserver.ContentTooLargeError
+	}
+
+	return httpserver.BadRequestError
+}
+
+// sizeError returns a quota-exceeded error instead of a content-too-large error if the maximum size
+// of the upload is limited by the remaining storage quota
+func sizeError(err error, quotaLimited bool) error {
+	if quotaLimited && err == httpserver.ContentTooLargeError {
+		return httpserver.QuotaExceededError
+	}
+
+	return err
+}
+
 type uploadWriter struct {
 	*httpserver.ResponseWriter
 	jsonMarshal func(v interface{}) ([]byte, error)
@@ -8,22 +8,31 @@ package dcashandler
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
 	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/authhandler"
 )
 
 //go:generate counterfeiter -o ./mocks/ioreader.gen.go --fake-name IOReader io.Reader
 
 func TestNewUploadHandler(t *testing.T) {
-	h := NewUploadHandler(channel1, handlerCfg, nil)
+	h := NewUploadHandler(channel1, handlerCfg, nil, nil)
 	require.NotNil(t, h)
 
 	require.Equal(t, "/cas", h.Path())
@@ -33,7 +42,7 @@ func TestNewUploadHandler(t *testing.T) {
 func TestUpload_Handler(t *testing.T) {
 	dcasProvider := &mocks.DCASClientProvider{}
 
-	h := NewUploadHandler(channel1, handlerCfg, dcasProvider)
+	h := NewUploadHandler(channel1, handlerCfg, dcasProvider, nil)
 	require.NotNil(t, h)
 
 	t.Run("DCAS provider error -> Server Error", func(t *testing.T) {
@@ -84,7 +93,237 @@ func TestUpload_Handler(t *testing.T) {
 		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
 		require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
 		require.Equal(t, fmt.Sprintf(`{"hash":"%s"}`, hash), rw.Body.String())
+
+		// The request body is streamed into DCAS as a file
+		require.Equal(t, 1, dcasClient.PutCallCount())
+		body, opts := dcasClient.PutArgsForCall(0)
+		require.IsType(t, &httpserver.UploadReader{}, body)
+		require.Len(t, opts, 1)
+	})
+
+	t.Run("Multipart form", func(t *testing.T) {
+		dcasClient := mocks.NewDCASClient()
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		body := bytes.NewBuffer(nil)
+		w := multipart.NewWriter(body)
+		part, err := w.CreateFormFile("file", "file1")
+		require.NoError(t, err)
+		_, err = part.Write([]byte{1, 2, 3, 4})
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", body)
+		req.Header.Set(httpserver.ContentTypeHeader, w.FormDataContentType())
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, "4", rw.Header().Get(httpserver.ContentSizeHeader))
+
+		resp := &UploadResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+
+		content := bytes.NewBuffer(nil)
+		require.NoError(t, dcasClient.Get(resp.Hash, content))
+		require.Equal(t, []byte{1, 2, 3, 4}, content.Bytes())
+	})
+
+	t.Run("Empty content -> Bad Request", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader(nil))
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusEmptyContent, rw.Body.String())
+	})
+
+	t.Run("Max upload size exceeded", func(t *testing.T) {
+		cfg := handlerCfg
+		cfg.MaxUploadSize = 3
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte{1, 2, 3, 4}))
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusContentTooLarge, rw.Body.String())
+	})
+
+	t.Run("Expected CID mismatch -> Bad Request", func(t *testing.T) {
+		cID, err := dcas.GetCID([]byte{1, 2, 3, 4}, dcas.CIDV1, cid.Raw, mh.SHA2_256)
+		require.NoError(t, err)
+
+		dcasClient := &mocks.DCASClient{}
+		dcasClient.PutReturns(cID, nil)
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte{1, 2, 3, 4}))
+		req.Header.Set(httpserver.ExpectedCIDHeader, cID)
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		// The verified content is stored as a single raw block so that its hash is the expected CID
+		_, opts := dcasClient.PutArgsForCall(0)
+		require.Empty(t, opts)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte{1, 2, 3, 4}))
+		req.Header.Set(httpserver.ExpectedCIDHeader, "some-other-hash")
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusCIDMismatch, rw.Body.String())
+		require.Equal(t, 1, dcasClient.PutCallCount(), "content should not be stored if the CID doesn't match")
 	})
+
+	t.Run("Expected CID with max upload size exceeded", func(t *testing.T) {
+		cfg := handlerCfg
+		cfg.MaxUploadSize = 3
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, nil)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte{1, 2, 3, 4}))
+		req.Header.Set(httpserver.ExpectedCIDHeader, hash)
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusContentTooLarge, rw.Body.String())
+	})
+}
+
+func TestUpload_Quota(t *testing.T) {
+	const (
+		token1 = "token1"
+		token2 = "token2"
+	)
+
+	cfg := handlerCfg
+	cfg.UsageCollection = "usage"
+	cfg.Quotas = map[string]dcasquota.Quota{
+		"w1": {MaxBytes: 10, MaxObjectSize: 6},
+		"w2": {MaxObjectsPerHour: 1},
+	}
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(mocks.NewDCASClient(), nil)
+
+	upload := func(h common.HTTPHandler, token string, content []byte) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader(content))
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		authhandler.NewWithTokenNames(channel1, []string{"w1", "w2"}, []string{token1, token2}, h).Handler()(rw, req)
+
+		return rw
+	}
+
+	t.Run("Storage quota", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, dcasquota.New(channel1, cc1, coll1, cfg.UsageCollection, cfg.Quotas, olProvider))
+
+		rw := upload(h, token1, []byte("1234"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		rw = upload(h, token1, []byte("1234567"))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusContentTooLarge, rw.Body.String())
+
+		rw = upload(h, token1, []byte("12345"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		rw = upload(h, token1, []byte("ab"))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusQuotaExceeded, rw.Body.String())
+
+		rw = upload(h, token1, []byte("a"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		rw = upload(h, token1, []byte("b"))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusQuotaExceeded, rw.Body.String())
+
+		// No quota for token2
+		rw = upload(h, token2, []byte("1234567"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, dcasquota.New(channel1, cc1, coll1, cfg.UsageCollection, cfg.Quotas, olProvider))
+
+		rw := upload(h, token2, []byte("1234"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+
+		rw = upload(h, token2, []byte("1234"))
+		require.Equal(t, http.StatusTooManyRequests, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusRateLimitExceeded, rw.Body.String())
+		require.NotEmpty(t, rw.Header().Get("Retry-After"))
+	})
+
+	t.Run("Quota error -> Server Error", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(nil, errors.New("injected off-ledger provider error"))
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, dcasquota.New(channel1, cc1, coll1, cfg.UsageCollection, cfg.Quotas, olProvider))
+
+		rw := upload(h, token1, []byte("1234"))
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusServerError, rw.Body.String())
+	})
+
+	t.Run("Reserve store error -> Server Error", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient().WithPutError(errors.New("injected put error")), nil)
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, dcasquota.New(channel1, cc1, coll1, cfg.UsageCollection, cfg.Quotas, olProvider))
+
+		rw := upload(h, token1, []byte("1234"))
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusServerError, rw.Body.String())
+	})
+
+	t.Run("Record error -> success", func(t *testing.T) {
+		// The usage is stored when the upload is reserved and again when it's recorded, so fail the second put
+		olClient := &failingPutOffLedgerClient{
+			MockOffLedgerClient: obmocks.NewMockOffLedgerClient(),
+			failAfter:           1,
+			err:                 errors.New("injected put error"),
+		}
+
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(olClient, nil)
+
+		h := NewUploadHandler(channel1, cfg, dcasProvider, dcasquota.New(channel1, cc1, coll1, cfg.UsageCollection, cfg.Quotas, olProvider))
+
+		rw := upload(h, token1, []byte("1234"))
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, 2, olClient.puts)
+	})
+}
+
+// failingPutOffLedgerClient fails the puts after the given number of successful puts
+type failingPutOffLedgerClient struct {
+	*obmocks.MockOffLedgerClient
+	failAfter int
+	puts      int
+	err       error
+}
+
+func (m *failingPutOffLedgerClient) Put(ns, coll, key string, value []byte) error {
+	m.puts++
+
+	if m.puts > m.failAfter {
+		return m.err
+	}
+
+	return m.MockOffLedgerClient.Put(ns, coll, key, value)
 }
 
 func TestUploadWriter_Write(t *testing.T) {
@@ -0,0 +1,88 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/authhandler"
+)
+
+const usagePath = "/usage"
+
+type usageProvider interface {
+	Usage(token string) (*dcasquota.Usage, error)
+}
+
+// Usage returns the upload usage (along with the quota) of the token that authorized the request
+type Usage struct {
+	Config
+	channelID string
+	quotas    usageProvider
+}
+
+// NewUsageHandler returns a new usage handler
+func NewUsageHandler(channelID string, cfg Config, quotas usageProvider) *Usage {
+	return &Usage{
+		Config:    cfg,
+		channelID: channelID,
+		quotas:    quotas,
+	}
+}
+
+// Path returns the context path
+func (h *Usage) Path() string {
+	return h.BasePath + usagePath
+}
+
+// Method returns the HTTP method
+func (h *Usage) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the request handler
+func (h *Usage) Handler() common.HTTPRequestHandler {
+	return h.usage
+}
+
+// usage responds with the usage of the token that authorized the request. A 404 (not-found) is returned
+// if no quota is configured for the token.
+func (h *Usage) usage(rw http.ResponseWriter, req *http.Request) {
+	token := authhandler.TokenName(req)
+
+	logger.Debugf("[%s:%s:%s] Retrieving usage for token [%s]", h.channelID, h.ChaincodeName, h.Collection, token)
+
+	hrw := httpserver.NewResponseWriter(rw)
+
+	usage, err := h.quotas.Usage(token)
+	if err != nil {
+		if err == dcasquota.ErrNoQuota {
+			logger.Debugf("[%s:%s:%s] No quota configured for token [%s]", h.channelID, h.ChaincodeName, h.Collection, token)
+
+			hrw.WriteError(httpserver.NotFoundError)
+			return
+		}
+
+		logger.Errorf("[%s:%s:%s] Error retrieving usage for token [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, token, err)
+
+		hrw.WriteError(httpserver.ServerError)
+		return
+	}
+
+	usageBytes, err := json.Marshal(usage)
+	if err != nil {
+		hrw.WriteError(err)
+		return
+	}
+
+	hrw.Write(http.StatusOK, usageBytes, httpserver.ContentTypeJSON)
+}
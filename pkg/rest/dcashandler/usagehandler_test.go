@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dcashandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/dcasquota"
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
+	obmocks "github.com/trustbloc/sidetree-fabric/pkg/observer/mocks"
+	"github.com/trustbloc/sidetree-fabric/pkg/rest/authhandler"
+)
+
+func TestNewUsageHandler(t *testing.T) {
+	h := NewUsageHandler(channel1, handlerCfg, nil)
+	require.NotNil(t, h)
+
+	require.Equal(t, "/cas/usage", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+}
+
+func TestUsage_Handler(t *testing.T) {
+	const token1 = "token1"
+
+	quotas := map[string]dcasquota.Quota{
+		"w1": {MaxBytes: 10},
+	}
+
+	getUsage := func(h *Usage, tokenName string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/cas/usage", nil)
+		req.Header.Set("Authorization", "Bearer "+token1)
+
+		authhandler.NewWithTokenNames(channel1, []string{tokenName}, []string{token1}, h).Handler()(rw, req)
+
+		return rw
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		m := dcasquota.New(channel1, cc1, coll1, "usage", quotas, olProvider)
+		require.NoError(t, m.Record("w1", 4))
+
+		rw := getUsage(NewUsageHandler(channel1, handlerCfg, m), "w1")
+		require.Equal(t, http.StatusOK, rw.Result().StatusCode)
+		require.Equal(t, httpserver.ContentTypeJSON, rw.Header().Get(httpserver.ContentTypeHeader))
+
+		usage := &dcasquota.Usage{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), usage))
+		require.Equal(t, "w1", usage.Token)
+		require.Equal(t, int64(10), usage.Quota.MaxBytes)
+		require.Equal(t, int64(4), usage.Bytes)
+		require.Equal(t, int64(1), usage.Objects)
+	})
+
+	t.Run("No quota -> Not Found", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(obmocks.NewMockOffLedgerClient(), nil)
+
+		rw := getUsage(NewUsageHandler(channel1, handlerCfg, dcasquota.New(channel1, cc1, coll1, "usage", quotas, olProvider)), "w2")
+		require.Equal(t, http.StatusNotFound, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusNotFound, rw.Body.String())
+	})
+
+	t.Run("Usage error -> Server Error", func(t *testing.T) {
+		olProvider := &obmocks.OffLedgerClientProvider{}
+		olProvider.ForChannelReturns(nil, errors.New("injected off-ledger provider error"))
+
+		rw := getUsage(NewUsageHandler(channel1, handlerCfg, dcasquota.New(channel1, cc1, coll1, "usage", quotas, olProvider)), "w1")
+		require.Equal(t, http.StatusInternalServerError, rw.Result().StatusCode)
+		require.Equal(t, httpserver.StatusServerError, rw.Body.String())
+	})
+}
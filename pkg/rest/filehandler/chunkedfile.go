@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/dcascid"
+)
+
+// putChunked splits the given content into chunks of the given size, stores each chunk in DCAS and then
+// stores the manifest. The CID of the manifest is returned. Each chunk is stored as a single raw block (rather
+// than as a file, which may be split into a DAG of blocks) so that the CID of the chunk is the hash of its content
+// and the chunk may therefore be verified when it's retrieved.
+func putChunked(client dcasclient.DCAS, contentType string, content []byte, chunkSize int) (string, error) {
+	manifest := &FileManifest{
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		ChunkSize:   int64(chunkSize),
+		Digest:      digest(content),
+	}
+
+	for offset := 0; offset < len(content); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+
+		cID, err := client.Put(bytes.NewReader(content[offset:end]))
+		if err != nil {
+			return "", errors.WithMessagef(err, "error storing chunk %d", len(manifest.Chunks))
+		}
+
+		manifest.Chunks = append(manifest.Chunks, cID)
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return "", errors.WithMessage(err, "error marshalling file manifest")
+	}
+
+	cID, err := client.Put(bytes.NewReader(manifestBytes), dcasclient.WithNodeType(dcasclient.FileNodeType))
+	if err != nil {
+		return "", errors.WithMessage(err, "error storing file manifest")
+	}
+
+	return cID, nil
+}
+
+func validateManifest(manifest *FileManifest) error {
+	if manifest.Size <= 0 {
+		return errors.New("invalid size in file manifest")
+	}
+
+	if manifest.ChunkSize <= 0 {
+		return errors.New("invalid chunk size in file manifest")
+	}
+
+	if int64(len(manifest.Chunks)) != (manifest.Size+manifest.ChunkSize-1)/manifest.ChunkSize {
+		return errors.New("number of chunks in file manifest does not match the size")
+	}
+
+	if manifest.Digest == "" {
+		return errors.New("digest is missing from file manifest")
+	}
+
+	return nil
+}
+
+// errDigestMismatch indicates that the reassembled content of a chunked file doesn't match the digest in its manifest
+var errDigestMismatch = errors.New("digest of file content does not match the digest in the file manifest")
+
+// chunkReader reads the content of a chunked file. Each chunk is retrieved from DCAS only when it's needed, so
+// the file is streamed rather than reassembled in memory, and Range requests only retrieve the required chunks.
+// Each chunk is verified against its CID before any of its bytes are returned so, since the manifest lists the
+// CIDs of the chunks, corrupt content is never served. When the file is read in order from the start, the content
+// is also hashed as it's read and the final bytes are only returned if the SHA-256 digest of the entire content
+// matches the digest in the manifest.
+type chunkReader struct {
+	manifest *FileManifest
+	client   dcasclient.DCAS
+
+	offset     int64
+	chunkIndex int
+	chunk      []byte
+
+	// hash contains the digest of the first 'hashed' bytes of the content
+	hash   hash.Hash
+	hashed int64
+}
+
+func newChunkReader(client dcasclient.DCAS, manifest *FileManifest) *chunkReader {
+	return &chunkReader{
+		manifest:   manifest,
+		client:     client,
+		chunkIndex: -1,
+		hash:       sha256.New(),
+	}
+}
+
+// Read reads up to len(p) bytes from the current chunk. Errors are logged here since they are not
+// reported by the HTTP server when streaming the content.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	n, err := r.read(p)
+	if err != nil && err != io.EOF {
+		logger.Errorf("Error reading chunked file: %s", err)
+	}
+
+	return n, err
+}
+
+func (r *chunkReader) read(p []byte) (int, error) {
+	if r.offset >= r.manifest.Size {
+		return 0, io.EOF
+	}
+
+	index := int(r.offset / r.manifest.ChunkSize)
+
+	if err := r.loadChunk(index); err != nil {
+		return 0, err
+	}
+
+	n := copy(p, r.chunk[r.offset-int64(index)*r.manifest.ChunkSize:])
+
+	if err := r.updateDigest(p[:n]); err != nil {
+		return 0, err
+	}
+
+	r.offset += int64(n)
+
+	return n, nil
+}
+
+// updateDigest adds the given bytes (which were read at the current offset) to the digest of the content if they
+// follow the bytes that have already been hashed. Once the entire content has been hashed, the digest is compared
+// with the digest in the manifest. (If the content isn't read in order, e.g. for a Range request, then the digest
+// is never completed and the content is only verified by the CIDs of the chunks.)
+func (r *chunkReader) updateDigest(b []byte) error {
+	if r.offset != r.hashed {
+		return nil
+	}
+
+	// Write never returns an error for a hash
+	_, _ = r.hash.Write(b)
+
+	r.hashed += int64(len(b))
+
+	if r.hashed < r.manifest.Size {
+		return nil
+	}
+
+	if hex.EncodeToString(r.hash.Sum(nil)) != r.manifest.Digest {
+		return errDigestMismatch
+	}
+
+	return nil
+}
+
+// Seek sets the offset for the next Read
+func (r *chunkReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = r.manifest.Size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	r.offset = abs
+
+	return abs, nil
+}
+
+func (r *chunkReader) loadChunk(index int) error {
+	if index == r.chunkIndex {
+		return nil
+	}
+
+	cID := r.manifest.Chunks[index]
+
+	content := bytes.NewBuffer(nil)
+	if err := r.client.Get(cID, content); err != nil {
+		return errors.WithMessagef(err, "error retrieving chunk %d [%s]", index, cID)
+	}
+
+	if int64(content.Len()) != r.chunkLen(index) {
+		return errors.Errorf("chunk %d [%s] has size %d but expecting %d", index, cID, content.Len(), r.chunkLen(index))
+	}
+
+	if err := dcascid.Verify(cID, content.Bytes()); err != nil {
+		return errors.WithMessagef(err, "error verifying chunk %d", index)
+	}
+
+	r.chunkIndex = index
+	r.chunk = content.Bytes()
+
+	return nil
+}
+
+func (r *chunkReader) chunkLen(index int) int64 {
+	if index == len(r.manifest.Chunks)-1 {
+		return r.manifest.Size - int64(index)*r.manifest.ChunkSize
+	}
+
+	return r.manifest.ChunkSize
+}
+
+func digest(content []byte) string {
+	d := sha256.Sum256(content)
+	return hex.EncodeToString(d[:])
+}
@@ -0,0 +1,296 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/common/dcascid"
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+const chunkedContent = `{"field1":"value1","field2":"value2","field3":"value3"}`
+
+func TestPutChunked(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		dcasClient := mocks.NewDCASClient()
+
+		cID, err := putChunked(dcasClient, "application/json", []byte(chunkedContent), 10)
+		require.NoError(t, err)
+
+		manifest := getManifest(t, dcasClient, cID)
+		require.Equal(t, "application/json", manifest.ContentType)
+		require.Equal(t, int64(len(chunkedContent)), manifest.Size)
+		require.Equal(t, int64(10), manifest.ChunkSize)
+		require.Equal(t, digest([]byte(chunkedContent)), manifest.Digest)
+		require.Len(t, manifest.Chunks, 6)
+		require.NoError(t, validateManifest(manifest))
+
+		content, err := ioutil.ReadAll(newChunkReader(dcasClient, manifest))
+		require.NoError(t, err)
+		require.Equal(t, chunkedContent, string(content))
+	})
+
+	t.Run("DCAS error", func(t *testing.T) {
+		errExpected := errors.New("injected DCAS error")
+		dcasClient := mocks.NewDCASClient().WithPutError(errExpected)
+
+		_, err := putChunked(dcasClient, "application/json", []byte(chunkedContent), 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestValidateManifest(t *testing.T) {
+	valid := func() *FileManifest {
+		return &FileManifest{
+			ContentType: "application/json",
+			Size:        25,
+			ChunkSize:   10,
+			Digest:      "1234",
+			Chunks:      []string{"1", "2", "3"},
+		}
+	}
+
+	require.NoError(t, validateManifest(valid()))
+
+	m := valid()
+	m.Size = 0
+	require.EqualError(t, validateManifest(m), "invalid size in file manifest")
+
+	m = valid()
+	m.ChunkSize = 0
+	require.EqualError(t, validateManifest(m), "invalid chunk size in file manifest")
+
+	m = valid()
+	m.Chunks = m.Chunks[0:2]
+	require.EqualError(t, validateManifest(m), "number of chunks in file manifest does not match the size")
+
+	m = valid()
+	m.Digest = ""
+	require.EqualError(t, validateManifest(m), "digest is missing from file manifest")
+}
+
+func TestChunkReader(t *testing.T) {
+	dcasClient := mocks.NewDCASClient()
+
+	cID, err := putChunked(dcasClient, "application/json", []byte(chunkedContent), 10)
+	require.NoError(t, err)
+
+	manifest := getManifest(t, dcasClient, cID)
+
+	t.Run("Seek", func(t *testing.T) {
+		r := newChunkReader(dcasClient, manifest)
+
+		pos, err := r.Seek(-5, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, manifest.Size-5, pos)
+
+		content, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, chunkedContent[len(chunkedContent)-5:], string(content))
+
+		pos, err = r.Seek(15, io.SeekStart)
+		require.NoError(t, err)
+		require.Equal(t, int64(15), pos)
+
+		pos, err = r.Seek(-3, io.SeekCurrent)
+		require.NoError(t, err)
+		require.Equal(t, int64(12), pos)
+
+		b := make([]byte, 4)
+		n, err := io.ReadFull(r, b)
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+		require.Equal(t, chunkedContent[12:16], string(b))
+
+		_, err = r.Seek(-1, io.SeekStart)
+		require.EqualError(t, err, "negative position")
+
+		_, err = r.Seek(0, 10)
+		require.EqualError(t, err, "invalid whence")
+	})
+
+	t.Run("Corrupt chunk", func(t *testing.T) {
+		corruptClient := mocks.NewDCASClient()
+		for _, cID := range manifest.Chunks {
+			content := bytes.NewBuffer(nil)
+			require.NoError(t, dcasClient.Get(cID, content))
+			corruptClient.WithData(cID, content.Bytes())
+		}
+
+		corruptClient.WithData(manifest.Chunks[2], []byte("0123456789"))
+
+		content, err := ioutil.ReadAll(newChunkReader(corruptClient, manifest))
+		require.Error(t, err)
+		require.Equal(t, dcascid.ErrMismatch, errors.Cause(err))
+		require.Equal(t, chunkedContent[0:20], string(content))
+	})
+
+	t.Run("Digest mismatch", func(t *testing.T) {
+		m := *manifest
+		m.Digest = digest([]byte("other content"))
+
+		// The chunks are valid so all but the final bytes are returned
+		content, err := ioutil.ReadAll(newChunkReader(dcasClient, &m))
+		require.Equal(t, errDigestMismatch, err)
+		require.Equal(t, chunkedContent[0:50], string(content))
+
+		// The digest isn't verified if the content isn't read from the start
+		r := newChunkReader(dcasClient, &m)
+		_, err = r.Seek(5, io.SeekStart)
+		require.NoError(t, err)
+
+		content, err = ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, chunkedContent[5:], string(content))
+	})
+
+	t.Run("Missing chunk", func(t *testing.T) {
+		m := *manifest
+		m.Chunks = append([]string{}, manifest.Chunks...)
+		m.Chunks[2] = "xxx"
+
+		_, err := ioutil.ReadAll(newChunkReader(dcasClient, &m))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "chunk 2 [xxx] has size 0 but expecting 10")
+	})
+
+	t.Run("DCAS error", func(t *testing.T) {
+		errExpected := errors.New("injected DCAS error")
+
+		_, err := ioutil.ReadAll(newChunkReader(mocks.NewDCASClient().WithGetError(errExpected), manifest))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestFileRetrieveHandler_Chunked(t *testing.T) {
+	restore := getResourceName
+	getResourceName = func(req *http.Request) string { return schema1 }
+	defer func() { getResourceName = restore }()
+
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	cfg := Config{
+		BasePath:       "/schema",
+		ChaincodeName:  "file_cc",
+		Collection:     "schemas",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+		ChunkSize:      10,
+	}
+
+	uploadHandler := NewUploadHandler(channelID, cfg, dcasProvider)
+
+	fileBytes, err := json.Marshal(&File{ContentType: "application/json", Content: []byte(chunkedContent)})
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	uploadHandler.Handler()(rw, httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(fileBytes)))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var cID string
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &cID))
+
+	manifest := getManifest(t, dcasClient, cID)
+	require.Len(t, manifest.Chunks, 6)
+
+	badManifestBytes, err := json.Marshal(&FileManifest{ContentType: "application/json", Size: 100, ChunkSize: 10, Digest: "1234", Chunks: manifest.Chunks})
+	require.NoError(t, err)
+
+	dcasClient.WithData("bad_manifest", badManifestBytes)
+
+	docResolver := &mocks.DocumentResolver{}
+	h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+
+	setMapping := func(id string) {
+		doc, err := getDocument(&FileIndexDoc{
+			ID: "file:idx:1234",
+			FileIndex: FileIndex{
+				BasePath: "/schema",
+				Mappings: map[string]string{schema1: id},
+			},
+		})
+		require.NoError(t, err)
+
+		docResolver.ResolveDocumentReturns(&document.ResolutionResult{Document: doc}, nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		setMapping(cID)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+		require.Equal(t, chunkedContent, rw.Body.String())
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		setMapping(cID)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil)
+		req.Header.Set("Range", "bytes=8-22")
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusPartialContent, rw.Code)
+		require.Equal(t, chunkedContent[8:23], rw.Body.String())
+	})
+
+	t.Run("Invalid manifest", func(t *testing.T) {
+		setMapping("bad_manifest")
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func TestUpload_ChunkingDisabled(t *testing.T) {
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	uploadHandler := NewUploadHandler(channelID, Config{BasePath: "/schema", ChaincodeName: "file_cc", Collection: "schemas"}, dcasProvider)
+
+	fileBytes, err := json.Marshal(&File{ContentType: "application/json", Content: []byte(chunkedContent)})
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	uploadHandler.Handler()(rw, httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(fileBytes)))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var cID string
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &cID))
+
+	manifest := getManifest(t, dcasClient, cID)
+	require.Empty(t, manifest.Chunks)
+}
+
+func getManifest(t *testing.T, dcasClient *mocks.MockDCASClient, cID string) *FileManifest {
+	manifestBytes := bytes.NewBuffer(nil)
+	require.NoError(t, dcasClient.Get(cID, manifestBytes))
+
+	manifest := &FileManifest{}
+	require.NoError(t, json.Unmarshal(manifestBytes.Bytes(), manifest))
+
+	return manifest
+}
@@ -24,4 +24,35 @@ type Config struct {
 	IndexNamespace string
 	// IndexDocID is ID of the Sidetree document that contains the index of all files stored in this path
 	IndexDocID string
+	// ChunkSize is the maximum size of a file (in bytes) that is stored as a single DCAS entry. Larger files are
+	// split into chunks of this size which are stored individually in DCAS along with a manifest. If 0 (the default)
+	// then files are never split into chunks. Note that each chunk is stored as a single raw block, so the chunk size
+	// must be within the size limits of DCAS.
+	ChunkSize int
+	// MaxUploadSize is the maximum size (in bytes) of the content of an uploaded file. If 0 then the size is not limited.
+	MaxUploadSize int64
+	// ListingEnabled indicates whether or not the names of the files served by this base path may be listed
+	// (with a GET on the base path). The listing requires one of the read tokens, so read tokens must be configured.
+	ListingEnabled bool
+	// Website contains the options for serving a static website from the base path
+	Website WebsiteConfig
+}
+
+// WebsiteConfig contains the options for serving a static website from a file handler base path. All options are
+// optional. If none are set then every file that isn't in the file index results in a 404.
+type WebsiteConfig struct {
+	// IndexDocument is the name of the file (e.g. index.html) that's served for a directory request, i.e. a request
+	// for the base path itself, a path ending in '/', or a path that isn't in the index but whose index document is.
+	IndexDocument string
+	// NotFoundDocument is the name of the file (e.g. 404.html) that's served with a 404 status when the requested
+	// file isn't in the file index
+	NotFoundDocument string
+	// FallbackDocument is the name of the file (usually the same as IndexDocument) that's served with a 200 status
+	// when a requested path without a file extension isn't in the file index. This allows a single-page application
+	// to handle its own client-side routes.
+	FallbackDocument string
+	// ContentTypes maps a lower-case file extension (without the leading '.', e.g. "html") to the content type that's
+	// served for files that were uploaded without a content type. If a file extension isn't in this map then the
+	// standard MIME type for the extension is used. Uploads may only omit the content type if this map is not empty.
+	ContentTypes map[string]string
 }
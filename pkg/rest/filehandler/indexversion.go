@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/processor"
+)
+
+const (
+	transactionTimeParam = "transactionTime"
+	operationNumberParam = "operationNumber"
+)
+
+// IndexVersion selects a historical version of the file index document. Exactly one of the fields is set.
+type IndexVersion struct {
+	// TransactionTime is the transaction time (block number). Only the operations that were anchored
+	// at or before this transaction time are applied.
+	TransactionTime uint64
+	// OperationNumber is the (one-based) number of an operation, where operation 1 is the create operation.
+	// Only the operations up to and including this operation are applied.
+	OperationNumber uint64
+}
+
+// String returns a readable string for the version
+func (v *IndexVersion) String() string {
+	if v.OperationNumber > 0 {
+		return fmt.Sprintf("operation number %d", v.OperationNumber)
+	}
+
+	return fmt.Sprintf("transaction time %d", v.TransactionTime)
+}
+
+type indexVersionResolver interface {
+	ResolveDocumentAtVersion(id string, version *IndexVersion) (*document.ResolutionResult, error)
+}
+
+// IndexVersionResolver resolves a file index document as of a given version by replaying only
+// the operations up to that version
+type IndexVersionResolver struct {
+	namespace string
+	store     processor.OperationStoreClient
+	pc        protocol.Client
+}
+
+// NewIndexVersionResolver returns a new index version resolver
+func NewIndexVersionResolver(namespace string, store processor.OperationStoreClient, pc protocol.Client) *IndexVersionResolver {
+	return &IndexVersionResolver{
+		namespace: namespace,
+		store:     store,
+		pc:        pc,
+	}
+}
+
+// ResolveDocumentAtVersion resolves the document with the given ID as of the given version
+func (r *IndexVersionResolver) ResolveDocumentAtVersion(id string, version *IndexVersion) (*document.ResolutionResult, error) {
+	return r.resolve(r.store, id, version)
+}
+
+// ResolveDocumentVersions resolves every version of the document with the given ID, i.e. the document as of each of
+// its operations. A version that can't be resolved (for example, if the operations up to that version are invalid)
+// is skipped. Nil is returned if the document doesn't exist. Note that the operations are replayed for each version.
+func (r *IndexVersionResolver) ResolveDocumentVersions(id string) ([]*document.ResolutionResult, error) {
+	ops, err := r.store.Get(strings.TrimPrefix(id, r.namespace+docutil.NamespaceDelimiter))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// The operations are only read once
+	store := &staticOperationStore{ops: ops}
+
+	var results []*document.ResolutionResult
+
+	for i := range ops {
+		version := &IndexVersion{OperationNumber: uint64(i + 1)}
+
+		result, err := r.resolve(store, id, version)
+		if err != nil {
+			logger.Debugf("Unable to resolve document [%s] at %s: %s", id, version, err)
+
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (r *IndexVersionResolver) resolve(opStore processor.OperationStoreClient, id string, version *IndexVersion) (*document.ResolutionResult, error) {
+	store := &versionedOperationStore{
+		OperationStoreClient: opStore,
+		version:              version,
+	}
+
+	h := dochandler.New(r.namespace, nil, r.pc, nil, processor.New(r.namespace+"@"+version.String(), store, r.pc))
+
+	return h.ResolveDocument(id)
+}
+
+// staticOperationStore returns a copy of the given operations
+type staticOperationStore struct {
+	ops []*operation.AnchoredOperation
+}
+
+// Get returns the operations. A copy is returned since the caller may sort the operations.
+func (s *staticOperationStore) Get(string) ([]*operation.AnchoredOperation, error) {
+	return append([]*operation.AnchoredOperation(nil), s.ops...), nil
+}
+
+// versionedOperationStore returns only the operations up to the given version
+type versionedOperationStore struct {
+	processor.OperationStoreClient
+	version *IndexVersion
+}
+
+// Get returns the operations for the given unique suffix up to the version
+func (s *versionedOperationStore) Get(uniqueSuffix string) ([]*operation.AnchoredOperation, error) {
+	ops, err := s.OperationStoreClient.Get(uniqueSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].TransactionTime == ops[j].TransactionTime {
+			return ops[i].TransactionNumber < ops[j].TransactionNumber
+		}
+
+		return ops[i].TransactionTime < ops[j].TransactionTime
+	})
+
+	var filtered []*operation.AnchoredOperation
+
+	for i, op := range ops {
+		if s.version.OperationNumber > 0 && uint64(i) >= s.version.OperationNumber {
+			break
+		}
+
+		if s.version.OperationNumber == 0 && op.TransactionTime > s.version.TransactionTime {
+			break
+		}
+
+		filtered = append(filtered, op)
+	}
+
+	if len(filtered) == 0 {
+		return nil, errors.Errorf("uniqueSuffix not found in the store at %s", s.version)
+	}
+
+	return filtered, nil
+}
+
+// getIndexVersion returns the index version from the given query parameters or nil if no version was requested
+func getIndexVersion(params url.Values) (*IndexVersion, error) {
+	txnTime := params.Get(transactionTimeParam)
+	opNumber := params.Get(operationNumberParam)
+
+	if txnTime != "" && opNumber != "" {
+		return nil, errors.Errorf("only one of '%s' and '%s' may be specified", transactionTimeParam, operationNumberParam)
+	}
+
+	if txnTime != "" {
+		value, err := strconv.ParseUint(txnTime, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid value for '%s': [%s]", transactionTimeParam, txnTime)
+		}
+
+		return &IndexVersion{TransactionTime: value}, nil
+	}
+
+	if opNumber != "" {
+		value, err := strconv.ParseUint(opNumber, 10, 64)
+		if err != nil || value == 0 {
+			return nil, errors.Errorf("invalid value for '%s': [%s]", operationNumberParam, opNumber)
+		}
+
+		return &IndexVersion{OperationNumber: value}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,307 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestGetIndexVersion(t *testing.T) {
+	t.Run("No version", func(t *testing.T) {
+		v, err := getIndexVersion(url.Values{})
+		require.NoError(t, err)
+		require.Nil(t, v)
+	})
+
+	t.Run("Transaction time", func(t *testing.T) {
+		v, err := getIndexVersion(url.Values{transactionTimeParam: []string{"25"}})
+		require.NoError(t, err)
+		require.Equal(t, &IndexVersion{TransactionTime: 25}, v)
+		require.Equal(t, "transaction time 25", v.String())
+
+		_, err = getIndexVersion(url.Values{transactionTimeParam: []string{"xxx"}})
+		require.EqualError(t, err, "invalid value for 'transactionTime': [xxx]")
+	})
+
+	t.Run("Operation number", func(t *testing.T) {
+		v, err := getIndexVersion(url.Values{operationNumberParam: []string{"2"}})
+		require.NoError(t, err)
+		require.Equal(t, &IndexVersion{OperationNumber: 2}, v)
+		require.Equal(t, "operation number 2", v.String())
+
+		_, err = getIndexVersion(url.Values{operationNumberParam: []string{"0"}})
+		require.EqualError(t, err, "invalid value for 'operationNumber': [0]")
+	})
+
+	t.Run("Both -> error", func(t *testing.T) {
+		_, err := getIndexVersion(url.Values{transactionTimeParam: []string{"25"}, operationNumberParam: []string{"2"}})
+		require.EqualError(t, err, "only one of 'transactionTime' and 'operationNumber' may be specified")
+	})
+}
+
+func TestVersionedOperationStore(t *testing.T) {
+	ops := []*operation.AnchoredOperation{
+		{Type: operation.TypeUpdate, TransactionTime: 20, TransactionNumber: 1},
+		{Type: operation.TypeCreate, TransactionTime: 10, TransactionNumber: 3},
+		{Type: operation.TypeUpdate, TransactionTime: 20, TransactionNumber: 0},
+		{Type: operation.TypeUpdate, TransactionTime: 30, TransactionNumber: 0},
+	}
+
+	opStore := &mocks.OperationStore{}
+	opStore.GetReturns(ops, nil)
+
+	t.Run("Transaction time", func(t *testing.T) {
+		s := &versionedOperationStore{OperationStoreClient: opStore, version: &IndexVersion{TransactionTime: 20}}
+
+		filtered, err := s.Get("1234")
+		require.NoError(t, err)
+		require.Len(t, filtered, 3)
+		require.Equal(t, operation.TypeCreate, filtered[0].Type)
+		require.Equal(t, uint64(0), filtered[1].TransactionNumber)
+		require.Equal(t, uint64(1), filtered[2].TransactionNumber)
+
+		s = &versionedOperationStore{OperationStoreClient: opStore, version: &IndexVersion{TransactionTime: 9}}
+
+		_, err = s.Get("1234")
+		require.EqualError(t, err, "uniqueSuffix not found in the store at transaction time 9")
+	})
+
+	t.Run("Operation number", func(t *testing.T) {
+		s := &versionedOperationStore{OperationStoreClient: opStore, version: &IndexVersion{OperationNumber: 2}}
+
+		filtered, err := s.Get("1234")
+		require.NoError(t, err)
+		require.Len(t, filtered, 2)
+		require.Equal(t, operation.TypeCreate, filtered[0].Type)
+		require.Equal(t, uint64(20), filtered[1].TransactionTime)
+		require.Equal(t, uint64(0), filtered[1].TransactionNumber)
+
+		s = &versionedOperationStore{OperationStoreClient: opStore, version: &IndexVersion{OperationNumber: 10}}
+
+		filtered, err = s.Get("1234")
+		require.NoError(t, err)
+		require.Len(t, filtered, 4)
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+		opStore := &mocks.OperationStore{}
+		opStore.GetReturns(nil, errExpected)
+
+		s := &versionedOperationStore{OperationStoreClient: opStore, version: &IndexVersion{OperationNumber: 2}}
+
+		_, err := s.Get("1234")
+		require.Equal(t, errExpected, err)
+	})
+}
+
+func TestIndexVersionResolver(t *testing.T) {
+	const id = "file:idx:1234"
+
+	pv := coremocks.GetProtocolVersion(coremocks.GetDefaultProtocolParameters())
+
+	parser := &coremocks.OperationParser{}
+	parser.ParseDIDReturns(id, nil, nil)
+	pv.OperationParserReturns(parser)
+
+	applier := &coremocks.OperationApplier{}
+	applier.ApplyStub = func(op *operation.AnchoredOperation, rm *protocol.ResolutionModel) (*protocol.ResolutionModel, error) {
+		doc, err := document.FromBytes(op.OperationBuffer)
+		if err != nil {
+			return nil, err
+		}
+
+		return &protocol.ResolutionModel{Doc: doc}, nil
+	}
+	pv.OperationApplierReturns(applier)
+
+	transformer := &coremocks.DocumentTransformer{}
+	transformer.TransformDocumentStub = func(rm *protocol.ResolutionModel, _ protocol.TransformationInfo) (*document.ResolutionResult, error) {
+		return &document.ResolutionResult{Document: rm.Doc}, nil
+	}
+	pv.DocumentTransformerReturns(transformer)
+
+	pc := coremocks.NewMockProtocolClient()
+	pc.CurrentVersion = pv
+	pc.Versions = []*coremocks.ProtocolVersion{pv}
+
+	opStore := &mocks.OperationStore{}
+	opStore.GetReturns([]*operation.AnchoredOperation{
+		{Type: operation.TypeCreate, UniqueSuffix: "1234", TransactionTime: 10, OperationBuffer: []byte(`{"id":"file:idx:1234"}`)},
+	}, nil)
+
+	r := NewIndexVersionResolver("file:idx", opStore, pc)
+
+	result, err := r.ResolveDocumentAtVersion(id, &IndexVersion{TransactionTime: 10})
+	require.NoError(t, err)
+	require.Equal(t, id, result.Document.ID())
+
+	_, err = r.ResolveDocumentAtVersion(id, &IndexVersion{TransactionTime: 5})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	t.Run("All versions", func(t *testing.T) {
+		opStore := &mocks.OperationStore{}
+		opStore.GetReturns([]*operation.AnchoredOperation{
+			{Type: operation.TypeUpdate, UniqueSuffix: "1234", TransactionTime: 20, OperationBuffer: []byte(`{"id":"file:idx:1234","version":2}`)},
+			{Type: operation.TypeCreate, UniqueSuffix: "1234", TransactionTime: 10, OperationBuffer: []byte(`{"id":"file:idx:1234","version":1}`)},
+		}, nil)
+
+		r := NewIndexVersionResolver("file:idx", opStore, pc)
+
+		results, err := r.ResolveDocumentVersions(id)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, float64(1), results[0].Document["version"])
+		// The mock update operation has no valid commitment, so it isn't applied by the processor
+		require.Equal(t, float64(1), results[1].Document["version"])
+		require.Equal(t, 1, opStore.GetCallCount())
+		require.Equal(t, "1234", opStore.GetArgsForCall(0))
+	})
+
+	t.Run("All versions - not found", func(t *testing.T) {
+		opStore := &mocks.OperationStore{}
+		opStore.GetReturns(nil, errors.New("uniqueSuffix not found in the store"))
+
+		results, err := NewIndexVersionResolver("file:idx", opStore, pc).ResolveDocumentVersions(id)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("All versions - store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		opStore := &mocks.OperationStore{}
+		opStore.GetReturns(nil, errExpected)
+
+		_, err := NewIndexVersionResolver("file:idx", opStore, pc).ResolveDocumentVersions(id)
+		require.Equal(t, errExpected, err)
+	})
+}
+
+func TestFileRetrieveHandler_Version(t *testing.T) {
+	restore := getResourceName
+	getResourceName = func(req *http.Request) string { return schema1 }
+	defer func() { getResourceName = restore }()
+
+	dcasClient := mocks.NewDCASClient()
+	dcasClient.WithData("cid1", getFileBytes(t, `{"version":1}`))
+	dcasClient.WithData("cid2", getFileBytes(t, `{"version":2}`))
+
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	getIndexDoc := func(cID string) *document.ResolutionResult {
+		doc, err := getDocument(&FileIndexDoc{
+			ID: "file:idx:1234",
+			FileIndex: FileIndex{
+				BasePath: "/schema",
+				Mappings: map[string]string{schema1: cID},
+			},
+		})
+		require.NoError(t, err)
+
+		return &document.ResolutionResult{Document: doc}
+	}
+
+	docResolver := &mocks.DocumentResolver{}
+	docResolver.ResolveDocumentReturns(getIndexDoc("cid2"), nil)
+
+	versionResolver := &mockVersionResolver{
+		results: map[uint64]*document.ResolutionResult{
+			1: getIndexDoc("cid1"),
+			2: getIndexDoc("cid2"),
+		},
+	}
+
+	cfg := Config{
+		BasePath:       "/schema",
+		ChaincodeName:  "file_cc",
+		Collection:     "schemas",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+	}
+
+	h := NewRetrieveHandler(channelID, cfg, docResolver, versionResolver, dcasProvider)
+
+	t.Run("Latest version", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `{"version":2}`, rw.Body.String())
+	})
+
+	t.Run("Historical version", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json?operationNumber=1", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `{"version":1}`, rw.Body.String())
+	})
+
+	t.Run("Version not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json?operationNumber=3", nil))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json?transactionTime=xxx", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("Version not supported", func(t *testing.T) {
+		h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema/schema1.json?operationNumber=1", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "not supported")
+	})
+
+	t.Run("List historical version", func(t *testing.T) {
+		h := NewListHandler(channelID, cfg, docResolver, versionResolver, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema?operationNumber=1", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		listing := &FileListing{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), listing))
+		require.Len(t, listing.Files, 1)
+		require.Equal(t, "cid1", listing.Files[0].CID)
+	})
+}
+
+// mockVersionResolver resolves the file index document by operation number. (A counterfeiter
+// mock can't be used since the mocks package imports this package.)
+type mockVersionResolver struct {
+	results map[uint64]*document.ResolutionResult
+}
+
+func (m *mockVersionResolver) ResolveDocumentAtVersion(_ string, version *IndexVersion) (*document.ResolutionResult, error) {
+	result, ok := m.results[version.OperationNumber]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	dcas "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const (
+	prefixParam = "prefix"
+	afterParam  = "after"
+	limitParam  = "limit"
+
+	defaultListLimit = 100
+	maxListLimit     = 1000
+
+	// fileHeaderSize is the number of bytes of a stored file that are read in order to determine its content type
+	// and size. (More may be read since the content is retrieved from DCAS one block at a time.)
+	fileHeaderSize = 16 * 1024
+)
+
+// errHeaderRead is returned by the header writer in order to abort the retrieval of a file
+var errHeaderRead = errors.New("file header read")
+
+// List is a REST handler that lists the files served by a base path
+type List struct {
+	*Retrieve
+}
+
+// NewListHandler returns a new file list handler
+func NewListHandler(channelID string, cfg Config, resolver documentResolver, versionResolver indexVersionResolver, dcasProvider dcasClientProvider) *List {
+	return &List{
+		Retrieve: NewRetrieveHandler(channelID, cfg, resolver, versionResolver, dcasProvider),
+	}
+}
+
+// Path returns the context path
+func (h *List) Path() string {
+	return h.BasePath
+}
+
+// Method returns the HTTP method
+func (h *List) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the handler
+func (h *List) Handler() common.HTTPRequestHandler {
+	return h.list
+}
+
+// list returns the names of the files in the file index document along with the CID, content type and size of
+// each file. (The size is omitted for large files that aren't chunked.) The files are sorted by name. The following (optional) query parameters are supported:
+// - prefix: only files whose names start with the prefix are returned
+// - after: only files whose names are greater than the given name are returned (used for pagination)
+// - limit: the maximum number of files to return
+// - transactionTime or operationNumber: the files are listed from the given version of the file index document
+func (h *List) list(rw http.ResponseWriter, req *http.Request) {
+	params := getQueryParams(req)
+
+	prefix := params.Get(prefixParam)
+	after := params.Get(afterParam)
+
+	limit, err := getLimit(params.Get(limitParam))
+	if err != nil {
+		common.WriteError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	version, err := getIndexVersion(params)
+	if err != nil {
+		common.WriteError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	logger.Debugf("[%s:%s:%s] Listing files - prefix [%s], after [%s], limit [%d]", h.channelID, h.ChaincodeName, h.Collection, prefix, after, limit)
+
+	listing, err := h.doList(prefix, after, limit, version)
+	if err != nil {
+		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		return
+	}
+
+	common.WriteResponse(rw, http.StatusOK, listing)
+}
+
+func (h *List) doList(prefix, after string, limit int, version *IndexVersion) (*FileListing, error) {
+	fileIndex, err := h.retrieveIndexDoc(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range fileIndex.Mappings {
+		if strings.HasPrefix(name, prefix) && name > after {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	listing := &FileListing{Files: []FileInfo{}}
+
+	if len(names) > limit {
+		names = names[0:limit]
+		listing.Next = names[limit-1]
+	}
+
+	if len(names) == 0 {
+		return listing, nil
+	}
+
+	dcasClient, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	for _, name := range names {
+		info, err := h.getFileInfo(dcasClient, name, fileIndex.Mappings[name])
+		if err != nil {
+			return nil, err
+		}
+
+		listing.Files = append(listing.Files, *info)
+	}
+
+	return listing, nil
+}
+
+// getFileInfo returns the content type and size of the given file. Only the start of the file is retrieved from
+// DCAS, so the size of a chunked file is read from its manifest but the size of a large file that isn't chunked
+// is unknown (and is therefore omitted).
+func (h *List) getFileInfo(dcasClient dcas.DCAS, name, cID string) (*FileInfo, error) {
+	info := &FileInfo{
+		Name: name,
+		CID:  cID,
+	}
+
+	w := &headerWriter{}
+
+	err := dcasClient.Get(cID, w)
+	if err != nil && errors.Cause(err) != errHeaderRead {
+		logger.Errorf("[%s:%s:%s] Error retrieving DCAS document for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	if w.Len() == 0 {
+		logger.Debugf("[%s:%s:%s] File [%s] not found in DCAS for CID [%s]", h.channelID, h.ChaincodeName, h.Collection, name, cID)
+		return info, nil
+	}
+
+	if err != nil {
+		logger.Debugf("[%s:%s:%s] File [%s] exceeds %d bytes - reading its content type and size from the header", h.channelID, h.ChaincodeName, h.Collection, name, fileHeaderSize)
+
+		info.ContentType, info.Size = parseFileHeader(w.Bytes())
+
+		return info, nil
+	}
+
+	f := &storedFile{}
+	if err := json.Unmarshal(w.Bytes(), f); err != nil {
+		logger.Errorf("[%s:%s:%s] Error unmarshalling data for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	info.ContentType = f.ContentType
+
+	if len(f.Chunks) > 0 {
+		info.Size = f.Size
+	} else {
+		info.Size = int64(len(f.Content))
+	}
+
+	return info, nil
+}
+
+// headerWriter keeps the start of a stored file. Once it has fileHeaderSize bytes, the retrieval is aborted.
+type headerWriter struct {
+	bytes.Buffer
+}
+
+func (w *headerWriter) Write(p []byte) (int, error) {
+	if w.Len() >= fileHeaderSize {
+		return 0, errHeaderRead
+	}
+
+	return w.Buffer.Write(p)
+}
+
+// parseFileHeader returns the content type and size from the start of a stored file. Since the fields of a File and
+// a FileManifest are marshalled in order, the content type (and the size in a manifest) precede the content (or chunks).
+// The size is only available from a manifest.
+func parseFileHeader(header []byte) (string, int64) {
+	var contentType string
+	var size int64
+
+	d := json.NewDecoder(bytes.NewReader(header))
+
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return "", 0
+	}
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return contentType, size
+		}
+
+		switch t {
+		case "contentType":
+			if err := d.Decode(&contentType); err != nil {
+				return "", 0
+			}
+		case "size":
+			if err := d.Decode(&size); err != nil {
+				return contentType, 0
+			}
+		default:
+			return contentType, size
+		}
+	}
+}
+
+func getLimit(value string) (int, error) {
+	if value == "" {
+		return defaultListLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, errors.Errorf("invalid limit [%s]", value)
+	}
+
+	if limit > maxListLimit {
+		return maxListLimit, nil
+	}
+
+	return limit, nil
+}
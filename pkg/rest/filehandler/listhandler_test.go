@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestList(t *testing.T) {
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	personCID, err := dcasClient.Put(bytes.NewReader(getFileBytes(t, `{"name":"person"}`)))
+	require.NoError(t, err)
+
+	orgCID, err := dcasClient.Put(bytes.NewReader(getFileBytes(t, `{"name":"organization"}`)))
+	require.NoError(t, err)
+
+	chunkedCID, err := putChunked(dcasClient, "application/json", []byte(chunkedContent), 10)
+	require.NoError(t, err)
+
+	doc, err := getDocument(&FileIndexDoc{
+		ID: "file:idx:1234",
+		FileIndex: FileIndex{
+			BasePath: "/schema",
+			Mappings: map[string]string{
+				"v1/person.json":       personCID,
+				"v1/organization.json": orgCID,
+				"v2/person.json":       chunkedCID,
+				"v2/missing.json":      "xxx",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	docResolver := &mocks.DocumentResolver{}
+	docResolver.ResolveDocumentReturns(&document.ResolutionResult{Document: doc}, nil)
+
+	cfg := Config{
+		BasePath:       "/schema",
+		ChaincodeName:  "file_cc",
+		Collection:     "schemas",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+		ListingEnabled: true,
+	}
+
+	h := NewListHandler(channelID, cfg, docResolver, nil, dcasProvider)
+	require.Equal(t, "/schema", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("All files", func(t *testing.T) {
+		listing := list(t, h, "/schema", http.StatusOK)
+		require.Empty(t, listing.Next)
+		require.Len(t, listing.Files, 4)
+
+		require.Equal(t, FileInfo{Name: "v1/organization.json", CID: orgCID, ContentType: "application/json", Size: 23}, listing.Files[0])
+		require.Equal(t, FileInfo{Name: "v1/person.json", CID: personCID, ContentType: "application/json", Size: 17}, listing.Files[1])
+		require.Equal(t, FileInfo{Name: "v2/missing.json", CID: "xxx"}, listing.Files[2])
+		require.Equal(t, FileInfo{Name: "v2/person.json", CID: chunkedCID, ContentType: "application/json", Size: int64(len(chunkedContent))}, listing.Files[3])
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		listing := list(t, h, "/schema?prefix=v2/", http.StatusOK)
+		require.Len(t, listing.Files, 2)
+		require.Equal(t, "v2/missing.json", listing.Files[0].Name)
+		require.Equal(t, "v2/person.json", listing.Files[1].Name)
+
+		listing = list(t, h, "/schema?prefix=v3/", http.StatusOK)
+		require.Empty(t, listing.Files)
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		listing := list(t, h, "/schema?limit=3", http.StatusOK)
+		require.Len(t, listing.Files, 3)
+		require.Equal(t, "v2/missing.json", listing.Next)
+
+		listing = list(t, h, "/schema?limit=3&after=v2/missing.json", http.StatusOK)
+		require.Len(t, listing.Files, 1)
+		require.Equal(t, "v2/person.json", listing.Files[0].Name)
+		require.Empty(t, listing.Next)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema?limit=0", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid limit [0]")
+	})
+
+	t.Run("File index not found", func(t *testing.T) {
+		docResolver.ResolveDocumentReturns(nil, errors.New("not found"))
+		defer docResolver.ResolveDocumentReturns(&document.ResolutionResult{Document: doc}, nil)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema", nil))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("DCAS provider error", func(t *testing.T) {
+		dcasProvider.GetDCASClientReturns(nil, errors.New("injected DCAS provider error"))
+		defer dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+
+	t.Run("DCAS error", func(t *testing.T) {
+		dcasProvider.GetDCASClientReturns(mocks.NewDCASClient().WithGetError(errors.New("injected DCAS error")), nil)
+		defer dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/schema", nil))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func TestList_LargeFile(t *testing.T) {
+	fileBytes := getFileBytes(t, strings.Repeat("x", 3*fileHeaderSize))
+
+	var written int
+
+	dcasClient := &mocks.DCASClient{}
+	dcasClient.GetStub = func(_ string, w io.Writer) error {
+		for offset := 0; offset < len(fileBytes); offset += 1024 {
+			end := offset + 1024
+			if end > len(fileBytes) {
+				end = len(fileBytes)
+			}
+
+			n, err := w.Write(fileBytes[offset:end])
+			written += n
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	h := NewListHandler(channelID, Config{}, nil, nil, nil)
+
+	info, err := h.getFileInfo(dcasClient, "large.json", "cid1")
+	require.NoError(t, err)
+	require.Equal(t, &FileInfo{Name: "large.json", CID: "cid1", ContentType: "application/json"}, info)
+	require.Equal(t, fileHeaderSize, written)
+}
+
+func TestParseFileHeader(t *testing.T) {
+	manifestBytes, err := json.Marshal(&FileManifest{ContentType: "text/html", Size: 1000, ChunkSize: 10, Digest: "1234", Chunks: []string{"1", "2"}})
+	require.NoError(t, err)
+
+	contentType, size := parseFileHeader(manifestBytes[0:60])
+	require.Equal(t, "text/html", contentType)
+	require.Equal(t, int64(1000), size)
+
+	contentType, size = parseFileHeader(getFileBytes(t, `{"name":"person"}`)[0:40])
+	require.Equal(t, "application/json", contentType)
+	require.Zero(t, size)
+
+	contentType, size = parseFileHeader([]byte(`{"contentType":"text/ht`))
+	require.Empty(t, contentType)
+	require.Zero(t, size)
+
+	contentType, size = parseFileHeader([]byte(`[]`))
+	require.Empty(t, contentType)
+	require.Zero(t, size)
+}
+
+func TestGetLimit(t *testing.T) {
+	limit, err := getLimit("")
+	require.NoError(t, err)
+	require.Equal(t, defaultListLimit, limit)
+
+	limit, err = getLimit("5")
+	require.NoError(t, err)
+	require.Equal(t, 5, limit)
+
+	limit, err = getLimit("5000")
+	require.NoError(t, err)
+	require.Equal(t, maxListLimit, limit)
+
+	_, err = getLimit("-1")
+	require.EqualError(t, err, "invalid limit [-1]")
+
+	_, err = getLimit("xxx")
+	require.EqualError(t, err, "invalid limit [xxx]")
+}
+
+func list(t *testing.T, h *List, target string, expectedStatus int) *FileListing {
+	rw := httptest.NewRecorder()
+	h.Handler()(rw, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, expectedStatus, rw.Code)
+
+	listing := &FileListing{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), listing))
+
+	return listing
+}
@@ -6,12 +6,61 @@ SPDX-License-Identifier: Apache-2.0
 
 package filehandler
 
+import "encoding/json"
+
 // File contains the file upload request
 type File struct {
 	ContentType string `json:"contentType"`
 	Content     []byte `json:"content"`
 }
 
+// FileManifest describes a file whose content is split into chunks that are stored individually in DCAS.
+// The manifest is stored in DCAS in place of the File and its CID is used in the file index mappings.
+type FileManifest struct {
+	ContentType string `json:"contentType"`
+	// Size is the total size of the file in bytes
+	Size int64 `json:"size"`
+	// ChunkSize is the size of each chunk in bytes (except for the last chunk which may be smaller)
+	ChunkSize int64 `json:"chunkSize"`
+	// Digest is the hex-encoded SHA-256 digest of the entire file content. It's verified when the entire
+	// file is retrieved (i.e. not for Range requests).
+	Digest string `json:"digest"`
+	// Chunks contains the CIDs of the chunks in order
+	Chunks []string `json:"chunks"`
+}
+
+// PublishRequest contains a file along with the update operation for the file index document
+// that maps a file name to the CID of the file
+type PublishRequest struct {
+	File File `json:"file"`
+	// IndexUpdate is the signed Sidetree update operation for the file index document
+	IndexUpdate json.RawMessage `json:"indexUpdate"`
+}
+
+// PublishResponse contains the CID of the published file and the status of the index update operation
+type PublishResponse struct {
+	CID    string `json:"cid"`
+	Status string `json:"status"`
+	// Error contains the reason that the index update operation failed (if the status is failed)
+	Error string `json:"error,omitempty"`
+}
+
+// FileListing contains a page of the files served by a base path
+type FileListing struct {
+	Files []FileInfo `json:"files"`
+	// Next is set if more files are available. It should be passed in the "after" parameter to retrieve the next page.
+	Next string `json:"next,omitempty"`
+}
+
+// FileInfo contains information about a file that's mapped in the file index document. The content type
+// and size are not set if the file was not found in DCAS.
+type FileInfo struct {
+	Name        string `json:"name"`
+	CID         string `json:"cid"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
 // FileIndexDoc contains a file index document
 type FileIndexDoc struct {
 	ID           string    `json:"id"`
@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/docutil"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	resthandler "github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+)
+
+const (
+	publishPath = "/publish"
+
+	// OperationStatusPending indicates that the index update operation was accepted and added to
+	// the batch. The new mapping is available once the batch has been anchored.
+	OperationStatusPending = "pending"
+	// OperationStatusFailed indicates that the file was stored but the index update operation couldn't be
+	// added to the batch. The operation may be resubmitted to the operations endpoint of the index namespace.
+	OperationStatusFailed = "failed"
+)
+
+// Publish is a REST handler that uploads a file to a DCAS store and then submits an update operation
+// for the file index document (which adds the mapping for the file) in a single request
+type Publish struct {
+	*Upload
+	processor resthandler.Processor
+	protocol  protocol.Client
+}
+
+// NewPublishHandler returns a new file publish handler
+func NewPublishHandler(channelID string, cfg Config, dcasProvider dcasClientProvider, processor resthandler.Processor, pc protocol.Client) *Publish {
+	return &Publish{
+		Upload:    NewUploadHandler(channelID, cfg, dcasProvider),
+		processor: processor,
+		protocol:  pc,
+	}
+}
+
+// Path returns the context path
+func (h *Publish) Path() string {
+	return h.BasePath + publishPath
+}
+
+// Method returns the HTTP method
+func (h *Publish) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the handler
+func (h *Publish) Handler() common.HTTPRequestHandler {
+	return h.publish
+}
+
+// publish stores the file in DCAS and then submits the update operation for the file index document.
+// Since the update operation is signed by the client, the client must compute the CID of the file in
+// advance. The update operation is validated before the file is stored and it's rejected if none of its
+// patches map a file name to the CID of the stored content. If the file was stored but the operation
+// couldn't be submitted then the response contains the CID along with the failed status.
+func (h *Publish) publish(rw http.ResponseWriter, req *http.Request) {
+	request := &PublishRequest{}
+	if err := h.decodeJSON(rw, req, request); err != nil {
+		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		return
+	}
+
+	resp, err := h.doPublish(request)
+	if err != nil {
+		if resp != nil {
+			common.WriteResponse(rw, err.(*common.HTTPError).Status(), resp)
+			return
+		}
+
+		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		return
+	}
+
+	common.WriteResponse(rw, http.StatusOK, resp)
+}
+
+// doPublish returns the response along with an error if the file was stored but the index update failed
+func (h *Publish) doPublish(request *PublishRequest) (*PublishResponse, error) {
+	if len(request.IndexUpdate) == 0 || string(request.IndexUpdate) == "null" {
+		return nil, common.NewHTTPError(http.StatusBadRequest, errors.New("index update is required"))
+	}
+
+	delta, err := h.unmarshalIndexUpdate(request.IndexUpdate)
+	if err != nil {
+		logger.Debugf("[%s:%s:%s] Invalid index update: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return nil, common.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	currentProtocol, err := h.protocol.Current()
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Error getting current protocol: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	if err := h.validateIndexUpdate(currentProtocol, request.IndexUpdate); err != nil {
+		logger.Debugf("[%s:%s:%s] Index update validation error: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return nil, common.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	cID, err := h.doUpload(&request.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if !referencesCID(delta, cID) {
+		logger.Debugf("[%s:%s:%s] Index update does not reference the CID of the uploaded file [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
+		return nil, common.NewHTTPError(http.StatusBadRequest, errors.Errorf("index update does not reference the uploaded file [%s]", cID))
+	}
+
+	if _, err := h.processor.ProcessOperation(request.IndexUpdate, currentProtocol.Protocol().GenesisTime); err != nil {
+		logger.Errorf("[%s:%s:%s] Error processing index update for file with CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
+
+		return &PublishResponse{
+			CID:    cID,
+			Status: OperationStatusFailed,
+			Error:  err.Error(),
+		}, common.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	logger.Debugf("[%s:%s:%s] Successfully published file with CID [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
+
+	return &PublishResponse{
+		CID:    cID,
+		Status: OperationStatusPending,
+	}, nil
+}
+
+// unmarshalIndexUpdate returns the delta of the given update operation after ensuring that the
+// operation is an update of the file index document of this endpoint
+func (h *Publish) unmarshalIndexUpdate(indexUpdate []byte) (*model.DeltaModel, error) {
+	req := &model.UpdateRequest{}
+	if err := json.Unmarshal(indexUpdate, req); err != nil {
+		return nil, errors.New("invalid index update")
+	}
+
+	if req.Operation != operation.TypeUpdate {
+		return nil, errors.Errorf("index update must be an update operation but got [%s]", req.Operation)
+	}
+
+	if req.DidSuffix != h.indexDocSuffix() {
+		return nil, errors.Errorf("index update must be for the file index document [%s]", h.IndexDocID)
+	}
+
+	if req.Delta == nil || len(req.Delta.Patches) == 0 {
+		return nil, errors.New("index update must contain at least one patch")
+	}
+
+	return req.Delta, nil
+}
+
+// validateIndexUpdate parses and validates the index update operation in the same way as the document handler
+// of the index namespace, so that an invalid operation is rejected before the file is stored
+func (h *Publish) validateIndexUpdate(pv protocol.Version, indexUpdate []byte) error {
+	if _, err := pv.OperationParser().Parse(h.processor.Namespace(), indexUpdate); err != nil {
+		return err
+	}
+
+	return pv.DocumentValidator().IsValidPayload(indexUpdate)
+}
+
+func (h *Publish) indexDocSuffix() string {
+	return strings.TrimPrefix(h.IndexDocID, h.IndexNamespace+docutil.NamespaceDelimiter)
+}
+
+// referencesCID returns true if any of the JSON patches in the given delta adds or replaces a mapping with the given CID
+func referencesCID(delta *model.DeltaModel, cID string) bool {
+	for _, p := range delta.Patches {
+		if referencedCIDs(p)[cID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func referencedCIDs(p patch.Patch) map[string]bool {
+	cIDs := make(map[string]bool)
+
+	action, err := p.GetAction()
+	if err != nil || action != patch.JSONPatch {
+		return cIDs
+	}
+
+	value, err := p.GetValue()
+	if err != nil {
+		return cIDs
+	}
+
+	patchBytes, err := json.Marshal(value)
+	if err != nil {
+		return cIDs
+	}
+
+	var ops []struct {
+		Op    string          `json:"op"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	if err := json.Unmarshal(patchBytes, &ops); err != nil {
+		return cIDs
+	}
+
+	for _, op := range ops {
+		if op.Op != "add" && op.Op != "replace" {
+			continue
+		}
+
+		var cID string
+		if err := json.Unmarshal(op.Value, &cID); err == nil {
+			cIDs[cID] = true
+		}
+	}
+
+	return cIDs
+}
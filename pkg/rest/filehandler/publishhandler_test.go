@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/model"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+//go:generate counterfeiter -o ../../mocks/operationprocessor.gen.go --fake-name OperationProcessor github.com/trustbloc/sidetree-core-go/pkg/restapi/dochandler.Processor
+
+func TestPublish(t *testing.T) {
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	processor := &mocks.OperationProcessor{}
+	pc := coremocks.NewMockProtocolClient()
+
+	cfg := Config{
+		BasePath:       "/schema",
+		ChaincodeName:  "file_cc",
+		Collection:     "schemas",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+	}
+
+	h := NewPublishHandler(channelID, cfg, dcasProvider, processor, pc)
+	require.Equal(t, "/schema/publish", h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+	require.NotNil(t, h.Handler())
+
+	file := File{
+		ContentType: "application/json",
+		Content:     []byte(`{"field1":"value1"}`),
+	}
+
+	cID := getCID(t, &file)
+
+	t.Run("Success", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "1234", cID)))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resp := &PublishResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, cID, resp.CID)
+		require.Equal(t, OperationStatusPending, resp.Status)
+
+		require.Equal(t, 1, processor.ProcessOperationCallCount())
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/schema/publish", bytes.NewReader([]byte("{"))))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+	})
+
+	t.Run("No index update", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "index update is required")
+	})
+
+	t.Run("Invalid index update", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, []byte(`"xxx"`)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid index update")
+	})
+
+	t.Run("Not an update operation", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeRecover, "1234", cID)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "index update must be an update operation")
+	})
+
+	t.Run("Wrong index document", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "5678", cID)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "index update must be for the file index document [file:idx:1234]")
+	})
+
+	t.Run("No patches", func(t *testing.T) {
+		indexUpdate, err := json.Marshal(&model.UpdateRequest{Operation: operation.TypeUpdate, DidSuffix: "1234"})
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, indexUpdate))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "index update must contain at least one patch")
+	})
+
+	t.Run("CID not referenced", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "1234", "some-other-cid")))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), fmt.Sprintf("index update does not reference the uploaded file [%s]", cID))
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, File{ContentType: "application/json"}, newIndexUpdate(t, operation.TypeUpdate, "1234", cID)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "content is required")
+	})
+
+	t.Run("Protocol error", func(t *testing.T) {
+		pc.Err = errors.New("injected protocol error")
+		defer func() { pc.Err = nil }()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "1234", cID)))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+
+	t.Run("Operation parse error", func(t *testing.T) {
+		parser := &coremocks.OperationParser{}
+		parser.ParseReturns(nil, errors.New("invalid signature"))
+		pc.CurrentVersion.OperationParserReturns(parser)
+		defer pc.CurrentVersion.OperationParserReturns(&coremocks.OperationParser{})
+
+		newFile := File{ContentType: "application/json", Content: []byte(`{"field1":"value2"}`)}
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, newFile, newIndexUpdate(t, operation.TypeUpdate, "1234", getCID(t, &newFile))))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid signature")
+
+		node, err := dcasClient.GetNode(getCID(t, &newFile))
+		require.NoError(t, err)
+		require.Nil(t, node, "file should not have been stored")
+	})
+
+	t.Run("Operation validation error", func(t *testing.T) {
+		validator := &coremocks.DocumentValidator{}
+		validator.IsValidPayloadReturns(errors.New("document not found"))
+		pc.CurrentVersion.DocumentValidatorReturns(validator)
+		defer pc.CurrentVersion.DocumentValidatorReturns(&coremocks.DocumentValidator{})
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "1234", cID)))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "document not found")
+	})
+
+	t.Run("Operation processing error", func(t *testing.T) {
+		processor.ProcessOperationReturns(nil, errors.New("injected processor error"))
+		defer processor.ProcessOperationReturns(nil, nil)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, newPublishRequest(t, file, newIndexUpdate(t, operation.TypeUpdate, "1234", cID)))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+
+		resp := &PublishResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, cID, resp.CID)
+		require.Equal(t, OperationStatusFailed, resp.Status)
+		require.Equal(t, "injected processor error", resp.Error)
+	})
+}
+
+func TestReferencesCID(t *testing.T) {
+	p1, err := patch.NewJSONPatch(`[{"op":"add","path":"/fileIndex/mappings/file1.json","value":"cid1"}]`)
+	require.NoError(t, err)
+
+	p2, err := patch.NewJSONPatch(`[{"op":"remove","path":"/fileIndex/mappings/file2.json","value":"cid2"},{"op":"replace","path":"/fileIndex/mappings/file3.json","value":"cid3"}]`)
+	require.NoError(t, err)
+
+	p3, err := patch.NewReplacePatch(`{"publicKeys":[]}`)
+	require.NoError(t, err)
+
+	delta := &model.DeltaModel{Patches: []patch.Patch{p1, p2, p3}}
+
+	require.True(t, referencesCID(delta, "cid1"))
+	require.False(t, referencesCID(delta, "cid2"))
+	require.True(t, referencesCID(delta, "cid3"))
+	require.False(t, referencesCID(delta, "cid4"))
+}
+
+func getCID(t *testing.T, file *File) string {
+	fileBytes, err := json.Marshal(file)
+	require.NoError(t, err)
+
+	cID, err := mocks.NewDCASClient().Put(bytes.NewReader(fileBytes))
+	require.NoError(t, err)
+
+	return cID
+}
+
+func newIndexUpdate(t *testing.T, opType operation.Type, suffix, cID string) []byte {
+	p, err := patch.NewJSONPatch(fmt.Sprintf(`[{"op":"add","path":"/fileIndex/mappings/schema1.json","value":"%s"}]`, cID))
+	require.NoError(t, err)
+
+	indexUpdate, err := json.Marshal(&model.UpdateRequest{
+		Operation:  opType,
+		DidSuffix:  suffix,
+		SignedData: "signed-data",
+		Delta:      &model.DeltaModel{Patches: []patch.Patch{p}},
+	})
+	require.NoError(t, err)
+
+	return indexUpdate
+}
+
+func newPublishRequest(t *testing.T, file File, indexUpdate []byte) *http.Request {
+	reqBytes, err := json.Marshal(&PublishRequest{File: file, IndexUpdate: indexUpdate})
+	require.NoError(t, err)
+
+	return httptest.NewRequest(http.MethodPost, "/schema/publish", bytes.NewReader(reqBytes))
+}
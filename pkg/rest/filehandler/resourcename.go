@@ -0,0 +1,64 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const pathSeparator = "/"
+
+// NormalizeResourceName returns the normalized form of the given resource name, which may contain
+// multiple path segments (e.g. schemas/v1/person.json). Leading, trailing and duplicate slashes as well
+// as "." segments are removed. An error is returned if the name is empty, if it contains a ".." segment
+// (which could be used to traverse outside of the base path) or if it contains a backslash or control character.
+func NormalizeResourceName(name string) (string, error) {
+	if strings.ContainsAny(name, `\`) {
+		return "", errors.Errorf("invalid character in resource name [%s]", name)
+	}
+
+	for _, c := range name {
+		if unicode.IsControl(c) {
+			return "", errors.Errorf("invalid character in resource name [%s]", name)
+		}
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(name, pathSeparator) {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return "", errors.Errorf("relative path segment not allowed in resource name [%s]", name)
+		default:
+			segments = append(segments, segment)
+		}
+	}
+
+	if len(segments) == 0 {
+		return "", errors.New("resource name not provided")
+	}
+
+	return strings.Join(segments, pathSeparator), nil
+}
+
+// ValidateResourceName returns an error if the given resource name is invalid or is not in normalized form
+func ValidateResourceName(name string) error {
+	normalized, err := NormalizeResourceName(name)
+	if err != nil {
+		return err
+	}
+
+	if normalized != name {
+		return errors.Errorf("resource name [%s] is not normalized - expecting [%s]", name, normalized)
+	}
+
+	return nil
+}
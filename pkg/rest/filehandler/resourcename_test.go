@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeResourceName(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		for name, expected := range map[string]string{
+			"schema1.json":                 "schema1.json",
+			"schemas/v1/person.json":       "schemas/v1/person.json",
+			"/schemas/v1/person.json/":     "schemas/v1/person.json",
+			"schemas//v1/./person.json":    "schemas/v1/person.json",
+			"./did-configuration.json":     "did-configuration.json",
+			"schemas/v1.0/person..v1.json": "schemas/v1.0/person..v1.json",
+		} {
+			normalized, err := NormalizeResourceName(name)
+			require.NoError(t, err)
+			require.Equal(t, expected, normalized)
+		}
+	})
+
+	t.Run("Empty name -> error", func(t *testing.T) {
+		for _, name := range []string{"", "/", ".", "/./"} {
+			_, err := NormalizeResourceName(name)
+			require.EqualError(t, err, "resource name not provided")
+		}
+	})
+
+	t.Run("Path traversal -> error", func(t *testing.T) {
+		for _, name := range []string{"..", "../secret.json", "schemas/../../secret.json", "schemas/.."} {
+			_, err := NormalizeResourceName(name)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "relative path segment not allowed")
+		}
+	})
+
+	t.Run("Invalid character -> error", func(t *testing.T) {
+		for _, name := range []string{`schemas\person.json`, "schemas/person\x00.json", "schemas/\nperson.json"} {
+			_, err := NormalizeResourceName(name)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid character in resource name")
+		}
+	})
+}
+
+func TestValidateResourceName(t *testing.T) {
+	require.NoError(t, ValidateResourceName("schemas/v1/person.json"))
+
+	err := ValidateResourceName("/schemas/v1/person.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not normalized - expecting [schemas/v1/person.json]")
+
+	err = ValidateResourceName("../person.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "relative path segment not allowed")
+}
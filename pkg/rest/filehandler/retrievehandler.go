@@ -9,7 +9,9 @@ package filehandler
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -20,6 +22,8 @@ import (
 
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 )
 
 var logger = flogging.MustGetLogger("sidetree_peer")
@@ -36,28 +40,37 @@ type documentResolver interface {
 // Retrieve manages file retrievals from a DCAS store
 type Retrieve struct {
 	Config
-	channelID    string
-	resolver     documentResolver
-	dcasProvider dcasClientProvider
+	channelID       string
+	resolver        documentResolver
+	versionResolver indexVersionResolver
+	dcasProvider    dcasClientProvider
 }
 
 type dcasClientProvider interface {
 	GetDCASClient(channelID, namespace, coll string) (dcas.DCAS, error)
 }
 
-// NewRetrieveHandler returns a new file retrieve handler
-func NewRetrieveHandler(channelID string, cfg Config, resolver documentResolver, dcasProvider dcasClientProvider) *Retrieve {
+// NewRetrieveHandler returns a new file retrieve handler. The version resolver is optional - if it's nil
+// then retrieval of a historical version of a file is not supported.
+func NewRetrieveHandler(channelID string, cfg Config, resolver documentResolver, versionResolver indexVersionResolver, dcasProvider dcasClientProvider) *Retrieve {
 	return &Retrieve{
-		Config:       cfg,
-		resolver:     resolver,
-		dcasProvider: dcasProvider,
-		channelID:    channelID,
+		Config:          cfg,
+		resolver:        resolver,
+		versionResolver: versionResolver,
+		dcasProvider:    dcasProvider,
+		channelID:       channelID,
 	}
 }
 
-// Path returns the context path
+// Path returns the context path. The resource name may contain multiple path segments. If an index
+// document is configured then the resource name may be empty so that the index document is served
+// for the base path.
 func (h *Retrieve) Path() string {
-	return h.BasePath + "/{resourceName}"
+	if h.Website.IndexDocument != "" {
+		return h.BasePath + "/{resourceName:.*}"
+	}
+
+	return h.BasePath + "/{resourceName:.+}"
 }
 
 // Method returns the HTTP method
@@ -73,65 +86,100 @@ func (h *Retrieve) Handler() common.HTTPRequestHandler {
 // retrieve retrieves a resource by name. First the file index Sidetree document for the path
 // is retrieved and then the ID of the resource is looked up from the index document. The file
 // is then retrieved from the DCAS store using that ID.
+//
+// The ID of the resource is used as the ETag. Since the mapping of a name to an ID may change, clients
+// must revalidate a cached copy of the file (using If-None-Match), although the file isn't retrieved
+// from DCAS if the ID hasn't changed.
+//
+// A historical version of the file may be retrieved by specifying either the "transactionTime" or the
+// "operationNumber" query parameter, in which case the file index document is resolved as of that version.
+//
+// If the website options are configured then an index document, fallback document or not found document
+// may be served in place of the requested file (see WebsiteConfig).
 func (h *Retrieve) retrieve(rw http.ResponseWriter, req *http.Request) {
 	resourceName := getResourceName(req)
 
 	logger.Debugf("[%s:%s:%s] Retrieving document for name [%s]", h.channelID, h.ChaincodeName, h.Collection, resourceName)
 
-	fileBytes, contentType, err := h.doRetrieve(resourceName)
+	version, err := getIndexVersion(getQueryParams(req))
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	file, err := h.resolveCID(resourceName, version)
 	if err != nil {
 		writeError(rw, err.(*common.HTTPError).Status(), err)
 		return
 	}
 
-	logger.Debugf("[%s:%s:%s] ... retrieved file [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, resourceName, fileBytes)
-	writeResponse(rw, http.StatusOK, fileBytes, contentType)
-}
+	etag := httpserver.ETag(file.cID)
 
-func (h *Retrieve) doRetrieve(resourceName string) ([]byte, string, error) {
-	if resourceName == "" {
-		return nil, "", common.NewHTTPError(http.StatusBadRequest, errors.New("resource name not provided"))
+	if file.status == http.StatusOK && httpserver.IfNoneMatch(req, etag) {
+		logger.Debugf("[%s:%s:%s] File [%s] not modified", h.channelID, h.ChaincodeName, h.Collection, resourceName)
+		httpserver.NewResponseWriter(rw).WriteNotModified(etag, httpserver.CacheControlRevalidate)
+		return
 	}
 
-	logger.Debugf("[%s:%s:%s] Resolving index file [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
-
-	fileIndex, err := h.retrieveIndexDoc()
+	content, contentType, err := h.retrieveFile(file.cID)
 	if err != nil {
-		return nil, "", err
+		writeError(rw, err.(*common.HTTPError).Status(), err)
+		return
 	}
 
-	cID := fileIndex.Mappings[resourceName]
-	if cID == "" {
-		logger.Debugf("[%s:%s:%s] Resource [%s] not found in index file", h.channelID, h.ChaincodeName, h.Collection, resourceName)
-		return nil, "", common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
+	if contentType == "" {
+		contentType = h.contentTypeFor(file.name)
 	}
 
-	logger.Debugf("[%s:%s:%s] Got CID for [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, resourceName, cID)
+	if file.status == http.StatusNotFound {
+		logger.Debugf("[%s:%s:%s] ... file [%s] not found - serving not found document", h.channelID, h.ChaincodeName, h.Collection, resourceName)
+		writeNotFoundDocument(rw, content, contentType)
+		return
+	}
 
-	fileBytes, contentType, err := h.retrieveFile(cID)
-	if err != nil {
-		return nil, "", err
+	logger.Debugf("[%s:%s:%s] ... retrieved file [%s]", h.channelID, h.ChaincodeName, h.Collection, resourceName)
+	httpserver.NewResponseWriter(rw).WriteContent(req, content, contentType, etag, httpserver.CacheControlRevalidate)
+}
+
+// resolveCID returns the file (including its ID) that's served for the given resource from the file index document
+func (h *Retrieve) resolveCID(resourceName string, version *IndexVersion) (*resolvedFile, error) {
+	requestedName := resourceName
+
+	if isDirectory(resourceName) && h.Website.IndexDocument != "" {
+		resourceName += pathSeparator + h.Website.IndexDocument
 	}
 
-	if len(fileBytes) == 0 {
-		logger.Debugf("[%s:%s:%s] CID [%s] not found in DCAS", h.channelID, h.ChaincodeName, h.Collection, cID)
-		return nil, "", common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
+	if resourceName == "" {
+		return nil, common.NewHTTPError(http.StatusBadRequest, errors.New("resource name not provided"))
 	}
 
-	return fileBytes, contentType, nil
-}
+	resourceName, err := NormalizeResourceName(resourceName)
+	if err != nil {
+		logger.Debugf("[%s:%s:%s] Invalid resource name: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return nil, common.NewHTTPError(http.StatusBadRequest, err)
+	}
 
-func (h *Retrieve) retrieveIndexDoc() (*FileIndex, error) {
-	logger.Debugf("[%s:%s:%s] Retrieving index document [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
+	logger.Debugf("[%s:%s:%s] Resolving index file [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
 
-	result, err := h.resolver.ResolveDocument(h.IndexDocID)
+	fileIndex, err := h.retrieveIndexDoc(version)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			logger.Warnf("[%s:%s:%s] File index document not found in document store: [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
-			return nil, common.NewHTTPError(http.StatusNotFound, errors.New("file index document not found"))
-		}
+		return nil, err
+	}
 
-		return nil, common.NewHTTPError(http.StatusInternalServerError, err)
+	file, err := h.resolveFile(fileIndex, resourceName, requestedName)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("[%s:%s:%s] Got CID for [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, file.name, file.cID)
+
+	return file, nil
+}
+
+func (h *Retrieve) retrieveIndexDoc(version *IndexVersion) (*FileIndex, error) {
+	result, err := h.resolveIndexDoc(version)
+	if err != nil {
+		return nil, err
 	}
 
 	if isDeactivated(result) {
@@ -166,38 +214,114 @@ func (h *Retrieve) retrieveIndexDoc() (*FileIndex, error) {
 	return &fileIndexDoc.FileIndex, nil
 }
 
-func (h *Retrieve) retrieveFile(cID string) ([]byte, string, error) {
+// storedFile contains the union of the fields of a File and a FileManifest so that the object retrieved
+// from DCAS may be unmarshalled before knowing whether or not the file was stored in chunks.
+type storedFile struct {
+	File
+	Size      int64    `json:"size"`
+	ChunkSize int64    `json:"chunkSize"`
+	Digest    string   `json:"digest"`
+	Chunks    []string `json:"chunks"`
+}
+
+// retrieveFile retrieves the file with the given CID from DCAS. If the file was stored in chunks then
+// the returned reader retrieves the chunks as they are read.
+func (h *Retrieve) retrieveFile(cID string) (io.ReadSeeker, string, error) {
 	dcasClient, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Could not get DCAS client: %s", h.channelID, h.ChaincodeName, h.Collection, err)
 		return nil, "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
 	}
 
+	f, err := h.getStoredFile(dcasClient, cID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(f.Chunks) == 0 {
+		if len(f.Content) == 0 {
+			logger.Debugf("[%s:%s:%s] Content missing from file retrieved from DCAS for cID [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
+			return nil, "", common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
+		}
+
+		return bytes.NewReader(f.Content), f.ContentType, nil
+	}
+
+	manifest := &FileManifest{
+		ContentType: f.ContentType,
+		Size:        f.Size,
+		ChunkSize:   f.ChunkSize,
+		Digest:      f.Digest,
+		Chunks:      f.Chunks,
+	}
+
+	if err := validateManifest(manifest); err != nil {
+		logger.Errorf("[%s:%s:%s] Invalid file manifest for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
+		return nil, "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	logger.Debugf("[%s:%s:%s] File for CID [%s] is stored in %d chunks", h.channelID, h.ChaincodeName, h.Collection, cID, len(manifest.Chunks))
+
+	return newChunkReader(dcasClient, manifest), manifest.ContentType, nil
+}
+
+// getStoredFile retrieves the file (or file manifest) with the given CID from DCAS
+func (h *Retrieve) getStoredFile(dcasClient dcas.DCAS, cID string) (*storedFile, error) {
 	content := bytes.NewBuffer(nil)
 
-	err = dcasClient.Get(cID, content)
+	err := dcasClient.Get(cID, content)
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Error retrieving DCAS document for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
-		return nil, "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
 	}
 
 	if len(content.Bytes()) == 0 {
 		logger.Debugf("[%s:%s:%s] File not found in DCAS for CID [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
-		return nil, "", common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
+		return nil, common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
 	}
 
-	f := &File{}
+	f := &storedFile{}
 	if err := json.Unmarshal(content.Bytes(), f); err != nil {
 		logger.Errorf("[%s:%s:%s] Error unmarshalling data for CID [%s]: %s", h.channelID, h.ChaincodeName, h.Collection, cID, err)
-		return nil, "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
 	}
 
-	if f.ContentType == "" {
+	if f.ContentType == "" && len(h.Website.ContentTypes) == 0 {
 		logger.Errorf("[%s:%s:%s] Content-type missing from file retrieved from DCAS for cID [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
-		return nil, "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+		return nil, common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
 	}
 
-	return f.Content, f.ContentType, nil
+	return f, nil
+}
+
+func (h *Retrieve) resolveIndexDoc(version *IndexVersion) (*document.ResolutionResult, error) {
+	var result *document.ResolutionResult
+	var err error
+
+	if version == nil {
+		logger.Debugf("[%s:%s:%s] Retrieving index document [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
+
+		result, err = h.resolver.ResolveDocument(h.IndexDocID)
+	} else {
+		if h.versionResolver == nil {
+			return nil, common.NewHTTPError(http.StatusBadRequest, errors.New("retrieval of a version of the file index document is not supported"))
+		}
+
+		logger.Debugf("[%s:%s:%s] Retrieving index document [%s] at %s", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID, version)
+
+		result, err = h.versionResolver.ResolveDocumentAtVersion(h.IndexDocID, version)
+	}
+
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logger.Warnf("[%s:%s:%s] File index document not found in document store: [%s]", h.channelID, h.ChaincodeName, h.Collection, h.IndexDocID)
+			return nil, common.NewHTTPError(http.StatusNotFound, errors.New("file index document not found"))
+		}
+
+		return nil, common.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return result, nil
 }
 
 func isDeactivated(resolutionResult *document.ResolutionResult) bool {
@@ -213,13 +337,8 @@ var getResourceName = func(req *http.Request) string {
 	return mux.Vars(req)["resourceName"]
 }
 
-func writeResponse(rw http.ResponseWriter, status int, bytes []byte, contentType string) {
-	rw.Header().Set("Content-Type", contentType)
-	rw.WriteHeader(status)
-	_, e := rw.Write(bytes)
-	if e != nil {
-		logger.Errorf("Unable to write response: %s", e)
-	}
+var getQueryParams = func(req *http.Request) url.Values {
+	return req.URL.Query()
 }
 
 func writeError(rw http.ResponseWriter, status int, err error) {
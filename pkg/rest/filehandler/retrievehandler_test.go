@@ -13,10 +13,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
 )
 
@@ -42,8 +44,8 @@ func TestFileRetrieveHandler(t *testing.T) {
 		IndexDocID:     "file:idx:1234",
 	}
 
-	h := NewRetrieveHandler(channelID, cfg, docResolver, dcasProvider)
-	require.Equal(t, cfg.BasePath+"/{resourceName}", h.Path())
+	h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+	require.Equal(t, cfg.BasePath+"/{resourceName:.+}", h.Path())
 	require.Equal(t, http.MethodGet, h.Method())
 	require.NotNil(t, h.Handler())
 
@@ -296,10 +298,136 @@ func TestFileRetrieveHandler(t *testing.T) {
 			h.Handler()(rw, req)
 			require.Equal(t, http.StatusOK, rw.Code)
 			require.Equal(t, fileContents, rw.Body.String())
+			require.Equal(t, "application/json", rw.Header().Get(httpserver.ContentTypeHeader))
+			require.Equal(t, `"1234567890"`, rw.Header().Get(httpserver.ETagHeader))
+			require.Equal(t, httpserver.CacheControlRevalidate, rw.Header().Get(httpserver.CacheControlHeader))
+		})
+
+		t.Run("If-None-Match -> Not Modified", func(t *testing.T) {
+			dcasClient.WithGetError(errors.New("file should not be retrieved"))
+			defer dcasClient.WithGetError(nil)
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil)
+			req.Header.Set(httpserver.IfNoneMatchHeader, `"1234567890"`)
+			h.Handler()(rw, req)
+			require.Equal(t, http.StatusNotModified, rw.Code)
+			require.Empty(t, rw.Body.String())
+			require.Equal(t, `"1234567890"`, rw.Header().Get(httpserver.ETagHeader))
+			require.Equal(t, httpserver.CacheControlRevalidate, rw.Header().Get(httpserver.CacheControlHeader))
+		})
+
+		t.Run("If-None-Match with previous ID -> Success", func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil)
+			req.Header.Set(httpserver.IfNoneMatchHeader, `"0987654321"`)
+			h.Handler()(rw, req)
+			require.Equal(t, http.StatusOK, rw.Code)
+			require.Equal(t, `{"field1":"value1"}`, rw.Body.String())
+		})
+
+		t.Run("Range -> Partial Content", func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/schema/schema1.json", nil)
+			req.Header.Set("Range", "bytes=0-9")
+			h.Handler()(rw, req)
+			require.Equal(t, http.StatusPartialContent, rw.Code)
+			require.Equal(t, `{"field1":`, rw.Body.String())
+			require.Equal(t, "bytes 0-9/19", rw.Header().Get("Content-Range"))
 		})
 	})
 }
 
+func TestFileRetrieveHandler_NestedPath(t *testing.T) {
+	restore := getResourceName
+	getResourceName = func(req *http.Request) string { return mux.Vars(req)["resourceName"] }
+	defer func() { getResourceName = restore }()
+
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	fileIndexDoc := &FileIndexDoc{
+		ID: "file:idx:1234",
+		FileIndex: FileIndex{
+			BasePath: "/.well-known",
+			Mappings: map[string]string{
+				"did-configuration.json": "1111111111",
+				"schemas/v1/person.json": "2222222222",
+			},
+		},
+	}
+
+	doc, err := getDocument(fileIndexDoc)
+	require.NoError(t, err)
+
+	docResolver := &mocks.DocumentResolver{}
+	docResolver.ResolveDocumentReturns(&document.ResolutionResult{Document: doc}, nil)
+
+	dcasClient.WithData("1111111111", getFileBytes(t, `{"name":"did-configuration"}`))
+	dcasClient.WithData("2222222222", getFileBytes(t, `{"name":"person"}`))
+
+	cfg := Config{
+		BasePath:       "/.well-known",
+		ChaincodeName:  "file_cc",
+		Collection:     "schemas",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+	}
+
+	h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+
+	router := mux.NewRouter()
+	router.HandleFunc(h.Path(), h.Handler()).Methods(h.Method())
+
+	t.Run("Top-level file", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/.well-known/did-configuration.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `{"name":"did-configuration"}`, rw.Body.String())
+	})
+
+	t.Run("Nested file", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/.well-known/schemas/v1/person.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `{"name":"person"}`, rw.Body.String())
+	})
+
+	t.Run("Nested file not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/.well-known/schemas/v2/person.json", nil))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+	})
+
+	t.Run("Normalized path", func(t *testing.T) {
+		getResourceName = func(req *http.Request) string { return "schemas//v1/./person.json/" }
+		defer func() { getResourceName = func(req *http.Request) string { return mux.Vars(req)["resourceName"] } }()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/.well-known/schemas/v1/person.json", nil))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `{"name":"person"}`, rw.Body.String())
+	})
+
+	t.Run("Path traversal", func(t *testing.T) {
+		getResourceName = func(req *http.Request) string { return "schemas/../../secret.json" }
+		defer func() { getResourceName = func(req *http.Request) string { return mux.Vars(req)["resourceName"] } }()
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, "/.well-known/secret.json", nil))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "relative path segment not allowed")
+	})
+}
+
+func getFileBytes(t *testing.T, content string) []byte {
+	fileBytes, err := json.Marshal(&File{ContentType: "application/json", Content: []byte(content)})
+	require.NoError(t, err)
+
+	return fileBytes
+}
+
 func getDocument(fileIndexDoc *FileIndexDoc) (document.Document, error) {
 	bytes, err := json.Marshal(fileIndexDoc)
 	if err != nil {
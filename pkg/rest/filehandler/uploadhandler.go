@@ -8,17 +8,25 @@ package filehandler
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/pkg/errors"
 	dcasclient "github.com/trustbloc/fabric-peer-ext/pkg/collections/offledger/dcas/client"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 )
 
 const (
 	badRequest = "bad request"
+
+	// jsonOverhead is the allowance for the fields of a JSON request other than the base64-encoded content
+	// (e.g. the content type or the index update of a publish request) when the size of the request is limited
+	jsonOverhead = 16 * 1024
 )
 
 // Upload manages file uploads to a DCAS store
@@ -52,11 +60,32 @@ func (h *Upload) Handler() common.HTTPRequestHandler {
 	return h.upload
 }
 
+// upload uploads a file and responds with the CID of the file. The file may be provided as:
+// - a JSON File (if the Content-Type of the request is application/json or isn't set)
+// - the first file in a multipart form (multipart/form-data)
+// - the request body (any other Content-Type), in which case the Content-Type of the request is the content type of the file
+// Note that a JSON file must be uploaded as a JSON File or in a multipart form.
+//
+// The returned CID is the CID of the stored file (which wraps the content along with its content type) so the
+// CID of the content itself (see httpserver.ContentCID) is returned in the X-Content-CID header. If the client
+// provided an expected CID (in the X-Expected-CID header) and it doesn't match the CID of the content then a 400
+// is returned and the file isn't stored.
 func (h *Upload) upload(rw http.ResponseWriter, req *http.Request) {
-	request := &File{}
-	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
-		logger.Debugf("[%s:%s:%s] Error unmarshalling request: %s", h.channelID, h.ChaincodeName, h.Collection, err)
-		common.WriteError(rw, http.StatusBadRequest, errors.New(badRequest))
+	request, err := h.readFile(rw, req)
+	if err != nil {
+		common.WriteError(rw, err.(*common.HTTPError).Status(), err)
+		return
+	}
+
+	contentCID, err := httpserver.ContentCID(request.Content)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Error computing CID of uploaded content: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		common.WriteError(rw, http.StatusInternalServerError, errors.New(serverError))
+		return
+	}
+
+	if err := httpserver.MatchCID(req, contentCID); err != nil {
+		common.WriteError(rw, err.(*httpserver.Error).Status(), err)
 		return
 	}
 
@@ -66,12 +95,62 @@ func (h *Upload) upload(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	rw.Header().Set(httpserver.ContentCIDHeader, contentCID)
+
 	common.WriteResponse(rw, http.StatusOK, id)
 }
 
-// doUpload uploads the file and returns the CAS key of the file.
+// readFile reads the file from the upload request
+func (h *Upload) readFile(rw http.ResponseWriter, req *http.Request) (*File, error) {
+	mediaType := httpserver.MediaType(req)
+
+	if mediaType != "" && mediaType != httpserver.ContentTypeJSON {
+		uploaded, err := httpserver.ReadUpload(req, h.MaxUploadSize)
+		if err != nil {
+			logger.Debugf("[%s:%s:%s] Invalid upload request: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+
+			return nil, common.NewHTTPError(err.(*httpserver.Error).Status(), err)
+		}
+
+		return &File{
+			ContentType: uploaded.ContentType,
+			Content:     uploaded.Content,
+		}, nil
+	}
+
+	request := &File{}
+	if err := h.decodeJSON(rw, req, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// decodeJSON decodes the JSON request body. If a maximum upload size is configured then the request
+// is rejected as soon as it's larger than a request with content of the maximum size could be.
+func (h *Upload) decodeJSON(rw http.ResponseWriter, req *http.Request, v interface{}) error {
+	var body io.Reader = req.Body
+	if h.MaxUploadSize > 0 {
+		body = httpserver.LimitBody(rw, req, int64(base64.StdEncoding.EncodedLen(int(h.MaxUploadSize))+jsonOverhead))
+	}
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		if err == httpserver.ContentTooLargeError {
+			logger.Debugf("[%s:%s:%s] Request exceeds the maximum upload size of %d bytes", h.channelID, h.ChaincodeName, h.Collection, h.MaxUploadSize)
+			return common.NewHTTPError(http.StatusRequestEntityTooLarge, errors.Errorf("content exceeds the maximum size of %d bytes", h.MaxUploadSize))
+		}
+
+		logger.Debugf("[%s:%s:%s] Error unmarshalling request: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return common.NewHTTPError(http.StatusBadRequest, errors.New(badRequest))
+	}
+
+	return nil
+}
+
+// doUpload uploads the file and returns the CAS key of the file. The content type may only be omitted
+// if default content types are configured for the website.
 func (h *Upload) doUpload(request *File) (string, error) {
-	if request.ContentType == "" {
+	if request.ContentType == "" && len(h.Website.ContentTypes) == 0 {
 		return "", common.NewHTTPError(http.StatusBadRequest, errors.New("content type is required"))
 	}
 
@@ -79,10 +158,8 @@ func (h *Upload) doUpload(request *File) (string, error) {
 		return "", common.NewHTTPError(http.StatusBadRequest, errors.New("content is required"))
 	}
 
-	content, err := json.Marshal(request)
-	if err != nil {
-		logger.Errorf("[%s:%s:%s] Could not marshal data: %s", h.channelID, h.ChaincodeName, h.Collection, err)
-		return "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	if h.MaxUploadSize > 0 && int64(len(request.Content)) > h.MaxUploadSize {
+		return "", common.NewHTTPError(http.StatusRequestEntityTooLarge, errors.Errorf("content exceeds the maximum size of %d bytes", h.MaxUploadSize))
 	}
 
 	client, err := h.dcasProvider.GetDCASClient(h.channelID, h.ChaincodeName, h.Collection)
@@ -91,6 +168,16 @@ func (h *Upload) doUpload(request *File) (string, error) {
 		return "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
 	}
 
+	if h.ChunkSize > 0 && len(request.Content) > h.ChunkSize {
+		return h.doChunkedUpload(client, request)
+	}
+
+	content, err := json.Marshal(request)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Could not marshal data: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
 	cID, err := client.Put(bytes.NewReader(content), dcasclient.WithNodeType(dcasclient.FileNodeType))
 	if err != nil {
 		logger.Errorf("[%s:%s:%s] Error storing file to DCAS collection: %s", h.channelID, h.ChaincodeName, h.Collection, err)
@@ -101,3 +188,19 @@ func (h *Upload) doUpload(request *File) (string, error) {
 
 	return cID, nil
 }
+
+// doChunkedUpload splits the file into chunks which are stored individually in DCAS along with
+// a manifest. The CAS key of the manifest is returned.
+func (h *Upload) doChunkedUpload(client dcasclient.DCAS, request *File) (string, error) {
+	logger.Debugf("[%s:%s:%s] Storing file of size %d in chunks of size %d", h.channelID, h.ChaincodeName, h.Collection, len(request.Content), h.ChunkSize)
+
+	cID, err := putChunked(client, request.ContentType, request.Content, h.ChunkSize)
+	if err != nil {
+		logger.Errorf("[%s:%s:%s] Error storing chunked file to DCAS collection: %s", h.channelID, h.ChaincodeName, h.Collection, err)
+		return "", common.NewHTTPError(http.StatusInternalServerError, errors.New(serverError))
+	}
+
+	logger.Debugf("[%s:%s:%s] Successfully uploaded chunked file to DCAS with manifest CID [%s]", h.channelID, h.ChaincodeName, h.Collection, cID)
+
+	return cID, nil
+}
@@ -9,13 +9,17 @@ package filehandler
 import (
 	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/sidetree-fabric/pkg/httpserver"
 	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
 )
 
@@ -123,4 +127,119 @@ func TestFileUploadHandler(t *testing.T) {
 		h.Handler()(rw, req)
 		require.Equal(t, http.StatusOK, rw.Code)
 	})
+
+	t.Run("Raw body", func(t *testing.T) {
+		cID := getCID(t, &File{ContentType: "text/plain", Content: []byte("some text")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader([]byte("some text")))
+		req.Header.Set(httpserver.ContentTypeHeader, "text/plain")
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `"`+cID+`"`, strings.TrimSpace(rw.Body.String()))
+	})
+
+	t.Run("Multipart form", func(t *testing.T) {
+		content := []byte(`{"field1":"value1"}`)
+		cID := getCID(t, &File{ContentType: "application/json", Content: content})
+
+		body := bytes.NewBuffer(nil)
+		w := multipart.NewWriter(body)
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="file"; filename="schema1.json"`)
+		header.Set(httpserver.ContentTypeHeader, "application/json")
+
+		part, err := w.CreatePart(header)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/schema", body)
+		req.Header.Set(httpserver.ContentTypeHeader, w.FormDataContentType())
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, `"`+cID+`"`, strings.TrimSpace(rw.Body.String()))
+	})
+
+	t.Run("Empty raw body", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(nil))
+		req.Header.Set(httpserver.ContentTypeHeader, "text/plain")
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, httpserver.StatusEmptyContent, rw.Body.String())
+	})
+
+	t.Run("Max upload size", func(t *testing.T) {
+		cfg := cfg
+		cfg.MaxUploadSize = 5
+
+		h := NewUploadHandler(channelID, cfg, dcasProvider)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader([]byte("some text")))
+		req.Header.Set(httpserver.ContentTypeHeader, "text/plain")
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+
+		fileBytes, err := json.Marshal(&File{ContentType: "text/plain", Content: []byte("some text")})
+		require.NoError(t, err)
+
+		rw = httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(fileBytes)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+		require.Equal(t, "content exceeds the maximum size of 5 bytes", rw.Body.String())
+
+		// The JSON request body is rejected while it's being read
+		fileBytes, err = json.Marshal(&File{ContentType: "text/plain", Content: bytes.Repeat([]byte("x"), 2*jsonOverhead)})
+		require.NoError(t, err)
+
+		rw = httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(fileBytes)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+		require.Equal(t, "content exceeds the maximum size of 5 bytes", rw.Body.String())
+	})
+
+	t.Run("Expected CID", func(t *testing.T) {
+		content := []byte("expected CID content")
+
+		// The expected CID is the CID of the raw content
+		contentCID, err := mocks.NewDCASClient().Put(bytes.NewReader(content))
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(content))
+		req.Header.Set(httpserver.ContentTypeHeader, "text/plain")
+		req.Header.Set(httpserver.ExpectedCIDHeader, contentCID)
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		fileCID := getCID(t, &File{ContentType: "text/plain", Content: content})
+		require.Equal(t, `"`+fileCID+`"`, strings.TrimSpace(rw.Body.String()))
+
+		// The CID of the content is returned in a header so that it may be matched with the expected CID
+		require.Equal(t, contentCID, rw.Header().Get(httpserver.ContentCIDHeader))
+
+		dcasClient := mocks.NewDCASClient()
+		dcasProvider := &mocks.DCASClientProvider{}
+		dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+		h := NewUploadHandler(channelID, cfg, dcasProvider)
+
+		rw = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(content))
+		req.Header.Set(httpserver.ContentTypeHeader, "text/plain")
+		req.Header.Set(httpserver.ExpectedCIDHeader, fileCID)
+		h.Handler()(rw, req)
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, httpserver.StatusCIDMismatch, rw.Body.String())
+
+		// The file must not be stored if the CID doesn't match
+		stored := &bytes.Buffer{}
+		require.NoError(t, dcasClient.Get(fileCID, stored))
+		require.Empty(t, stored.Bytes())
+	})
 }
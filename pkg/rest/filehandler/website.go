@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+const defaultContentType = "application/octet-stream"
+
+// resolvedFile contains the file that's served for a requested resource name. The name and status may
+// differ from the request if one of the website documents is served instead of the requested file.
+type resolvedFile struct {
+	name   string
+	cID    string
+	status int
+}
+
+// isDirectory returns true if the given resource name refers to a directory (i.e. it's empty or ends with '/')
+func isDirectory(resourceName string) bool {
+	return resourceName == "" || resourceName[len(resourceName)-1:] == pathSeparator
+}
+
+// resolveFile looks up the given (normalized) resource name in the file index. If the name isn't found then
+// the index document, fallback document and not found document are tried (in that order) if they're configured.
+// The requested name is the name in the request, before the index document was appended for a directory. Whether
+// or not the fallback document applies depends on the requested name (rather than the name of the index document).
+func (h *Retrieve) resolveFile(fileIndex *FileIndex, resourceName, requestedName string) (*resolvedFile, error) {
+	if cID := fileIndex.Mappings[resourceName]; cID != "" {
+		return &resolvedFile{name: resourceName, cID: cID, status: http.StatusOK}, nil
+	}
+
+	site := h.Website
+
+	if site.IndexDocument != "" {
+		name := resourceName + pathSeparator + site.IndexDocument
+		if cID := fileIndex.Mappings[name]; cID != "" {
+			logger.Debugf("[%s:%s:%s] Serving index document [%s] for [%s]", h.channelID, h.ChaincodeName, h.Collection, name, resourceName)
+			return &resolvedFile{name: name, cID: cID, status: http.StatusOK}, nil
+		}
+	}
+
+	if site.FallbackDocument != "" && path.Ext(requestedName) == "" {
+		if cID := fileIndex.Mappings[site.FallbackDocument]; cID != "" {
+			logger.Debugf("[%s:%s:%s] Serving fallback document [%s] for [%s]", h.channelID, h.ChaincodeName, h.Collection, site.FallbackDocument, resourceName)
+			return &resolvedFile{name: site.FallbackDocument, cID: cID, status: http.StatusOK}, nil
+		}
+	}
+
+	if site.NotFoundDocument != "" {
+		if cID := fileIndex.Mappings[site.NotFoundDocument]; cID != "" {
+			logger.Debugf("[%s:%s:%s] Serving not found document [%s] for [%s]", h.channelID, h.ChaincodeName, h.Collection, site.NotFoundDocument, resourceName)
+			return &resolvedFile{name: site.NotFoundDocument, cID: cID, status: http.StatusNotFound}, nil
+		}
+	}
+
+	logger.Debugf("[%s:%s:%s] Resource [%s] not found in index file", h.channelID, h.ChaincodeName, h.Collection, resourceName)
+
+	return nil, common.NewHTTPError(http.StatusNotFound, errors.New(fileNotFound))
+}
+
+// contentTypeFor returns the content type for a file that was uploaded without one. The content type is
+// determined from the extension of the file name.
+func (h *Retrieve) contentTypeFor(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+
+	if contentType, ok := h.Website.ContentTypes[strings.TrimPrefix(ext, ".")]; ok {
+		return contentType
+	}
+
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+
+	return defaultContentType
+}
+
+// writeNotFoundDocument writes the not found document with a 404 status. (http.ServeContent can't be used
+// since it always responds with a success status.)
+func writeNotFoundDocument(rw http.ResponseWriter, content io.Reader, contentType string) {
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(http.StatusNotFound)
+
+	if _, err := io.Copy(rw, content); err != nil {
+		logger.Errorf("Unable to write response: %s", err)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/sidetree-fabric/pkg/mocks"
+)
+
+func TestFileRetrieveHandler_Website(t *testing.T) {
+	restore := getResourceName
+	getResourceName = func(req *http.Request) string { return mux.Vars(req)["resourceName"] }
+	defer func() { getResourceName = restore }()
+
+	dcasClient := mocks.NewDCASClient()
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(dcasClient, nil)
+
+	cfg := Config{
+		BasePath:       "/site",
+		ChaincodeName:  "file_cc",
+		Collection:     "site",
+		IndexNamespace: "file:idx",
+		IndexDocID:     "file:idx:1234",
+		Website: WebsiteConfig{
+			IndexDocument:    "index.html",
+			NotFoundDocument: "404.html",
+			FallbackDocument: "index.html",
+			ContentTypes:     map[string]string{"md": "text/markdown"},
+		},
+	}
+
+	upload := func(contentType, content string) string {
+		h := NewUploadHandler(channelID, cfg, dcasProvider)
+
+		fileBytes, err := json.Marshal(&File{ContentType: contentType, Content: []byte(content)})
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, "/site", bytes.NewReader(fileBytes)))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		var cID string
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &cID))
+
+		return cID
+	}
+
+	doc, err := getDocument(&FileIndexDoc{
+		ID: "file:idx:1234",
+		FileIndex: FileIndex{
+			BasePath: "/site",
+			Mappings: map[string]string{
+				"index.html":      upload("", "<html>home</html>"),
+				"404.html":        upload("", "<html>not found</html>"),
+				"docs/index.html": upload("text/html", "<html>docs</html>"),
+				"docs/readme.md":  upload("", "# Readme"),
+				"app.js":          upload("", "console.log()"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	docResolver := &mocks.DocumentResolver{}
+	docResolver.ResolveDocumentReturns(&document.ResolutionResult{Document: doc}, nil)
+
+	h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+	require.Equal(t, "/site/{resourceName:.*}", h.Path())
+
+	router := mux.NewRouter()
+	router.HandleFunc(h.Path(), h.Handler()).Methods(h.Method())
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return rw
+	}
+
+	t.Run("Base path -> index document", func(t *testing.T) {
+		rw := get("/site/")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "<html>home</html>", rw.Body.String())
+		require.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("Directory -> index document", func(t *testing.T) {
+		rw := get("/site/docs/")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "<html>docs</html>", rw.Body.String())
+		require.Equal(t, "text/html", rw.Header().Get("Content-Type"))
+
+		rw = get("/site/docs")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "<html>docs</html>", rw.Body.String())
+	})
+
+	t.Run("Configured content type", func(t *testing.T) {
+		rw := get("/site/docs/readme.md")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "# Readme", rw.Body.String())
+		require.Equal(t, "text/markdown", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("Fallback document", func(t *testing.T) {
+		rw := get("/site/users/123")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "<html>home</html>", rw.Body.String())
+
+		rw = get("/site/users/")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "<html>home</html>", rw.Body.String())
+	})
+
+	t.Run("Not found document", func(t *testing.T) {
+		rw := get("/site/images/logo.png")
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		require.Equal(t, "<html>not found</html>", rw.Body.String())
+		require.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("No website documents", func(t *testing.T) {
+		cfg := cfg
+		cfg.Website = WebsiteConfig{}
+
+		h := NewRetrieveHandler(channelID, cfg, docResolver, nil, dcasProvider)
+		require.Equal(t, "/site/{resourceName:.+}", h.Path())
+
+		rw := httptest.NewRecorder()
+		h.Handler()(rw, mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/site/images/logo.png", nil), map[string]string{"resourceName": "images/logo.png"}))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		require.Equal(t, fileNotFound, rw.Body.String())
+
+		rw = httptest.NewRecorder()
+		h.Handler()(rw, mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/site/app.js", nil), map[string]string{"resourceName": "app.js"}))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func TestContentTypeFor(t *testing.T) {
+	h := NewRetrieveHandler(channelID, Config{Website: WebsiteConfig{ContentTypes: map[string]string{"md": "text/markdown"}}}, nil, nil, nil)
+
+	require.Equal(t, "text/markdown", h.contentTypeFor("docs/README.MD"))
+	require.Equal(t, "application/json", h.contentTypeFor("schema.json"))
+	require.Equal(t, defaultContentType, h.contentTypeFor("file.xyz123"))
+	require.Equal(t, defaultContentType, h.contentTypeFor("file"))
+}
+
+func TestFileUploadHandler_NoContentType(t *testing.T) {
+	dcasProvider := &mocks.DCASClientProvider{}
+	dcasProvider.GetDCASClientReturns(mocks.NewDCASClient(), nil)
+
+	fileBytes, err := json.Marshal(&File{Content: []byte("content")})
+	require.NoError(t, err)
+
+	cfg := Config{BasePath: "/site", ChaincodeName: "file_cc", Collection: "site"}
+
+	rw := httptest.NewRecorder()
+	NewUploadHandler(channelID, cfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodPost, "/site", bytes.NewReader(fileBytes)))
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+
+	cfg.Website.ContentTypes = map[string]string{"txt": "text/plain"}
+
+	rw = httptest.NewRecorder()
+	NewUploadHandler(channelID, cfg, dcasProvider).Handler()(rw, httptest.NewRequest(http.MethodPost, "/site", bytes.NewReader(fileBytes)))
+	require.Equal(t, http.StatusOK, rw.Code)
+}